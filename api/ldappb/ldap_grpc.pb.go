@@ -0,0 +1,517 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: ldap.proto
+
+package ldappb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	LdapService_CheckAvailability_FullMethodName  = "/ldap.v1.LdapService/CheckAvailability"
+	LdapService_GetUser_FullMethodName            = "/ldap.v1.LdapService/GetUser"
+	LdapService_CreateUser_FullMethodName         = "/ldap.v1.LdapService/CreateUser"
+	LdapService_UpdateUser_FullMethodName         = "/ldap.v1.LdapService/UpdateUser"
+	LdapService_SetPassword_FullMethodName        = "/ldap.v1.LdapService/SetPassword"
+	LdapService_GetGroup_FullMethodName           = "/ldap.v1.LdapService/GetGroup"
+	LdapService_CreateGroup_FullMethodName        = "/ldap.v1.LdapService/CreateGroup"
+	LdapService_UpdateGroupMembers_FullMethodName = "/ldap.v1.LdapService/UpdateGroupMembers"
+	LdapService_SearchUsers_FullMethodName        = "/ldap.v1.LdapService/SearchUsers"
+	LdapService_SearchGroups_FullMethodName       = "/ldap.v1.LdapService/SearchGroups"
+)
+
+// LdapServiceClient is the client API for LdapService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LdapServiceClient interface {
+	// CheckAvailability 检查sAMAccountName是否可用
+	CheckAvailability(ctx context.Context, in *CheckAvailabilityRequest, opts ...grpc.CallOption) (*CheckAvailabilityResponse, error)
+	// GetUser 获取用户信息
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	// CreateUser 创建启用的用户
+	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*User, error)
+	// UpdateUser 在一次modify请求中变更用户属性，ou不为空时最后移动用户
+	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*User, error)
+	// SetPassword 设置用户密码并解锁账户
+	SetPassword(ctx context.Context, in *SetPasswordRequest, opts ...grpc.CallOption) (*SetPasswordResponse, error)
+	// GetGroup 获取群组信息
+	GetGroup(ctx context.Context, in *GetGroupRequest, opts ...grpc.CallOption) (*Group, error)
+	// CreateGroup 创建群组
+	CreateGroup(ctx context.Context, in *CreateGroupRequest, opts ...grpc.CallOption) (*Group, error)
+	// UpdateGroupMembers 添加、移除群组成员，无法解析的成员记录在failures中
+	UpdateGroupMembers(ctx context.Context, in *UpdateGroupMembersRequest, opts ...grpc.CallOption) (*UpdateGroupMembersResponse, error)
+	// SearchUsers 分页搜索用户，逐个返回
+	SearchUsers(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (LdapService_SearchUsersClient, error)
+	// SearchGroups 分页搜索群组，逐个返回
+	SearchGroups(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (LdapService_SearchGroupsClient, error)
+}
+
+type ldapServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLdapServiceClient(cc grpc.ClientConnInterface) LdapServiceClient {
+	return &ldapServiceClient{cc}
+}
+
+func (c *ldapServiceClient) CheckAvailability(ctx context.Context, in *CheckAvailabilityRequest, opts ...grpc.CallOption) (*CheckAvailabilityResponse, error) {
+	out := new(CheckAvailabilityResponse)
+	err := c.cc.Invoke(ctx, LdapService_CheckAvailability_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ldapServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, LdapService_GetUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ldapServiceClient) CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, LdapService_CreateUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ldapServiceClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, LdapService_UpdateUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ldapServiceClient) SetPassword(ctx context.Context, in *SetPasswordRequest, opts ...grpc.CallOption) (*SetPasswordResponse, error) {
+	out := new(SetPasswordResponse)
+	err := c.cc.Invoke(ctx, LdapService_SetPassword_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ldapServiceClient) GetGroup(ctx context.Context, in *GetGroupRequest, opts ...grpc.CallOption) (*Group, error) {
+	out := new(Group)
+	err := c.cc.Invoke(ctx, LdapService_GetGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ldapServiceClient) CreateGroup(ctx context.Context, in *CreateGroupRequest, opts ...grpc.CallOption) (*Group, error) {
+	out := new(Group)
+	err := c.cc.Invoke(ctx, LdapService_CreateGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ldapServiceClient) UpdateGroupMembers(ctx context.Context, in *UpdateGroupMembersRequest, opts ...grpc.CallOption) (*UpdateGroupMembersResponse, error) {
+	out := new(UpdateGroupMembersResponse)
+	err := c.cc.Invoke(ctx, LdapService_UpdateGroupMembers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ldapServiceClient) SearchUsers(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (LdapService_SearchUsersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LdapService_ServiceDesc.Streams[0], LdapService_SearchUsers_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ldapServiceSearchUsersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LdapService_SearchUsersClient interface {
+	Recv() (*User, error)
+	grpc.ClientStream
+}
+
+type ldapServiceSearchUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *ldapServiceSearchUsersClient) Recv() (*User, error) {
+	m := new(User)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *ldapServiceClient) SearchGroups(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (LdapService_SearchGroupsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LdapService_ServiceDesc.Streams[1], LdapService_SearchGroups_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ldapServiceSearchGroupsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LdapService_SearchGroupsClient interface {
+	Recv() (*Group, error)
+	grpc.ClientStream
+}
+
+type ldapServiceSearchGroupsClient struct {
+	grpc.ClientStream
+}
+
+func (x *ldapServiceSearchGroupsClient) Recv() (*Group, error) {
+	m := new(Group)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LdapServiceServer is the server API for LdapService service.
+// All implementations must embed UnimplementedLdapServiceServer
+// for forward compatibility
+type LdapServiceServer interface {
+	// CheckAvailability 检查sAMAccountName是否可用
+	CheckAvailability(context.Context, *CheckAvailabilityRequest) (*CheckAvailabilityResponse, error)
+	// GetUser 获取用户信息
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	// CreateUser 创建启用的用户
+	CreateUser(context.Context, *CreateUserRequest) (*User, error)
+	// UpdateUser 在一次modify请求中变更用户属性，ou不为空时最后移动用户
+	UpdateUser(context.Context, *UpdateUserRequest) (*User, error)
+	// SetPassword 设置用户密码并解锁账户
+	SetPassword(context.Context, *SetPasswordRequest) (*SetPasswordResponse, error)
+	// GetGroup 获取群组信息
+	GetGroup(context.Context, *GetGroupRequest) (*Group, error)
+	// CreateGroup 创建群组
+	CreateGroup(context.Context, *CreateGroupRequest) (*Group, error)
+	// UpdateGroupMembers 添加、移除群组成员，无法解析的成员记录在failures中
+	UpdateGroupMembers(context.Context, *UpdateGroupMembersRequest) (*UpdateGroupMembersResponse, error)
+	// SearchUsers 分页搜索用户，逐个返回
+	SearchUsers(*SearchRequest, LdapService_SearchUsersServer) error
+	// SearchGroups 分页搜索群组，逐个返回
+	SearchGroups(*SearchRequest, LdapService_SearchGroupsServer) error
+	mustEmbedUnimplementedLdapServiceServer()
+}
+
+// UnimplementedLdapServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLdapServiceServer struct {
+}
+
+func (UnimplementedLdapServiceServer) CheckAvailability(context.Context, *CheckAvailabilityRequest) (*CheckAvailabilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckAvailability not implemented")
+}
+func (UnimplementedLdapServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedLdapServiceServer) CreateUser(context.Context, *CreateUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUser not implemented")
+}
+func (UnimplementedLdapServiceServer) UpdateUser(context.Context, *UpdateUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+}
+func (UnimplementedLdapServiceServer) SetPassword(context.Context, *SetPasswordRequest) (*SetPasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPassword not implemented")
+}
+func (UnimplementedLdapServiceServer) GetGroup(context.Context, *GetGroupRequest) (*Group, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroup not implemented")
+}
+func (UnimplementedLdapServiceServer) CreateGroup(context.Context, *CreateGroupRequest) (*Group, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateGroup not implemented")
+}
+func (UnimplementedLdapServiceServer) UpdateGroupMembers(context.Context, *UpdateGroupMembersRequest) (*UpdateGroupMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateGroupMembers not implemented")
+}
+func (UnimplementedLdapServiceServer) SearchUsers(*SearchRequest, LdapService_SearchUsersServer) error {
+	return status.Errorf(codes.Unimplemented, "method SearchUsers not implemented")
+}
+func (UnimplementedLdapServiceServer) SearchGroups(*SearchRequest, LdapService_SearchGroupsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SearchGroups not implemented")
+}
+func (UnimplementedLdapServiceServer) mustEmbedUnimplementedLdapServiceServer() {}
+
+// UnsafeLdapServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LdapServiceServer will
+// result in compilation errors.
+type UnsafeLdapServiceServer interface {
+	mustEmbedUnimplementedLdapServiceServer()
+}
+
+func RegisterLdapServiceServer(s grpc.ServiceRegistrar, srv LdapServiceServer) {
+	s.RegisterService(&LdapService_ServiceDesc, srv)
+}
+
+func _LdapService_CheckAvailability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckAvailabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LdapServiceServer).CheckAvailability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LdapService_CheckAvailability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LdapServiceServer).CheckAvailability(ctx, req.(*CheckAvailabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LdapService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LdapServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LdapService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LdapServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LdapService_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LdapServiceServer).CreateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LdapService_CreateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LdapServiceServer).CreateUser(ctx, req.(*CreateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LdapService_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LdapServiceServer).UpdateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LdapService_UpdateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LdapServiceServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LdapService_SetPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LdapServiceServer).SetPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LdapService_SetPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LdapServiceServer).SetPassword(ctx, req.(*SetPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LdapService_GetGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LdapServiceServer).GetGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LdapService_GetGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LdapServiceServer).GetGroup(ctx, req.(*GetGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LdapService_CreateGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LdapServiceServer).CreateGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LdapService_CreateGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LdapServiceServer).CreateGroup(ctx, req.(*CreateGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LdapService_UpdateGroupMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateGroupMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LdapServiceServer).UpdateGroupMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LdapService_UpdateGroupMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LdapServiceServer).UpdateGroupMembers(ctx, req.(*UpdateGroupMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LdapService_SearchUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LdapServiceServer).SearchUsers(m, &ldapServiceSearchUsersServer{stream})
+}
+
+type LdapService_SearchUsersServer interface {
+	Send(*User) error
+	grpc.ServerStream
+}
+
+type ldapServiceSearchUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *ldapServiceSearchUsersServer) Send(m *User) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LdapService_SearchGroups_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LdapServiceServer).SearchGroups(m, &ldapServiceSearchGroupsServer{stream})
+}
+
+type LdapService_SearchGroupsServer interface {
+	Send(*Group) error
+	grpc.ServerStream
+}
+
+type ldapServiceSearchGroupsServer struct {
+	grpc.ServerStream
+}
+
+func (x *ldapServiceSearchGroupsServer) Send(m *Group) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// LdapService_ServiceDesc is the grpc.ServiceDesc for LdapService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LdapService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ldap.v1.LdapService",
+	HandlerType: (*LdapServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckAvailability",
+			Handler:    _LdapService_CheckAvailability_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _LdapService_GetUser_Handler,
+		},
+		{
+			MethodName: "CreateUser",
+			Handler:    _LdapService_CreateUser_Handler,
+		},
+		{
+			MethodName: "UpdateUser",
+			Handler:    _LdapService_UpdateUser_Handler,
+		},
+		{
+			MethodName: "SetPassword",
+			Handler:    _LdapService_SetPassword_Handler,
+		},
+		{
+			MethodName: "GetGroup",
+			Handler:    _LdapService_GetGroup_Handler,
+		},
+		{
+			MethodName: "CreateGroup",
+			Handler:    _LdapService_CreateGroup_Handler,
+		},
+		{
+			MethodName: "UpdateGroupMembers",
+			Handler:    _LdapService_UpdateGroupMembers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SearchUsers",
+			Handler:       _LdapService_SearchUsers_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SearchGroups",
+			Handler:       _LdapService_SearchGroups_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "ldap.proto",
+}
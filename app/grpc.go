@@ -0,0 +1,435 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"ldap-http-service/api/ldappb"
+	"ldap-http-service/constants"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/logger"
+	"ldap-http-service/lib/utils"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+)
+
+// grpcServer 实现ldappb.LdapServiceServer，与HTTP接口共用core/ldap及请求校验逻辑
+type grpcServer struct {
+	ldappb.UnimplementedLdapServiceServer
+}
+
+// newGrpcServer 创建gRPC服务并注册链路ID、日志、panic恢复及错误转换拦截器
+func newGrpcServer() *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryInterceptor),
+		grpc.StreamInterceptor(streamInterceptor),
+	)
+	ldappb.RegisterLdapServiceServer(srv, &grpcServer{})
+	return srv
+}
+
+// traceContext 从metadata中获取trace_id，未传递时自动生成，与HTTP请求一样标记在上下文中
+func traceContext(ctx context.Context, method string) (context.Context, string) {
+	var traceID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("trace_id"); len(values) > 0 {
+			traceID = values[0]
+		}
+	}
+	if traceID == "" {
+		traceID = utils.GenUuid("req")
+	}
+	ctx = context.WithValue(ctx, "trace_id", traceID)
+	ctx = context.WithValue(ctx, "opt", method)
+	return ctx, traceID
+}
+
+// grpcCaller 解析owner模式的调用方：metadata中携带Basic认证的authorization时校验凭据，并将调用方的DN记录在上下文中，
+// 未携带时以服务身份执行
+func grpcCaller(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	authorization := md.Get("authorization")
+	if len(authorization) == 0 {
+		return ctx, nil
+	}
+
+	username, password, ok := (&http.Request{Header: http.Header{"Authorization": authorization}}).BasicAuth()
+	if !ok {
+		return ctx, &ers.UnauthorizedErr{Message: "only basic authentication is supported"}
+	}
+	user, err := ldap.Authenticate(ctx, username, password)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, ldap.CallerKey, user.DistinguishedName), nil
+}
+
+// ownerRejectedMethods owner模式只允许更新群组成员，其他写操作的方法携带Basic认证时拒绝，需要以服务身份调用
+var ownerRejectedMethods = map[string]bool{
+	ldappb.LdapService_CreateUser_FullMethodName:  true,
+	ldappb.LdapService_UpdateUser_FullMethodName:  true,
+	ldappb.LdapService_SetPassword_FullMethodName: true,
+	ldappb.LdapService_CreateGroup_FullMethodName: true,
+}
+
+// rejectGrpcOwnerMode 与HTTP接口一致，拒绝以owner模式调用的写操作，不校验凭据
+func rejectGrpcOwnerMode(ctx context.Context, method string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ownerRejectedMethods[method] && len(md.Get("authorization")) > 0 {
+		return &ers.ForbiddenErr{Message: "owner mode only allows updating group members"}
+	}
+	return nil
+}
+
+// grpcCode 将自定义错误转换为gRPC状态码，参数类错误在HTTP中返回500，这里按其语义归为InvalidArgument
+func grpcCode(err ers.CustomErr) codes.Code {
+	switch err.(type) {
+	case *ers.TimeoutErr:
+		return codes.DeadlineExceeded
+	case *ers.UnSupportedErr, *ers.InvalidFormatErr:
+		return codes.InvalidArgument
+	case *ers.RequestInProgressErr:
+		return codes.Aborted
+	case *ers.MembershipCycleErr, *ers.ManagerCycleErr:
+		// 对象已存在之外的冲突是当前目录状态不满足变更的前提
+		return codes.FailedPrecondition
+	}
+
+	switch err.HttpCode() {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusGone:
+		return codes.OutOfRange
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
+
+// grpcStatus 将错误转换为gRPC status及业务错误码，未知错误不返回具体信息
+func grpcStatus(err error) (*status.Status, int) {
+	var customErr ers.CustomErr
+	if errors.As(err, &customErr) {
+		return status.New(grpcCode(customErr), customErr.Error()), customErr.Code()
+	}
+	return status.New(codes.Internal, "Internal Server Error"), constants.CodeInternalException
+}
+
+// grpcFinish 记录请求结果，将handler的错误或panic转换为gRPC status，业务错误码通过trailer中的code返回
+func grpcFinish(ctx context.Context, method string, startTime time.Time, recovered interface{}, err error, setTrailer func(metadata.MD)) error {
+	st, code := status.New(codes.OK, ""), 0
+	switch {
+	case recovered != nil:
+		logger.GrpcLogger.WithContext(ctx).Warning(fmt.Sprintf("Handler panic! %v", recovered))
+		logger.GrpcLogger.WithContext(ctx).Debug(string(debug.Stack()))
+		st, code = status.New(codes.Internal, "Internal Server Error"), constants.CodeInternalException
+	case err != nil:
+		logger.GrpcLogger.WithContext(ctx).Errorf("Handler异常：%v", err)
+		st, code = grpcStatus(err)
+	}
+	setTrailer(metadata.Pairs("code", strconv.Itoa(code)))
+
+	retInfo := logrus.Fields{
+		"method":   method,
+		"status":   st.Code().String(),
+		"duration": time.Since(startTime),
+	}
+	logger.GrpcLogger.WithContext(ctx).WithFields(retInfo).Info("gRPC请求已处理")
+	return st.Err()
+}
+
+func unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	ctx, traceID := traceContext(ctx, info.FullMethod)
+	_ = grpc.SetHeader(ctx, metadata.Pairs("trace_id", traceID))
+	logger.GrpcLogger.WithContext(ctx).WithField("method", info.FullMethod).Info("收到gRPC请求")
+
+	startTime := time.Now()
+	defer func() {
+		err = grpcFinish(ctx, info.FullMethod, startTime, recover(), err, func(md metadata.MD) {
+			_ = grpc.SetTrailer(ctx, md)
+		})
+		if err != nil {
+			resp = nil
+		}
+	}()
+	if err = rejectGrpcOwnerMode(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// tracedStream 替换ServerStream的上下文，使流式handler获取到带有trace_id的上下文
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+func streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx, traceID := traceContext(ss.Context(), info.FullMethod)
+	_ = ss.SetHeader(metadata.Pairs("trace_id", traceID))
+	logger.GrpcLogger.WithContext(ctx).WithField("method", info.FullMethod).Info("收到gRPC请求")
+
+	startTime := time.Now()
+	defer func() {
+		err = grpcFinish(ctx, info.FullMethod, startTime, recover(), err, ss.SetTrailer)
+	}()
+	return handler(srv, &tracedStream{ServerStream: ss, ctx: ctx})
+}
+
+func pbTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toPbBase(obj *ldap.BaseObject) *ldappb.BaseObject {
+	if obj == nil {
+		return nil
+	}
+	return &ldappb.BaseObject{
+		Name:              obj.Name,
+		DisplayName:       obj.DisplayName,
+		SamAccountName:    obj.SAMAccountName,
+		DistinguishedName: obj.DistinguishedName,
+		Description:       obj.Description,
+		WhenCreated:       pbTime(time.Time(obj.WhenCreated)),
+		WhenChanged:       pbTime(time.Time(obj.WhenChanged)),
+		ObjectClass:       obj.ObjectClass,
+		ObjectCategory:    obj.ObjectCategory,
+		ObjectGuid:        obj.ObjectGUID,
+		ObjectSid:         obj.ObjectSid,
+		UsnChanged:        obj.USNChanged,
+		UsnCreated:        obj.USNCreated,
+	}
+}
+
+func toPbUser(user *ldap.User) *ldappb.User {
+	return &ldappb.User{
+		Base:                       toPbBase(&user.BaseObject),
+		Company:                    user.Company,
+		Department:                 user.Department,
+		GivenName:                  user.GivenName,
+		Sn:                         user.Sn,
+		Manager:                    user.Manager,
+		PhysicalDeliveryOfficeName: user.PhysicalDeliveryOfficeName,
+		MemberOf:                   user.MemberOf,
+		PwdLastSet:                 pbTime(time.Time(user.PwdLastSet)),
+		LockoutTime:                pbTime(time.Time(user.LockoutTime)),
+		LastLogon:                  pbTime(time.Time(user.LastLogon)),
+		PwdExpiryTime:              pbTime(time.Time(user.PwdExpiryTime)),
+		ProxyAddresses:             user.ProxyAddresses,
+		Mail:                       user.Mail,
+		MailNickname:               user.MailNickname,
+		UserPrincipalName:          user.UserPrincipalName,
+		UserAccountControl:         user.UserAccountControl,
+		LegacyExchangeDn:           user.LegacyExchangeDN,
+		HomeMdb:                    user.HomeMDB,
+		MdbUseDefaults:             user.MDBUseDefaults,
+		MdbStorageQuota:            user.MDBStorageQuota,
+		MdbOverQuotaLimit:          user.MDBOverQuotaLimit,
+		MdbOverHardQuotaLimit:      user.MDBOverHardQuotaLimit,
+		Title:                      user.Title,
+		EmployeeId:                 user.EmployeeID,
+		DirectReports:              user.DirectReports,
+	}
+}
+
+func toPbGroup(group *ldap.Group) *ldappb.Group {
+	return &ldappb.Group{
+		Base:                  toPbBase(&group.BaseObject),
+		Cn:                    group.CN,
+		Member:                group.Member,
+		Mail:                  group.Mail,
+		MailNickname:          group.MailNickName,
+		MsExchCoManagedByLink: group.MsExchCoManagedByLink,
+		ProxyAddresses:        group.ProxyAddresses,
+		ManagedBy:             group.ManagedBy,
+		GroupType:             group.GroupType,
+	}
+}
+
+// pbPatch 将属性变更转换为与HTTP PATCH相同的变更，并使用相同的属性校验
+func pbPatch(changes []*ldappb.AttributeChange, ou string, allowed, required []string) (*objPatch, error) {
+	ops := make([]patchOp, 0, len(changes))
+	for _, change := range changes {
+		op := patchOp{Path: "/" + change.Attribute}
+		switch change.Op {
+		case ldappb.AttributeChange_OPERATION_ADD:
+			op.Op = ldap.OpAdd
+		case ldappb.AttributeChange_OPERATION_REMOVE:
+			op.Op = ldap.OpRemove
+		case ldappb.AttributeChange_OPERATION_REPLACE:
+			op.Op = ldap.OpReplace
+		default:
+			return nil, &ers.InvalidPatchErr{Message: fmt.Sprintf("operation of '%s' is not specified", change.Attribute)}
+		}
+		if change.Attribute == "OU" {
+			return nil, &ers.InvalidPatchErr{Message: "use the ou field to move the object"}
+		}
+
+		if len(change.Values) > 0 {
+			values := make([]interface{}, 0, len(change.Values))
+			for _, value := range change.Values {
+				values = append(values, value)
+			}
+			op.Value = values
+		}
+		ops = append(ops, op)
+	}
+
+	patch, err := parseJsonPatch(ops, allowed, required)
+	if err != nil {
+		return nil, err
+	}
+	patch.OU = ou
+	return patch, nil
+}
+
+func (s *grpcServer) CheckAvailability(ctx context.Context, req *ldappb.CheckAvailabilityRequest) (*ldappb.CheckAvailabilityResponse, error) {
+	ok, obj, err := ldap.CheckAvailability(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &ldappb.CheckAvailabilityResponse{Available: ok, Object: toPbBase(obj)}, nil
+}
+
+func (s *grpcServer) GetUser(ctx context.Context, req *ldappb.GetUserRequest) (*ldappb.User, error) {
+	user, err := ldap.GetUser(ctx, req.UserId, req.UserIdType, req.SearchBase, req.Attributes...)
+	if err != nil {
+		return nil, err
+	}
+	return toPbUser(&user), nil
+}
+
+func (s *grpcServer) CreateUser(ctx context.Context, req *ldappb.CreateUserRequest) (*ldappb.User, error) {
+	ctx = ldap.WithoutCache(ctx)
+	err := ldap.CreateEnabledUser(ctx, req.SamAccountName, req.DisplayName, req.Ou, req.Password, req.PrimaryDomain)
+	if err != nil {
+		return nil, err
+	}
+	user, err := ldap.GetUser(ctx, req.SamAccountName, "sAMAccountName", "")
+	if err != nil {
+		return nil, err
+	}
+	return toPbUser(&user), nil
+}
+
+func (s *grpcServer) UpdateUser(ctx context.Context, req *ldappb.UpdateUserRequest) (*ldappb.User, error) {
+	ctx = ldap.WithoutCache(ctx)
+	patch, err := pbPatch(req.Changes, req.Ou, userPatchAttrs, userRequiredAttrs)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := ldap.GetUser(ctx, req.UserId, req.UserIdType, req.SearchBase)
+	if err != nil {
+		return nil, err
+	}
+	if err = applyObjPatch(ctx, user.DistinguishedName, req.Version, patch); err != nil {
+		return nil, err
+	}
+
+	// 用户可能已被移动到其他OU，按DN以外的标识重新获取
+	user, err = ldap.GetUser(ctx, user.ObjectGUID, "objectGUID", "")
+	if err != nil {
+		return nil, err
+	}
+	return toPbUser(&user), nil
+}
+
+func (s *grpcServer) SetPassword(ctx context.Context, req *ldappb.SetPasswordRequest) (*ldappb.SetPasswordResponse, error) {
+	ctx = ldap.WithoutCache(ctx)
+	err := ldap.SetUserPwd(ctx, req.UserId, req.UserIdType, req.Password, req.SearchBase, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &ldappb.SetPasswordResponse{}, nil
+}
+
+func (s *grpcServer) GetGroup(ctx context.Context, req *ldappb.GetGroupRequest) (*ldappb.Group, error) {
+	group, err := ldap.GetGroup(ctx, req.GroupId, req.GroupIdType, req.SearchBase, req.Attributes...)
+	if err != nil {
+		return nil, err
+	}
+	return toPbGroup(&group), nil
+}
+
+func (s *grpcServer) CreateGroup(ctx context.Context, req *ldappb.CreateGroupRequest) (*ldappb.Group, error) {
+	ctx = ldap.WithoutCache(ctx)
+	err := ldap.CreateGroup(ctx, req.SamAccountName, req.Ou, req.DisplayName, req.Description, int(req.GroupType))
+	if err != nil {
+		return nil, err
+	}
+	group, err := ldap.GetGroup(ctx, req.SamAccountName, "sAMAccountName", "")
+	if err != nil {
+		return nil, err
+	}
+	return toPbGroup(&group), nil
+}
+
+func (s *grpcServer) UpdateGroupMembers(ctx context.Context, req *ldappb.UpdateGroupMembersRequest) (*ldappb.UpdateGroupMembersResponse, error) {
+	ctx, err := grpcCaller(ldap.WithoutCache(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := ldap.GetGroup(ctx, req.GroupId, req.GroupIdType, req.SearchBase)
+	if err != nil {
+		return nil, err
+	}
+
+	// 与HTTP接口一致，owner模式下只允许所有者或共同管理者更新成员
+	if err = ldap.AuthorizeMemberChange(ctx, &group); err != nil {
+		return nil, err
+	}
+
+	// 在解析成员前提前校验版本，避免部分成员变更后才发现版本不一致
+	if req.Version != "" && req.Version != group.USNChanged {
+		return nil, &ers.PreconditionFailedErr{Object: group.DistinguishedName, Version: req.Version}
+	}
+
+	failures, err := updateGroupMembers(ctx, group.DistinguishedName, req.MemberIdType, req.SearchBase, req.Version, req.AddMembers, nil, req.RemoveMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err = ldap.GetGroup(ctx, group.DistinguishedName, "distinguishedName", "")
+	if err != nil {
+		return nil, err
+	}
+	return &ldappb.UpdateGroupMembersResponse{Group: toPbGroup(&group), Failures: failures}, nil
+}
+
+func (s *grpcServer) SearchUsers(req *ldappb.SearchRequest, stream ldappb.LdapService_SearchUsersServer) error {
+	return ldap.SearchUsers(stream.Context(), req.Filter, req.SearchBase, req.Attributes, func(user *ldap.User) error {
+		return stream.Send(toPbUser(user))
+	})
+}
+
+func (s *grpcServer) SearchGroups(req *ldappb.SearchRequest, stream ldappb.LdapService_SearchGroupsServer) error {
+	return ldap.SearchGroups(stream.Context(), req.Filter, req.SearchBase, req.Attributes, func(group *ldap.Group) error {
+		return stream.Send(toPbGroup(group))
+	})
+}
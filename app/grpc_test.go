@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"ldap-http-service/api/ldappb"
+	"ldap-http-service/lib/ers"
+	"testing"
+	"time"
+)
+
+func TestGrpcCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  ers.CustomErr
+		want codes.Code
+	}{
+		{name: "timeout", err: &ers.TimeoutErr{Option: "search", Time: time.Second}, want: codes.DeadlineExceeded},
+		{name: "unsupported", err: &ers.UnSupportedErr{Object: "x", ObjectType: "type"}, want: codes.InvalidArgument},
+		{name: "invalid format", err: &ers.InvalidFormatErr{Name: "objectGUID", Object: "x"}, want: codes.InvalidArgument},
+		{name: "request in progress", err: &ers.RequestInProgressErr{Key: "k"}, want: codes.Aborted},
+		{name: "bad request", err: &ers.BadRequestErr{Message: "x"}, want: codes.InvalidArgument},
+		{name: "validation", err: &ers.ValidationErr{Fields: []ers.FieldError{{Field: "ou", Message: "required"}}}, want: codes.InvalidArgument},
+		{name: "unauthorized", err: &ers.UnauthorizedErr{Message: "x"}, want: codes.Unauthenticated},
+		{name: "forbidden", err: &ers.ForbiddenErr{Message: "x"}, want: codes.PermissionDenied},
+		{name: "not found", err: &ers.NotFoundError{Object: "x"}, want: codes.NotFound},
+		{name: "object exists", err: &ers.ObjExistError{Object: "x"}, want: codes.AlreadyExists},
+		{name: "membership cycle", err: &ers.MembershipCycleErr{Group: "a", Member: "b"}, want: codes.FailedPrecondition},
+		{name: "manager cycle", err: &ers.ManagerCycleErr{User: "a", Manager: "b"}, want: codes.FailedPrecondition},
+		{name: "cursor expired", err: &ers.CursorExpiredErr{Cursor: 1}, want: codes.OutOfRange},
+		{name: "version mismatch", err: &ers.PreconditionFailedErr{Object: "x", Version: "1"}, want: codes.FailedPrecondition},
+		{name: "system", err: &ers.SystemErr{Message: "x"}, want: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := grpcCode(tt.err); got != tt.want {
+				t.Errorf("grpcCode(%T) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRejectGrpcOwnerMode(t *testing.T) {
+	owner := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic YWxpY2U6c2VjcmV0"))
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		reject bool
+	}{
+		{name: "service identity", ctx: context.Background(), method: ldappb.LdapService_CreateUser_FullMethodName},
+		{name: "owner creates user", ctx: owner, method: ldappb.LdapService_CreateUser_FullMethodName, reject: true},
+		{name: "owner updates user", ctx: owner, method: ldappb.LdapService_UpdateUser_FullMethodName, reject: true},
+		{name: "owner sets password", ctx: owner, method: ldappb.LdapService_SetPassword_FullMethodName, reject: true},
+		{name: "owner creates group", ctx: owner, method: ldappb.LdapService_CreateGroup_FullMethodName, reject: true},
+		{name: "owner updates members", ctx: owner, method: ldappb.LdapService_UpdateGroupMembers_FullMethodName},
+		{name: "owner reads group", ctx: owner, method: ldappb.LdapService_GetGroup_FullMethodName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rejectGrpcOwnerMode(tt.ctx, tt.method)
+			var forbidden *ers.ForbiddenErr
+			if errors.As(err, &forbidden) != tt.reject || (!tt.reject && err != nil) {
+				t.Errorf("rejectGrpcOwnerMode() = %v, want reject %v", err, tt.reject)
+			}
+		})
+	}
+}
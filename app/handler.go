@@ -1,16 +1,218 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"io"
+	"ldap-http-service/config"
 	"ldap-http-service/constants"
+	"ldap-http-service/core/bulk"
+	"ldap-http-service/core/changes"
+	"ldap-http-service/core/expiry"
 	"ldap-http-service/core/ldap"
+	"ldap-http-service/core/transfer"
+	"ldap-http-service/core/webhook"
 	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/utils"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// parseAttributes 解析请求中的attributes参数，多个属性以逗号分隔，未指定时返回nil代表获取全部属性
+func parseAttributes(c *gin.Context) []string {
+	var attributes []string
+	for _, attr := range strings.Split(c.Query("attributes"), ",") {
+		if attr = strings.TrimSpace(attr); attr != "" {
+			attributes = append(attributes, attr)
+		}
+	}
+	return attributes
+}
+
+// ifMatch 解析请求头中的If-Match，返回期望的对象版本，未指定或为 "*" 时返回空字符串代表不做版本校验
+func ifMatch(c *gin.Context) string {
+	version := strings.TrimPrefix(strings.TrimSpace(c.GetHeader("If-Match")), "W/")
+	if version == "*" {
+		return ""
+	}
+	return strings.Trim(version, "\"")
+}
+
+// setETag 在响应头中设置对象的ETag
+func setETag(c *gin.Context, obj *ldap.BaseObject) {
+	if etag := obj.ETag(); etag != "" {
+		c.Header("ETag", etag)
+	}
+}
+
+// owner模式认证失败时返回的认证方式
+const basicRealm = `Basic realm="ldap-http-service"`
+
+// ownerCaller 解析owner模式的调用方：请求携带Basic认证时以该用户的身份校验凭据并返回用户，未携带认证信息时返回nil，以服务身份执行；
+// 认证成功后调用方的DN记录在上下文中，同一请求中不再重复认证
+func ownerCaller(c *gin.Context) (*ldap.User, error) {
+	if caller, ok := c.Get("owner_caller"); ok {
+		return caller.(*ldap.User), nil
+	}
+	if c.GetHeader("Authorization") == "" {
+		return nil, nil
+	}
+
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		c.Header("WWW-Authenticate", basicRealm)
+		return nil, &ers.UnauthorizedErr{Message: "only basic authentication is supported"}
+	}
+	user, err := ldap.Authenticate(c, username, password)
+	var unauthorizedErr *ers.UnauthorizedErr
+	if errors.As(err, &unauthorizedErr) {
+		c.Header("WWW-Authenticate", basicRealm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Set("owner_caller", &user)
+	c.Set(ldap.CallerKey, user.DistinguishedName)
+	return &user, nil
+}
+
+// checkGroupOwner 校验调用方是否可以变更群组成员，owner模式下只允许群组的所有者或共同管理者操作群组
+func checkGroupOwner(c *gin.Context, group *ldap.Group) error {
+	if _, err := ownerCaller(c); err != nil {
+		return err
+	}
+	err := ldap.AuthorizeMemberChange(c, group)
+	var unauthorizedErr *ers.UnauthorizedErr
+	if errors.As(err, &unauthorizedErr) {
+		c.Header("WWW-Authenticate", basicRealm)
+	}
+	return err
+}
+
+// 允许通过更新接口变更的属性，以及其中不允许清空的属性
+var (
+	userPatchAttrs    = []string{"sAMAccountName", "displayName", "description", "userAccountControl", "proxyAddresses", "mail", "title", "employeeID"}
+	userRequiredAttrs = []string{"sAMAccountName", "userAccountControl"}
+	groupPatchAttrs   = []string{"displayName", "description", "proxyAddresses", "mail"}
+	// 计算机的sAMAccountName需要与计算机名保持一致，不允许直接修改
+	computerPatchAttrs    = []string{"displayName", "description", "dNSHostName", "userAccountControl", "managedBy"}
+	computerRequiredAttrs = []string{"userAccountControl"}
+	contactPatchAttrs     = []string{"displayName", "description", "givenName", "sn", "company", "department", "telephoneNumber", "mail", "mailNickname", "targetAddress", "proxyAddresses"}
+	contactRequiredAttrs  = []string{"mail", "targetAddress"}
+)
+
+// applyObjPatch 在一次modify请求中完成用户、计算机、联系人的属性变更，需要修改OU时最后单独移动对象
+func applyObjPatch(ctx context.Context, dn, version string, patch *objPatch) error {
+	if len(patch.Changes) > 0 || patch.OU == "" {
+		if err := ldap.ApplyChanges(ctx, dn, version, patch.Changes); err != nil {
+			return err
+		}
+		// 属性变更已经通过了版本校验，并且会更新uSNChanged，移动OU时无需再次校验
+		version = ""
+	}
+
+	// 仅移动OU时，ModifyDN不支持断言控件，版本只能在移动前校验
+	if patch.OU != "" {
+		return ldap.MoveObjectToOU(ctx, dn, patch.OU, version)
+	}
+	return nil
+}
+
+// updateGroupMembers 解析并添加、移除群组成员，成员可以是用户、联系人或群组，expiring中的成员在到期后自动移除；
+// 返回无法解析、会形成循环嵌套或执行失败的成员信息；版本校验失败时不执行后续变更，直接返回*ers.PreconditionFailedErr
+func updateGroupMembers(ctx context.Context, groupDN, memberIdType, searchBase, version string, add []string, expiring []expiringMember, remove []string) ([]string, error) {
+	var (
+		failures       []string
+		addMembers     []string
+		removeMembers  []string
+		expiringGroups = make(map[int64][]string)
+		expiringTimes  []time.Time
+	)
+
+	resolveAdd := func(am string) (string, bool) {
+		member, err := ldap.GetMember(ctx, am, memberIdType, searchBase)
+		if err == nil && utils.InSliceIC(member.ObjectClass, ldap.MemberGroup) {
+			// 逐个校验嵌套群组，形成循环的群组记录为失败，不影响其他成员的添加
+			err = ldap.CheckMembershipCycle(ctx, groupDN, member.DistinguishedName)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("add failed: %s", err.Error()))
+			return "", false
+		}
+		return member.DistinguishedName, true
+	}
+
+	for _, am := range add {
+		if dn, ok := resolveAdd(am); ok {
+			addMembers = append(addMembers, dn)
+		}
+	}
+
+	// 到期时间相同的成员一次性添加，按Unix秒分组，同一时刻以不同时区或精度表示的时间视为相同
+	for _, em := range expiring {
+		if dn, ok := resolveAdd(em.Member); ok {
+			if _, exists := expiringGroups[em.ExpiresAt.Unix()]; !exists {
+				expiringTimes = append(expiringTimes, em.ExpiresAt)
+			}
+			expiringGroups[em.ExpiresAt.Unix()] = append(expiringGroups[em.ExpiresAt.Unix()], dn)
+		}
+	}
+
+	for _, rm := range remove {
+		member, err := ldap.GetMember(ctx, rm, memberIdType, searchBase)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("remove failed: %s", err.Error()))
+			continue
+		}
+		removeMembers = append(removeMembers, member.DistinguishedName)
+	}
+
+	if len(addMembers) > 0 {
+		if err := ldap.AddGroupMembers(ctx, groupDN, "distinguishedName", version, addMembers...); isPreconditionFailed(err) {
+			return nil, err
+		} else if err != nil {
+			failures = append(failures, fmt.Sprintf("add failed: %s", err.Error()))
+		} else {
+			// 添加成员已经通过了版本校验，并且会更新uSNChanged，后续的变更无需再次校验
+			version = ""
+		}
+	}
+
+	for _, expiresAt := range expiringTimes {
+		if err := expiry.AddGroupMembers(ctx, groupDN, version, expiresAt, expiringGroups[expiresAt.Unix()]...); isPreconditionFailed(err) {
+			return nil, err
+		} else if err != nil {
+			failures = append(failures, fmt.Sprintf("add failed: %s", err.Error()))
+		} else {
+			version = ""
+		}
+	}
+
+	if len(removeMembers) > 0 {
+		if err := ldap.RemoveGroupMembers(ctx, groupDN, "distinguishedName", version, removeMembers...); isPreconditionFailed(err) {
+			return nil, err
+		} else if err != nil {
+			failures = append(failures, fmt.Sprintf("remove failed: %s", err.Error()))
+		}
+	}
+	return failures, nil
+}
+
+// isPreconditionFailed 判断错误是否为版本校验失败，版本校验失败需要以412返回而不是作为部分失败
+func isPreconditionFailed(err error) bool {
+	var precondition *ers.PreconditionFailedErr
+	return errors.As(err, &precondition)
+}
+
 func handleHealthz(c *gin.Context) {
-	_, err := ldap.GetUser(c, "Administrator", "sAMAccountName", "")
+	// 健康检查需要确认LDAP可用，不能使用缓存的结果
+	_, err := ldap.GetUser(ldap.WithoutCache(c), "Administrator", "sAMAccountName", "")
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -34,59 +236,103 @@ func handleCheckAvailability(c *gin.Context) {
 	}
 }
 
+func handleSuggestNames(c *gin.Context) {
+	c.Set("opt", "生成可用的用户名")
+
+	givenName, surname := c.Query("given"), c.Query("surname")
+	if givenName == "" || surname == "" {
+		_ = c.Error(&ers.BadRequestErr{Message: "given and surname are required"})
+		return
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", "5"))
+	if err != nil || count <= 0 || count > 50 {
+		_ = c.Error(&ers.InvalidFormatErr{Name: "count", Object: c.Query("count")})
+		return
+	}
+
+	names, err := ldap.SuggestNames(c, givenName, surname, count)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"suggestions": names})
+}
+
 func handleGetUser(c *gin.Context) {
 	c.Set("opt", "获取LDAP用户信息")
 	userId := c.Param("user_id")
 	userIdType := c.Query("user_id_type")
 	searchBase := c.Query("search_base")
+	attributes := parseAttributes(c)
 
-	user, err := ldap.GetUser(c, userId, userIdType, searchBase)
+	user, err := ldap.GetUser(c, userId, userIdType, searchBase, attributes...)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
-	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"user": user})
+	setETag(c, &user.BaseObject)
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"user": ldap.ProjectObject(&user, attributes)})
 }
 
 func handleNewEnableUser(c *gin.Context) {
 	c.Set("opt", "创建启用LDAP用户")
-	var user struct {
-		SAMAccountName string `json:"sAMAccountName"`
-		DisplayName    string `json:"displayName"`
-		OU             string `json:"OU"`
-		Password       string `json:"password"`
-		PrimaryDomain  string `json:"primaryDomain"`
-	}
-
-	if err := c.ShouldBindJSON(&user); err != nil {
-		_ = c.Error(&ers.InvalidJsonErr{})
+	var user createUserRequest
+	if err := bindRequest(c, &user); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	err := ldap.CreateEnabledUser(c, user.SAMAccountName, user.DisplayName, user.OU, user.Password, user.PrimaryDomain)
+	var err error
+	if user.AutoName {
+		user.SAMAccountName, err = createAutoNamedUser(c, user)
+	} else {
+		err = ldap.CreateEnabledUserWithAttrs(c, user.SAMAccountName, user.DisplayName, user.OU, user.Password, user.PrimaryDomain, user.nameAttrs())
+	}
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
+
 	userInfo, _ := ldap.GetUser(c, user.SAMAccountName, "sAMAccountName", "")
 	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"user": userInfo})
 }
 
+// autoNameAttempts 自动生成用户名时，候选用户名在创建前被占用的情况下最多尝试的次数
+const autoNameAttempts = 3
+
+// createAutoNamedUser 使用自动生成的用户名创建用户，返回实际使用的用户名；候选用户名在创建前被占用时依次尝试下一个
+func createAutoNamedUser(c *gin.Context, user createUserRequest) (string, error) {
+	names, err := ldap.SuggestNames(c, user.GivenName, user.Sn, autoNameAttempts)
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", &ers.ForbiddenErr{Message: fmt.Sprintf("no available username for '%s %s'", user.GivenName, user.Sn)}
+	}
+
+	var objExistError *ers.ObjExistError
+	for _, name := range names {
+		err = ldap.CreateEnabledUserWithAttrs(c, name, user.DisplayName, user.OU, user.Password, user.PrimaryDomain, user.nameAttrs())
+		if !errors.As(err, &objExistError) {
+			return name, err
+		}
+	}
+	return "", err
+}
+
 func handleUserPwd(c *gin.Context) {
 	c.Set("opt", "设置LDAP用户密码")
 	userId := c.Param("user_id")
 	userIdType := c.Query("user_id_type")
 	searchBase := c.Query("search_base")
 
-	var pwdChanged struct {
-		Password string `json:"password"`
-	}
-	if err := c.ShouldBindJSON(&pwdChanged); err != nil {
-		_ = c.Error(&ers.InvalidJsonErr{})
+	var pwdChanged setPasswordRequest
+	if err := bindRequest(c, &pwdChanged); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	err := ldap.SetUserPwd(c, userId, userIdType, pwdChanged.Password, searchBase)
+	err := ldap.SetUserPwd(c, userId, userIdType, pwdChanged.Password, searchBase, ifMatch(c))
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -100,18 +346,12 @@ func handleUserUpdate(c *gin.Context) {
 	userId := c.Param("user_id")
 	userIdType := c.Query("user_id_type")
 	searchBase := c.Query("search_base")
+	version := ifMatch(c)
 
-	var userUpdated struct {
-		SAMAccountName     string   `json:"sAMAccountName"`
-		DisplayName        string   `json:"displayName"`
-		Description        string   `json:"description"`
-		UserAccountControl int      `json:"userAccountControl"`
-		ProxyAddresses     []string `json:"proxyAddresses"`
-		Mail               string   `json:"mail"`
-		OU                 string   `json:"OU"`
-	}
-	if err := c.ShouldBindJSON(&userUpdated); err != nil {
-		_ = c.Error(&ers.InvalidJsonErr{})
+	// 支持JSON Merge Patch（null清空属性）以及JSON Patch风格的操作列表
+	patch, err := parsePatch(c, userPatchAttrs, userRequiredAttrs)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
@@ -121,210 +361,1034 @@ func handleUserUpdate(c *gin.Context) {
 		return
 	}
 
-	// 建立一个map来存储要修改的属性
-	var replaceAttr = map[string][]string{}
-	if userUpdated.SAMAccountName != "" {
-		replaceAttr["sAMAccountName"] = []string{userUpdated.SAMAccountName}
+	if err = applyObjPatch(c, user.DistinguishedName, version, patch); err != nil {
+		_ = c.Error(err)
+		return
 	}
 
-	if userUpdated.DisplayName != "" {
-		replaceAttr["displayName"] = []string{userUpdated.DisplayName}
+	// 按objectGUID重新读取，对象可能已被移动到其他OU
+	attributes := parseAttributes(c)
+	if user, err = ldap.GetUser(c, user.ObjectGUID, "objectGUID", "", attributes...); err != nil {
+		_ = c.Error(err)
+		return
 	}
+	setETag(c, &user.BaseObject)
 
-	if userUpdated.Description != "" {
-		replaceAttr["description"] = []string{userUpdated.Description}
-	}
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"user": ldap.ProjectObject(&user, attributes)})
+}
 
-	if userUpdated.UserAccountControl != 0 {
-		replaceAttr["userAccountControl"] = []string{fmt.Sprintf("%d", userUpdated.UserAccountControl)}
-	}
+// mailboxAttrs 邮箱相关接口返回的用户属性
+var mailboxAttrs = []string{"mail", "mailNickname", "proxyAddresses", "legacyExchangeDN", "homeMDB", "mDBUseDefaults", "mDBStorageQuota", "mDBOverQuotaLimit", "mDBOverHardQuotaLimit"}
 
-	if len(userUpdated.ProxyAddresses) > 0 {
-		replaceAttr["proxyAddresses"] = userUpdated.ProxyAddresses
+// updateMailbox 获取用户后执行邮箱变更，返回变更后用户的邮箱属性
+func updateMailbox(c *gin.Context, update func(userDN, version string) error) {
+	user, err := ldap.GetUser(c, c.Param("user_id"), c.Query("user_id_type"), c.Query("search_base"), "distinguishedName")
+	if err != nil {
+		_ = c.Error(err)
+		return
 	}
 
-	if userUpdated.Mail != "" {
-		replaceAttr["mail"] = []string{userUpdated.Mail}
+	if err = update(user.DistinguishedName, ifMatch(c)); err != nil {
+		_ = c.Error(err)
+		return
 	}
 
-	err = ldap.ModifyObj(user.DistinguishedName, replaceAttr)
-	if err != nil {
+	user, _ = ldap.GetUser(c, user.DistinguishedName, "distinguishedName", "", mailboxAttrs...)
+	setETag(c, &user.BaseObject)
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"user": ldap.ProjectObject(&user, mailboxAttrs)})
+}
+
+func handleAddProxyAddress(c *gin.Context) {
+	c.Set("opt", "添加LDAP用户SMTP地址")
+
+	var req proxyAddressRequest
+	if err := bindRequest(c, &req); err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	// 如果要修改OU，则最后单独修改
-	if userUpdated.OU != "" {
-		err = ldap.MoveObjectToOU(c, user.DistinguishedName, userUpdated.OU)
-		if err != nil {
-			_ = c.Error(err)
-			return
+	updateMailbox(c, func(userDN, version string) error {
+		if req.Primary {
+			return ldap.SetPrimarySMTPAddress(c, userDN, version, req.Address)
 		}
-	}
+		return ldap.AddProxyAddress(c, userDN, version, req.Address)
+	})
+}
 
-	user, _ = ldap.GetUser(c, userId, userIdType, searchBase)
+func handleRemoveProxyAddress(c *gin.Context) {
+	c.Set("opt", "移除LDAP用户SMTP地址")
 
-	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"user": user})
+	updateMailbox(c, func(userDN, version string) error {
+		return ldap.RemoveProxyAddress(c, userDN, version, c.Param("address"))
+	})
 }
 
-func handleGetGroup(c *gin.Context) {
-	c.Set("opt", "获取LDAP群组信息")
-
-	groupId := c.Param("group_id")
-	groupIdType := c.Query("group_id_type")
-	searchBase := c.Query("search_base")
+func handleSetPrimarySMTPAddress(c *gin.Context) {
+	c.Set("opt", "设置LDAP用户主SMTP地址")
 
-	group, err := ldap.GetGroup(c, groupId, groupIdType, searchBase)
-	if err != nil {
+	var req primarySMTPAddressRequest
+	if err := bindRequest(c, &req); err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"group": group})
+	updateMailbox(c, func(userDN, version string) error {
+		return ldap.SetPrimarySMTPAddress(c, userDN, version, req.Address)
+	})
 }
 
-func handleNewGroup(c *gin.Context) {
-	c.Set("opt", "创建LDAP群组")
-	var group struct {
-		DisplayName    string `json:"displayName"`
-		OU             string `json:"OU"`
-		SAMAccountName string `json:"sAMAccountName"`
-		Description    string `json:"description"`
-		GroupType      int    `json:"groupType"`
+func handleSetMailboxQuota(c *gin.Context) {
+	c.Set("opt", "设置LDAP用户邮箱配额")
+
+	var req mailboxQuotaRequest
+	if err := bindRequest(c, &req); err != nil {
+		_ = c.Error(err)
+		return
 	}
-	if err := c.ShouldBindJSON(&group); err != nil {
-		_ = c.Error(&ers.InvalidJsonErr{})
+
+	updateMailbox(c, func(userDN, version string) error {
+		return ldap.SetMailboxQuota(c, userDN, version, ldap.MailboxQuota{
+			UseDefaults:        req.UseDefaults,
+			StorageQuota:       req.StorageQuota,
+			OverQuotaLimit:     req.OverQuotaLimit,
+			OverHardQuotaLimit: req.OverHardQuotaLimit,
+		})
+	})
+}
+
+func handleSetManager(c *gin.Context) {
+	c.Set("opt", "设置LDAP用户经理")
+
+	var req managerRequest
+	if err := bindRequest(c, &req); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	err := ldap.CreateGroup(c, group.SAMAccountName, group.OU, group.DisplayName, group.Description, group.GroupType)
+	user, err := ldap.GetUser(c, c.Param("user_id"), c.Query("user_id_type"), c.Query("search_base"), "distinguishedName")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	manager, err := ldap.GetUser(c, req.Manager, c.Query("manager_id_type"), c.Query("search_base"), "distinguishedName")
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	groupInfo, _ := ldap.GetGroup(c, group.SAMAccountName, "sAMAccountName", "")
-	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"group": groupInfo})
+	if err = ldap.SetManager(c, user.DistinguishedName, ifMatch(c), manager.DistinguishedName); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	respondManager(c, user.DistinguishedName)
 }
 
-func handleGroupMemberUpdate(c *gin.Context) {
-	c.Set("opt", "更新LDAP群组成员")
+func handleClearManager(c *gin.Context) {
+	c.Set("opt", "清除LDAP用户经理")
 
-	groupId := c.Param("group_id")
-	groupIdType := c.Query("group_id_type")
-	memberIdType := c.Query("member_id_type")
-	searchBase := c.Query("search_base")
+	user, err := ldap.GetUser(c, c.Param("user_id"), c.Query("user_id_type"), c.Query("search_base"), "distinguishedName")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-	var memberChanged struct {
-		AddMembers    []string `json:"add_members"`
-		RemoveMembers []string `json:"remove_members"`
+	if err = ldap.SetManager(c, user.DistinguishedName, ifMatch(c), ""); err != nil {
+		_ = c.Error(err)
+		return
 	}
-	if err := c.ShouldBindJSON(&memberChanged); err != nil {
-		_ = c.Error(&ers.InvalidJsonErr{})
+	respondManager(c, user.DistinguishedName)
+}
+
+// respondManager 返回变更后用户的经理属性
+func respondManager(c *gin.Context, userDN string) {
+	user, _ := ldap.GetUser(c, userDN, "distinguishedName", "", "manager")
+	setETag(c, &user.BaseObject)
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"user": ldap.ProjectObject(&user, []string{"manager"})})
+}
+
+func handleGetOrgChart(c *gin.Context) {
+	c.Set("opt", "获取LDAP用户汇报关系")
+
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "1"))
+	if err != nil || depth <= 0 || depth > 10 {
+		_ = c.Error(&ers.InvalidFormatErr{Name: "depth", Object: c.Query("depth")})
 		return
 	}
 
-	// 查找群组
-	group, err := ldap.GetGroup(c, groupId, groupIdType, searchBase)
+	chart, err := ldap.GetOrgChart(c, c.Param("user_id"), c.Query("user_id_type"), c.Query("search_base"), depth)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"orgchart": chart})
+}
 
-	var (
-		message       = "ok"
-		addMembers    []string
-		removeMembers []string
-	)
+func handleGetUserPhoto(c *gin.Context) {
+	c.Set("opt", "获取LDAP用户照片")
+
+	data, user, err := ldap.GetUserPhoto(c, c.Param("user_id"), c.Query("user_id_type"), c.Query("search_base"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	setETag(c, &user)
+	c.Data(http.StatusOK, http.DetectContentType(data), data)
+}
 
-	var user ldap.User
+// readPhoto 读取上传的照片，支持multipart/form-data中的photo字段或直接以图片作为请求体
+func readPhoto(c *gin.Context) ([]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.PhotoConfig.MaxUploadBytes)
 
-	for _, am := range memberChanged.AddMembers {
-		user, err = ldap.GetUser(c, am, memberIdType, searchBase)
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		file, err := c.FormFile("photo")
 		if err != nil {
-			message = fmt.Sprintf("%s;add failed: %s", message, err.Error())
-			continue
+			return nil, photoReadErr(err)
 		}
-		addMembers = append(addMembers, user.DistinguishedName)
-	}
-
-	for _, rm := range memberChanged.RemoveMembers {
-		user, err = ldap.GetUser(c, rm, memberIdType, searchBase)
+		f, err := file.Open()
 		if err != nil {
-			message = fmt.Sprintf("%s;remove failed: %s", message, err.Error())
-			continue
+			return nil, &ers.SystemErr{Message: err.Error()}
 		}
-		removeMembers = append(removeMembers, user.DistinguishedName)
+		defer f.Close()
+		reader = f
 	}
 
-	if len(addMembers) > 0 {
-		err = ldap.AddGroupMembers(c, group.DistinguishedName, "distinguishedName", addMembers...)
-		if err != nil {
-			message = fmt.Sprintf("%s;add failed%s", message, err.Error())
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, photoReadErr(err)
+	}
+	if len(data) == 0 {
+		return nil, &ers.BadRequestErr{Message: "photo is required"}
+	}
+	return data, nil
+}
 
-		}
+// photoReadErr 将读取上传照片的错误转换为请求错误，超过上传大小限制时提示限制大小
+func photoReadErr(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &ers.BadRequestErr{Message: fmt.Sprintf("photo must not exceed %d bytes", tooLarge.Limit)}
+	}
+	if errors.Is(err, http.ErrMissingFile) {
+		return &ers.BadRequestErr{Message: "photo is required"}
 	}
+	return &ers.BadRequestErr{Message: err.Error()}
+}
 
-	if len(removeMembers) > 0 {
-		err = ldap.RemoveGroupMembers(c, group.DistinguishedName, "distinguishedName", removeMembers...)
-		if err != nil {
-			message = fmt.Sprintf("%s;remove failed: %s", message, err.Error())
-		}
+func handleSetUserPhoto(c *gin.Context) {
+	c.Set("opt", "设置LDAP用户照片")
+
+	data, err := readPhoto(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
 	}
+	updateUserPhoto(c, data)
+}
 
-	group, _ = ldap.GetGroup(c, groupId, groupIdType, searchBase)
-	JsonWithTraceId(c, http.StatusOK, 0, message, map[string]interface{}{"group": group})
+func handleDeleteUserPhoto(c *gin.Context) {
+	c.Set("opt", "删除LDAP用户照片")
+	updateUserPhoto(c, nil)
 }
 
-func handleGroupUpdate(c *gin.Context) {
-	c.Set("opt", "更新LDAP群组信息")
+// updateUserPhoto 设置或清除用户的照片，返回实际写入的照片大小及类型
+func updateUserPhoto(c *gin.Context, data []byte) {
+	user, err := ldap.GetUser(c, c.Param("user_id"), c.Query("user_id_type"), c.Query("search_base"), "distinguishedName")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-	groupId := c.Param("group_id")
-	groupIdType := c.Query("group_id_type")
-	searchBase := c.Query("search_base")
+	if data, err = ldap.SetUserPhoto(c, user.DistinguishedName, ifMatch(c), data); err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-	var groupUpdated struct {
-		DisplayName    string   `json:"displayName"`
-		Description    string   `json:"description"`
-		ProxyAddresses []string `json:"proxyAddresses"`
-		Mail           string   `json:"mail"`
+	if user, err = ldap.GetUser(c, user.DistinguishedName, "distinguishedName", "", "distinguishedName"); err != nil {
+		_ = c.Error(err)
+		return
 	}
-	if err := c.ShouldBindJSON(&groupUpdated); err != nil {
-		_ = c.Error(&ers.InvalidJsonErr{})
+	setETag(c, &user.BaseObject)
+	result := map[string]interface{}{"size": len(data)}
+	if len(data) > 0 {
+		result["content_type"] = http.DetectContentType(data)
 	}
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"photo": result})
+}
 
-	group, err := ldap.GetGroup(c, groupId, groupIdType, searchBase)
+func handleLookupUsers(c *gin.Context) {
+	c.Set("opt", "批量获取LDAP用户信息")
+	attributes := parseAttributes(c)
+
+	var req lookupRequest
+	if err := bindRequest(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	users, notFound, err := ldap.LookupUsers(c, req.IDs, req.IDType, c.Query("search_base"), attributes)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	// 建立一个map来存储要修改的属性
-	var replaceAttr = map[string][]string{}
+	projected := make(map[string]interface{}, len(users))
+	for id, user := range users {
+		projected[id] = ldap.ProjectObject(user, attributes)
+	}
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"users": projected, "not_found": notFoundList(notFound)})
+}
+
+func handleLookupGroups(c *gin.Context) {
+	c.Set("opt", "批量获取LDAP群组信息")
+	attributes := parseAttributes(c)
+
+	var req lookupRequest
+	if err := bindRequest(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	groups, notFound, err := ldap.LookupGroups(c, req.IDs, req.IDType, c.Query("search_base"), attributes)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-	if groupUpdated.DisplayName != "" {
-		replaceAttr["displayName"] = []string{groupUpdated.DisplayName}
+	projected := make(map[string]interface{}, len(groups))
+	for id, group := range groups {
+		projected[id] = ldap.ProjectObject(group, attributes)
 	}
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"groups": projected, "not_found": notFoundList(notFound)})
+}
 
-	if groupUpdated.Description != "" {
-		replaceAttr["description"] = []string{groupUpdated.Description}
+// notFoundList 未找到的标识为空时返回空列表，避免响应中出现null
+func notFoundList(notFound []string) []string {
+	if notFound == nil {
+		return []string{}
 	}
+	return notFound
+}
+
+func handleSearchPeople(c *gin.Context) {
+	c.Set("opt", "搜索人员")
 
-	if len(groupUpdated.ProxyAddresses) > 0 {
-		replaceAttr["proxyAddresses"] = groupUpdated.ProxyAddresses
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		_ = c.Error(&ers.BadRequestErr{Message: "q is required"})
+		return
+	}
+	if utf8.RuneCountInString(q) < config.TypeaheadConfig.MinQueryLength {
+		_ = c.Error(&ers.BadRequestErr{Message: fmt.Sprintf("q must be at least %d characters", config.TypeaheadConfig.MinQueryLength)})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > config.TypeaheadConfig.SizeLimit {
+		_ = c.Error(&ers.InvalidFormatErr{Name: "limit", Object: c.Query("limit")})
+		return
 	}
 
-	if groupUpdated.Mail != "" {
-		replaceAttr["mail"] = []string{groupUpdated.Mail}
+	people, err := ldap.SearchPeople(c, q, limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
 	}
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"people": people})
+}
+
+func handleGetGroup(c *gin.Context) {
+	c.Set("opt", "获取LDAP群组信息")
+
+	groupId := c.Param("group_id")
+	groupIdType := c.Query("group_id_type")
+	searchBase := c.Query("search_base")
+	attributes := parseAttributes(c)
 
-	err = ldap.ModifyObj(group.DistinguishedName, replaceAttr)
+	group, err := ldap.GetGroup(c, groupId, groupIdType, searchBase, attributes...)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	group, _ = ldap.GetGroup(c, groupId, groupIdType, searchBase)
+	setETag(c, &group.BaseObject)
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"group": ldap.ProjectObject(&group, attributes)})
+}
 
-	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"group": group})
+func handleGetMember(c *gin.Context) {
+	c.Set("opt", "解析LDAP群组成员")
+
+	member, err := ldap.GetMember(c, c.Param("member_id"), c.Query("member_id_type"), c.Query("search_base"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setETag(c, &member)
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"member": member})
+}
+
+func handleNewGroup(c *gin.Context) {
+	c.Set("opt", "创建LDAP群组")
+	var group createGroupRequest
+	if err := bindRequest(c, &group); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err := ldap.CreateGroup(c, group.SAMAccountName, group.OU, group.DisplayName, group.Description, group.GroupType)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	groupInfo, _ := ldap.GetGroup(c, group.SAMAccountName, "sAMAccountName", "")
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"group": groupInfo})
+}
+
+func handleGroupMemberUpdate(c *gin.Context) {
+	c.Set("opt", "更新LDAP群组成员")
+
+	groupId := c.Param("group_id")
+	groupIdType := c.Query("group_id_type")
+	memberIdType := c.Query("member_id_type")
+	searchBase := c.Query("search_base")
+	version := ifMatch(c)
+
+	var memberChanged groupMemberRequest
+	if err := bindRequest(c, &memberChanged); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	for _, em := range memberChanged.AddExpiringMembers {
+		if !em.ExpiresAt.After(time.Now()) {
+			_ = c.Error(&ers.BadRequestErr{Message: fmt.Sprintf("expires_at of member '%s' must be in the future", em.Member)})
+			return
+		}
+	}
+
+	// 查找群组
+	group, err := ldap.GetGroup(c, groupId, groupIdType, searchBase)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// owner模式下只允许所有者或共同管理者更新成员
+	if err = checkGroupOwner(c, &group); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// 在解析成员前提前校验版本，避免部分成员变更后才发现版本不一致
+	if version != "" && version != group.USNChanged {
+		_ = c.Error(&ers.PreconditionFailedErr{Object: group.DistinguishedName, Version: version})
+		return
+	}
+
+	failures, err := updateGroupMembers(c, group.DistinguishedName, memberIdType, searchBase, version, memberChanged.AddMembers, memberChanged.AddExpiringMembers, memberChanged.RemoveMembers)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	message := "ok"
+	for _, failure := range failures {
+		message = fmt.Sprintf("%s;%s", message, failure)
+	}
+
+	group, _ = ldap.GetGroup(c, groupId, groupIdType, searchBase)
+	setETag(c, &group.BaseObject)
+	JsonWithTraceId(c, http.StatusOK, 0, message, map[string]interface{}{"group": group, "expirations": expiry.Pending(group.DistinguishedName)})
+}
+
+func handleSetGroupOwner(c *gin.Context) {
+	c.Set("opt", "设置LDAP群组所有者")
+	var owner groupOwnerRequest
+	if err := bindRequest(c, &owner); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	group, err := ldap.GetGroup(c, c.Param("group_id"), c.Query("group_id_type"), c.Query("search_base"), "distinguishedName")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	member, err := ldap.GetMember(c, owner.Owner, c.Query("owner_id_type"), c.Query("search_base"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err = ldap.SetGroupOwner(c, group.DistinguishedName, member.DistinguishedName, ifMatch(c)); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	respondGroupManagers(c, group.DistinguishedName)
+}
+
+func handleClearGroupOwner(c *gin.Context) {
+	c.Set("opt", "清空LDAP群组所有者")
+	group, err := ldap.GetGroup(c, c.Param("group_id"), c.Query("group_id_type"), c.Query("search_base"), "distinguishedName")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err = ldap.SetGroupOwner(c, group.DistinguishedName, "", ifMatch(c)); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	respondGroupManagers(c, group.DistinguishedName)
+}
+
+func handleSetGroupCoManagers(c *gin.Context) {
+	c.Set("opt", "设置LDAP群组共同管理者")
+	var managers groupCoManagersRequest
+	if err := bindRequest(c, &managers); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	group, err := ldap.GetGroup(c, c.Param("group_id"), c.Query("group_id_type"), c.Query("search_base"), "distinguishedName")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// 共同管理者整体替换，任一管理者无法解析时不做修改
+	var dns []string
+	for _, id := range managers.CoManagers {
+		member, err := ldap.GetMember(c, id, c.Query("manager_id_type"), c.Query("search_base"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		dns = append(dns, member.DistinguishedName)
+	}
+
+	if err = ldap.SetGroupCoManagers(c, group.DistinguishedName, ifMatch(c), dns...); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	respondGroupManagers(c, group.DistinguishedName)
+}
+
+func handleClearGroupCoManagers(c *gin.Context) {
+	c.Set("opt", "清空LDAP群组共同管理者")
+	group, err := ldap.GetGroup(c, c.Param("group_id"), c.Query("group_id_type"), c.Query("search_base"), "distinguishedName")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err = ldap.SetGroupCoManagers(c, group.DistinguishedName, ifMatch(c)); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	respondGroupManagers(c, group.DistinguishedName)
+}
+
+// respondGroupManagers 返回变更后群组的所有者及共同管理者
+func respondGroupManagers(c *gin.Context, groupDN string) {
+	attributes := []string{"managedBy", "msExchCoManagedByLink"}
+	group, _ := ldap.GetGroup(c, groupDN, "distinguishedName", "", attributes...)
+	setETag(c, &group.BaseObject)
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"group": ldap.ProjectObject(&group, attributes)})
+}
+
+func handleGroupUpdate(c *gin.Context) {
+	c.Set("opt", "更新LDAP群组信息")
+
+	groupId := c.Param("group_id")
+	groupIdType := c.Query("group_id_type")
+	searchBase := c.Query("search_base")
+
+	// 支持JSON Merge Patch（null清空属性）以及JSON Patch风格的操作列表，群组不支持通过此接口移动OU
+	patch, err := parsePatch(c, groupPatchAttrs, nil)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if patch.OU != "" {
+		_ = c.Error(&ers.UnSupportedErr{Object: "OU", ObjectType: "attribute"})
+		return
+	}
+
+	group, err := ldap.GetGroup(c, groupId, groupIdType, searchBase)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = ldap.ApplyChanges(c, group.DistinguishedName, ifMatch(c), patch.Changes)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	group, _ = ldap.GetGroup(c, groupId, groupIdType, searchBase)
+	setETag(c, &group.BaseObject)
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"group": group})
+}
+
+func handleGetComputer(c *gin.Context) {
+	c.Set("opt", "获取LDAP计算机信息")
+	computerId := c.Param("computer_id")
+	computerIdType := c.Query("computer_id_type")
+	searchBase := c.Query("search_base")
+	attributes := parseAttributes(c)
+
+	computer, err := ldap.GetComputer(c, computerId, computerIdType, searchBase, attributes...)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	setETag(c, &computer.BaseObject)
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"computer": ldap.ProjectObject(&computer, attributes)})
+}
+
+func handleNewComputer(c *gin.Context) {
+	c.Set("opt", "预创建LDAP计算机账户")
+	var computer createComputerRequest
+	if err := bindRequest(c, &computer); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err := ldap.CreateComputer(c, computer.Name, computer.OU, computer.DNSHostName, computer.Description)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	computerInfo, _ := ldap.GetComputer(c, computer.Name, "sAMAccountName", "")
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"computer": computerInfo})
+}
+
+func handleComputerUpdate(c *gin.Context) {
+	c.Set("opt", "更新LDAP计算机信息")
+	computerId := c.Param("computer_id")
+	computerIdType := c.Query("computer_id_type")
+	searchBase := c.Query("search_base")
+
+	// 与用户一致，支持JSON Merge Patch以及JSON Patch风格的操作列表，OU用于移动计算机
+	patch, err := parsePatch(c, computerPatchAttrs, computerRequiredAttrs)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	computer, err := ldap.GetComputer(c, computerId, computerIdType, searchBase)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err = applyObjPatch(c, computer.DistinguishedName, ifMatch(c), patch); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	attributes := parseAttributes(c)
+	if computer, err = ldap.GetComputer(c, computer.ObjectGUID, "objectGUID", "", attributes...); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	setETag(c, &computer.BaseObject)
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"computer": ldap.ProjectObject(&computer, attributes)})
+}
+
+func handleComputerDisable(c *gin.Context) {
+	c.Set("opt", "禁用LDAP计算机账户")
+	computerId := c.Param("computer_id")
+	computerIdType := c.Query("computer_id_type")
+	searchBase := c.Query("search_base")
+
+	computer, err := ldap.GetComputer(c, computerId, computerIdType, searchBase, "distinguishedName")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err = ldap.DisableComputer(c, computer.DistinguishedName, ifMatch(c)); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	computer, _ = ldap.GetComputer(c, computer.DistinguishedName, "distinguishedName", "")
+	setETag(c, &computer.BaseObject)
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"computer": computer})
+}
+
+func handleGetContact(c *gin.Context) {
+	c.Set("opt", "获取LDAP联系人信息")
+	contactId := c.Param("contact_id")
+	contactIdType := c.Query("contact_id_type")
+	searchBase := c.Query("search_base")
+	attributes := parseAttributes(c)
+
+	contact, err := ldap.GetContact(c, contactId, contactIdType, searchBase, attributes...)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	setETag(c, &contact.BaseObject)
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"contact": ldap.ProjectObject(&contact, attributes)})
+}
+
+func handleNewContact(c *gin.Context) {
+	c.Set("opt", "创建LDAP联系人")
+	var contact createContactRequest
+	if err := bindRequest(c, &contact); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := ldap.CreateContact(c, contact.contact()); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	contactInfo, _ := ldap.GetContact(c, contact.Mail, "mail", "")
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"contact": contactInfo})
+}
+
+func handleContactUpdate(c *gin.Context) {
+	c.Set("opt", "更新LDAP联系人信息")
+	contactId := c.Param("contact_id")
+	contactIdType := c.Query("contact_id_type")
+	searchBase := c.Query("search_base")
+
+	patch, err := parsePatch(c, contactPatchAttrs, contactRequiredAttrs)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	contact, err := ldap.GetContact(c, contactId, contactIdType, searchBase)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err = applyObjPatch(c, contact.DistinguishedName, ifMatch(c), patch); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	attributes := parseAttributes(c)
+	if contact, err = ldap.GetContact(c, contact.ObjectGUID, "objectGUID", "", attributes...); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	setETag(c, &contact.BaseObject)
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"contact": ldap.ProjectObject(&contact, attributes)})
+}
+
+func handleDeleteContact(c *gin.Context) {
+	c.Set("opt", "删除LDAP联系人")
+	contactId := c.Param("contact_id")
+	contactIdType := c.Query("contact_id_type")
+	searchBase := c.Query("search_base")
+
+	contact, err := ldap.GetContact(c, contactId, contactIdType, searchBase)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err = ldap.DeleteObj(c, contact.DistinguishedName, ifMatch(c)); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", nil)
+}
+
+func handleNewBulkJob(c *gin.Context) {
+	c.Set("opt", "提交LDAP批量任务")
+
+	var bulkJob bulkJobRequest
+	if err := bindRequest(c, &bulkJob); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// owner模式只允许提交成员变更，任务执行时以调用方的身份校验群组的所有者
+	caller, err := ownerCaller(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// update_user与单个用户的PATCH接口一样，只允许变更userPatchAttrs中的属性
+	for i, op := range bulkJob.Operations {
+		if caller != nil && op.Type != bulk.OpUpdateMembers {
+			_ = c.Error(&ers.ForbiddenErr{Message: fmt.Sprintf("operations[%d]: owner mode only allows updating group members", i)})
+			return
+		}
+		if op.Type != bulk.OpUpdateUser {
+			continue
+		}
+		if err := checkChanges(op.Changes, userPatchAttrs, userRequiredAttrs); err != nil {
+			_ = c.Error(&ers.BadRequestErr{Message: fmt.Sprintf("operations[%d]: %s", i, err.Error())})
+			return
+		}
+	}
+
+	job, err := bulk.SubmitJob(c, bulkJob.Operations)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	JsonWithTraceId(c, http.StatusAccepted, 0, "ok", map[string]interface{}{"job": job})
+}
+
+func handleGetJob(c *gin.Context) {
+	c.Set("opt", "获取LDAP批量任务状态")
+
+	job, err := bulk.GetJob(c.Param("job_id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"job": job})
+}
+
+func handleCancelJob(c *gin.Context) {
+	c.Set("opt", "取消LDAP批量任务")
+
+	jobId := c.Param("job_id")
+	err := bulk.CancelJob(c, jobId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	job, _ := bulk.GetJob(jobId)
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"job": job})
+}
+
+func handleExport(c *gin.Context) {
+	c.Set("opt", "导出LDAP对象")
+
+	format := c.DefaultQuery("format", transfer.FormatJSONL)
+	objType := c.DefaultQuery("type", transfer.TypeUser)
+	if err := transfer.CheckExport(format, objType); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", transfer.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%ss.%s", objType, format))
+	err := transfer.Export(c, c.Writer, format, objType, c.Query("filter"), c.Query("search_base"), parseAttributes(c))
+	if err != nil {
+		// 尚未输出任何内容时，仍然可以返回json格式的错误
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+		}
+		_ = c.Error(err)
+	}
+}
+
+func handleImport(c *gin.Context) {
+	c.Set("opt", "导入LDAP对象")
+
+	dryRun := c.Query("dry_run") == "true"
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, 32<<20)
+
+	var (
+		report *transfer.Report
+		err    error
+	)
+	switch format := c.DefaultQuery("format", transfer.FormatCSV); format {
+	case transfer.FormatCSV:
+		var mapping map[string]string
+		mapping, err = transfer.ParseMapping(c.Query("mapping"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		report, err = transfer.ImportCSV(c, body, transfer.CSVOptions{
+			ObjType: c.DefaultQuery("type", transfer.TypeUser),
+			Mode:    c.DefaultQuery("mode", transfer.ModeCreate),
+			Key:     c.Query("key"),
+			Mapping: mapping,
+			DryRun:  dryRun,
+		})
+	case transfer.FormatLDIF:
+		report, err = transfer.ImportLDIF(c, body, dryRun)
+	default:
+		err = &ers.UnSupportedErr{Object: format, ObjectType: "import format"}
+	}
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"report": report})
+}
+
+// parseCursor 解析变更游标，未指定时返回-1
+func parseCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return -1, nil
+	}
+	value, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || value < 0 {
+		return 0, &ers.InvalidFormatErr{Name: "cursor", Object: cursor}
+	}
+	return value, nil
+}
+
+func handleChanges(c *gin.Context) {
+	c.Set("opt", "获取目录变更事件")
+
+	cursor, err := parseCursor(c.Query("since"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		_ = c.Error(&ers.InvalidFormatErr{Name: "limit", Object: c.Query("limit")})
+		return
+	}
+
+	events, next, err := changes.Events(cursor, limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"events": events, "cursor": next})
+}
+
+func handleChangeStream(c *gin.Context) {
+	c.Set("opt", "订阅目录变更事件")
+
+	// 断线重连时优先使用浏览器自动携带的Last-Event-ID
+	since := c.GetHeader("Last-Event-ID")
+	if since == "" {
+		since = c.Query("since")
+	}
+	cursor, err := parseCursor(since)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	backlog, events, cancel, err := changes.Subscribe(cursor)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	writeEvent := func(w io.Writer, event changes.Event) {
+		data, _ := json.Marshal(event)
+		_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	}
+	for _, event := range backlog {
+		writeEvent(c.Writer, event)
+	}
+	c.Writer.Flush()
+
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			writeEvent(w, event)
+			return true
+		case <-keepalive.C:
+			_, _ = io.WriteString(w, ": keepalive\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func handleNewWebhook(c *gin.Context) {
+	c.Set("opt", "创建webhook订阅")
+
+	var subscription webhookRequest
+	if err := bindRequest(c, &subscription); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	created, err := webhook.CreateSubscription(c, subscription.subscription())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"webhook": created})
+}
+
+func handleListWebhooks(c *gin.Context) {
+	c.Set("opt", "获取webhook订阅列表")
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"webhooks": webhook.ListSubscriptions()})
+}
+
+func handleDeleteWebhook(c *gin.Context) {
+	c.Set("opt", "删除webhook订阅")
+
+	err := webhook.DeleteSubscription(c, c.Param("webhook_id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", nil)
+}
+
+func handleListDeadDeliveries(c *gin.Context) {
+	c.Set("opt", "获取webhook失败的投递")
+
+	deliveries, err := webhook.ListDeadDeliveries(c.Param("webhook_id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", map[string]interface{}{"deliveries": deliveries})
+}
+
+func handleReplayDelivery(c *gin.Context) {
+	c.Set("opt", "重新投递webhook事件")
+
+	err := webhook.ReplayDelivery(c, c.Param("webhook_id"), c.Param("delivery_id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", nil)
+}
+
+func handleDeleteDelivery(c *gin.Context) {
+	c.Set("opt", "删除webhook失败的投递")
+
+	err := webhook.DeleteDelivery(c, c.Param("webhook_id"), c.Param("delivery_id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	JsonWithTraceId(c, http.StatusOK, 0, "ok", nil)
 }
@@ -5,8 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 	"ldap-http-service/config"
+	"ldap-http-service/core/bulk"
+	"ldap-http-service/core/changes"
+	"ldap-http-service/core/expiry"
+	"ldap-http-service/core/webhook"
+	"ldap-http-service/lib/idempotency"
 	"ldap-http-service/lib/logger"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -18,30 +25,86 @@ func main() {
 	// 加载配置
 	config.LoadConfig()
 
+	// 加载幂等键存储
+	store, err := idempotency.NewStore(config.IdempotencyConfig.Path, config.IdempotencyConfig.TTL, config.IdempotencyConfig.MaxEntries)
+	if err != nil {
+		logger.GinLogger.Fatal("异常: 幂等键存储加载失败", err)
+	}
+
+	// 加载批量任务，继续执行重启前未完成的任务
+	if err = bulk.Init(context.Background()); err != nil {
+		logger.GinLogger.Fatal("异常: 批量任务加载失败", err)
+	}
+
+	// 启动目录变更跟踪
+	if err = changes.Init(context.Background()); err != nil {
+		logger.GinLogger.Fatal("异常: 目录变更跟踪启动失败", err)
+	}
+
+	// 启动webhook分发
+	if err = webhook.Init(context.Background()); err != nil {
+		logger.GinLogger.Fatal("异常: webhook分发器启动失败", err)
+	}
+
+	// 加载限时群组成员，按时移除到期的成员
+	if err = expiry.Init(context.Background()); err != nil {
+		logger.GinLogger.Fatal("异常: 限时群组成员调度器启动失败", err)
+	}
+
 	// 启动gin并配置中间件等
 	router := gin.New()
-	router.Use(processRequest(logger.GinLogger), ginLog(logger.GinLogger), errorHandler(logger.GinLogger))
+	// handler以gin.Context作为上下文调用core时，可以读取到中间件写入请求上下文中的值，例如跳过缓存的标记
+	router.ContextWithFallback = true
+	router.Use(processRequest(logger.GinLogger), ginLog(logger.GinLogger), idempotent(logger.GinLogger, store), errorHandler(logger.GinLogger))
 
 	// 使用自定义的Logger的写入Gin日志
 	gin.DefaultWriter = logger.GinLogger.Writer()
 
 	// 设置可信代理IP
-	err := router.SetTrustedProxies([]string{"127.0.0.1"})
+	err = router.SetTrustedProxies([]string{"127.0.0.1"})
 	if err != nil {
 		return
 	}
 
-	// 注册路由
-	router.GET("/ldap/healthz", handleHealthz)
-	router.GET("/ldap/availability", handleCheckAvailability)
-	router.GET("/ldap/user/:user_id", handleGetUser)
-	router.POST("/ldap/user", handleNewEnableUser)
-	router.PATCH("/ldap/user/:user_id", handleUserUpdate)
-	router.POST("/ldap/user/:user_id/password", handleUserPwd)
-	router.GET("/ldap/group/:group_id", handleGetGroup)
-	router.POST("/ldap/group", handleNewGroup)
-	router.PATCH("/ldap/group/:group_id", handleGroupUpdate)
-	router.PUT("/ldap/group/:group_id/member", handleGroupMemberUpdate)
+	// 注册路由，路由定义同时用于生成OpenAPI文档
+	for _, route := range apiRoutes() {
+		router.Handle(route.Method, route.Path, ownerMode(route.OwnerMode), route.Handler)
+	}
+
+	// 注册SCIM 2.0路由
+	scimRouter := router.Group("/scim/v2")
+	scimRouter.GET("/ServiceProviderConfig", handleScimServiceProviderConfig)
+	scimRouter.GET("/ResourceTypes", handleScimResourceTypes)
+	scimRouter.GET("/ResourceTypes/:id", handleScimResourceTypes)
+	scimRouter.GET("/Schemas", handleScimSchemas)
+	scimRouter.GET("/Schemas/:id", handleScimSchemas)
+	scimRouter.GET("/Users", handleScimListUsers)
+	scimRouter.POST("/Users", handleScimCreateUser)
+	scimRouter.GET("/Users/:id", handleScimGetUser)
+	scimRouter.PUT("/Users/:id", handleScimReplaceUser)
+	scimRouter.PATCH("/Users/:id", handleScimPatchUser)
+	scimRouter.DELETE("/Users/:id", handleScimDeleteUser)
+	scimRouter.GET("/Groups", handleScimListGroups)
+	scimRouter.POST("/Groups", handleScimCreateGroup)
+	scimRouter.GET("/Groups/:id", handleScimGetGroup)
+	scimRouter.PUT("/Groups/:id", handleScimReplaceGroup)
+	scimRouter.PATCH("/Groups/:id", handleScimPatchGroup)
+	scimRouter.DELETE("/Groups/:id", handleScimDeleteGroup)
+
+	// 启动gRPC服务，与http服务使用不同的端口
+	var grpcSrv *grpc.Server
+	if config.GrpcConfig.Enabled {
+		lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.GrpcConfig.Listen, config.GrpcConfig.Port))
+		if err != nil {
+			logger.GrpcLogger.Fatal("异常: gRPC端口监听失败", err)
+		}
+		grpcSrv = newGrpcServer()
+		go func() {
+			if err := grpcSrv.Serve(lis); err != nil {
+				logger.GrpcLogger.Fatal("异常: gRPC启动失败", err)
+			}
+		}()
+	}
 
 	// 启动http服务
 	srv := &http.Server{
@@ -64,6 +127,20 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// 等待gRPC请求处理完成，超时后强制停止
+	if grpcSrv != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			grpcSrv.Stop()
+		}
+	}
+
 	// 程序退出异常，强行终止
 	if err = srv.Shutdown(ctx); err != nil {
 		logger.GinLogger.Fatal("Server forced to shutdown: ", err)
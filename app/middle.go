@@ -1,16 +1,23 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"io"
+	"ldap-http-service/core/ldap"
 	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/idempotency"
 	"ldap-http-service/lib/logger"
 	"ldap-http-service/lib/utils"
 	"net/http"
 	"runtime/debug"
+	"strings"
 	"time"
 )
 
@@ -51,6 +58,12 @@ func processRequest(logger *logrus.Entry) gin.HandlerFunc {
 		}
 		c.Set("trace_id", traceID)
 
+		// 请求要求不使用缓存时，读取用户及群组时跳过查询缓存；写请求中的读取用于版本校验、权限判断，同样跳过缓存
+		if strings.Contains(strings.ToLower(c.GetHeader("Cache-Control")), "no-cache") ||
+			(c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) {
+			c.Request = c.Request.WithContext(ldap.WithoutCache(c.Request.Context()))
+		}
+
 		// panic恢复和异常处理
 		defer func() {
 			if r := recover(); r != nil {
@@ -78,7 +91,12 @@ func errorHandler(logger *logrus.Entry) gin.HandlerFunc {
 			var customErr ers.CustomErr
 
 			if errors.As(c.Errors[0], &customErr) {
-				JsonWithTraceId(c, customErr.HttpCode(), customErr.Code(), customErr.Error(), nil)
+				// 携带详情的错误，将详情作为data返回
+				var data interface{}
+				if detailed, ok := customErr.(interface{ Details() interface{} }); ok {
+					data = detailed.Details()
+				}
+				JsonWithTraceId(c, customErr.HttpCode(), customErr.Code(), customErr.Error(), data)
 			} else {
 				// 其他未知错误
 				JsonWithTraceId(c, http.StatusInternalServerError, 1000, "Internal Server Error", map[string]interface{}{})
@@ -118,3 +136,137 @@ func ginLog(logger *logrus.Entry) gin.HandlerFunc {
 
 	}
 }
+
+// bodyRecorder 在写入响应的同时记录响应内容，用于幂等请求的结果重放
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// 幂等请求重放时一并返回的响应头，客户端重试创建或更新请求后可以继续执行条件请求
+var replayHeaders = []string{"ETag", "Location"}
+
+// requestFingerprint 以请求方法、路径、参数以及请求体作为请求指纹，相同的键只能用于相同的请求；
+// caller为owner模式下认证后的调用方DN，避免其他调用方通过相同的键重放响应，指纹中不包含认证信息本身
+func requestFingerprint(method, uri, caller string, body []byte) string {
+	fingerprint := method + " " + uri + "\n"
+	if caller != "" {
+		fingerprint += caller + "\n"
+	}
+	sum := sha256.Sum256(append([]byte(fingerprint), body...))
+	return hex.EncodeToString(sum[:])
+}
+
+// ownerMode owner模式只允许更新群组成员，allowed为false的写接口携带Basic认证时直接拒绝，需要以服务身份调用；
+// 读接口不以调用方的身份执行，忽略认证信息
+func ownerMode(allowed bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if allowed || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		if _, _, ok := c.Request.BasicAuth(); ok {
+			abortWithErr(c, &ers.ForbiddenErr{Message: "owner mode only allows updating group members"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// idempotent 幂等键中间件，携带Idempotency-Key请求头的写请求在TTL内重试时，直接重放首次请求的响应；
+// 需要注册在errorHandler之前，才能记录到由errorHandler写入的错误响应
+func idempotent(logger *logrus.Entry, store *idempotency.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" || c.Request.Method == http.MethodGet {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithErr(c, &ers.InvalidJsonErr{})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var callerDN string
+		if _, _, ok := c.Request.BasicAuth(); ok {
+			caller, err := ownerCaller(c)
+			if err != nil {
+				var customErr ers.CustomErr
+				if errors.As(err, &customErr) {
+					abortWithErr(c, customErr)
+					return
+				}
+				abortWithErr(c, &ers.SystemErr{Message: err.Error()})
+				return
+			}
+			callerDN = caller.DistinguishedName
+		}
+		record, err := store.Begin(key, requestFingerprint(c.Request.Method, c.Request.URL.RequestURI(), callerDN, body))
+		if err != nil {
+			var customErr ers.CustomErr
+			if errors.As(err, &customErr) {
+				abortWithErr(c, customErr)
+				return
+			}
+			abortWithErr(c, &ers.SystemErr{Message: err.Error()})
+			return
+		}
+		if record != nil {
+			logger.WithContext(c).Infof("幂等键 `%s` 已处理，重放首次请求的响应", key)
+			for name, value := range record.Headers {
+				c.Header(name, value)
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.Status, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// 处理过程中panic时放弃记录，允许客户端重试
+		defer func() {
+			if r := recover(); r != nil {
+				store.Abort(key)
+				panic(r)
+			}
+		}()
+
+		c.Next()
+
+		// 服务端异常的响应不做记录，允许客户端重试
+		if recorder.Status() >= http.StatusInternalServerError {
+			store.Abort(key)
+			return
+		}
+		headers := make(map[string]string)
+		for _, name := range replayHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		if err = store.Complete(key, recorder.Status(), recorder.Header().Get("Content-Type"), headers, recorder.body.Bytes()); err != nil {
+			logger.WithContext(c).Errorf("保存幂等键 `%s` 的处理结果失败: %v", key, err)
+		}
+	}
+}
+
+// abortWithErr 在中间件中直接返回错误响应并终止后续处理
+func abortWithErr(c *gin.Context, err ers.CustomErr) {
+	JsonWithTraceId(c, err.HttpCode(), err.Code(), err.Error(), nil)
+	c.Abort()
+}
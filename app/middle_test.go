@@ -0,0 +1,139 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"ldap-http-service/lib/idempotency"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	base := requestFingerprint(http.MethodPost, "/ldap/user", "", []byte(`{"a":1}`))
+	tests := []struct {
+		name   string
+		method string
+		uri    string
+		caller string
+		body   string
+		same   bool
+	}{
+		{name: "identical request", method: http.MethodPost, uri: "/ldap/user", body: `{"a":1}`, same: true},
+		{name: "different method", method: http.MethodPut, uri: "/ldap/user", body: `{"a":1}`},
+		{name: "different query", method: http.MethodPost, uri: "/ldap/user?search_base=OU=x", body: `{"a":1}`},
+		{name: "different body", method: http.MethodPost, uri: "/ldap/user", body: `{"a":2}`},
+		{name: "authenticated caller", method: http.MethodPost, uri: "/ldap/user", caller: "CN=alice,DC=example,DC=com", body: `{"a":1}`},
+		{name: "path and body boundary", method: http.MethodPost, uri: "/ldap/user\n{", body: `"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := requestFingerprint(tt.method, tt.uri, tt.caller, []byte(tt.body))
+			if (got == base) != tt.same {
+				t.Errorf("requestFingerprint() equal to base = %v, want %v", got == base, tt.same)
+			}
+		})
+	}
+}
+
+func TestIdempotentReplay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, err := idempotency.NewStore(filepath.Join(t.TempDir(), "idempotency.json"), time.Hour, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	router := gin.New()
+	router.Use(idempotent(logrus.NewEntry(logrus.New()), store))
+	router.POST("/ldap/user", func(c *gin.Context) {
+		calls++
+		c.Header("ETag", `"42"`)
+		c.Header("Location", "/ldap/user/alice")
+		c.String(http.StatusCreated, "created %d", calls)
+	})
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/ldap/user", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name         string
+		key          string
+		body         string
+		wantStatus   int
+		wantBody     string
+		wantReplayed bool
+	}{
+		{name: "first request", key: "k1", body: "{}", wantStatus: http.StatusCreated, wantBody: "created 1"},
+		{name: "retry is replayed", key: "k1", body: "{}", wantStatus: http.StatusCreated, wantBody: "created 1", wantReplayed: true},
+		{name: "key reused with another body", key: "k1", body: `{"a":1}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "new key runs again", key: "k2", body: "{}", wantStatus: http.StatusCreated, wantBody: "created 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(tt.key, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" {
+				body, _ := io.ReadAll(w.Body)
+				if string(body) != tt.wantBody {
+					t.Errorf("body = %q, want %q", body, tt.wantBody)
+				}
+				if w.Header().Get("ETag") != `"42"` || w.Header().Get("Location") != "/ldap/user/alice" {
+					t.Errorf("headers = %v, want ETag and Location of the first response", w.Header())
+				}
+			}
+			if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != tt.wantReplayed {
+				t.Errorf("replayed = %v, want %v", replayed, tt.wantReplayed)
+			}
+		})
+	}
+}
+
+func TestOwnerMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	for _, route := range apiRoutes() {
+		router.Handle(route.Method, route.Path, ownerMode(route.OwnerMode), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+	}
+
+	// owner模式只允许更新群组成员，批量任务在提交时再校验操作类型
+	allowed := map[string]bool{
+		http.MethodPut + " /ldap/group/:group_id/member": true,
+		http.MethodPost + " /ldap/bulk":                  true,
+	}
+	for _, route := range apiRoutes() {
+		path := strings.NewReplacer(":", "").Replace(route.Path)
+		for _, authorization := range []string{"", "Basic YWxpY2U6c2VjcmV0", "Bearer token"} {
+			req := httptest.NewRequest(route.Method, path, nil)
+			if authorization != "" {
+				req.Header.Set("Authorization", authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			want := http.StatusNoContent
+			isRead := route.Method == http.MethodGet || route.Method == http.MethodHead
+			if strings.HasPrefix(authorization, "Basic") && !isRead && !allowed[route.Method+" "+route.Path] {
+				want = http.StatusForbidden
+			}
+			if w.Code != want {
+				t.Errorf("%s %s with %q: status = %d, want %d", route.Method, route.Path, authorization, w.Code, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"ldap-http-service/core/ldap"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiParam 接口的查询参数，路径参数由路由自动生成
+type apiParam struct {
+	Name        string
+	Description string
+	Required    bool
+	Enum        []string
+}
+
+// rawContent 直接指定的请求体，key为Content-Type，value为对应的OpenAPI schema
+type rawContent map[string]interface{}
+
+// apiRoute 接口路由及其文档，注册路由与生成OpenAPI文档使用同一份定义
+type apiRoute struct {
+	Method  string
+	Path    string
+	Handler gin.HandlerFunc
+	Summary string
+	Params  []apiParam
+	// Body 请求体类型的零值或rawContent，nil代表没有请求体
+	Body interface{}
+	// Status 成功时的http状态码，默认为200
+	Status int
+	// Data 成功响应中data包含的字段及其类型的零值
+	Data map[string]interface{}
+	// Produces 不是标准json响应时的Content-Type
+	Produces string
+	// OwnerMode 是否允许以owner模式（携带Basic认证）调用，其他写接口携带Basic认证时拒绝
+	OwnerMode bool
+}
+
+var (
+	openAPIDoc  map[string]interface{}
+	openAPIOnce sync.Once
+)
+
+// 以字符串表示的时间类型
+var timeTypes = map[reflect.Type]bool{
+	reflect.TypeOf(time.Time{}):            true,
+	reflect.TypeOf(ldap.FileTime{}):        true,
+	reflect.TypeOf(ldap.GeneralizedTime{}): true,
+}
+
+// schemaBuilder 通过反射将Go类型转换为OpenAPI schema，具名结构体记录在components中
+type schemaBuilder struct {
+	schemas map[string]interface{}
+}
+
+// jsonType 返回Go类型在json中对应的类型名称
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case timeTypes[t]:
+		return "string"
+	case t.Kind() == reflect.Bool:
+		return "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "number"
+	case t.Kind() == reflect.String:
+		return "string"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return "array"
+	case t.Kind() == reflect.Struct || t.Kind() == reflect.Map:
+		return "object"
+	}
+	return "any"
+}
+
+// schemaName 返回具名结构体在components中的名称，首字母大写
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// schema 生成类型的schema，具名结构体返回$ref引用
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case timeTypes[t]:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case t.Kind() == reflect.Interface:
+		return map[string]interface{}{}
+	case t.Kind() == reflect.Struct && t.Name() == "":
+		return b.object(t)
+	case t.Kind() == reflect.Struct:
+		name := schemaName(t)
+		if _, ok := b.schemas[name]; !ok {
+			// 先占位，避免递归引用的类型重复生成
+			b.schemas[name] = nil
+			b.schemas[name] = b.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+
+	schema := map[string]interface{}{"type": jsonType(t)}
+	switch t.Kind() {
+	case reflect.Int32, reflect.Int64:
+		schema["format"] = t.Kind().String()
+	}
+	return schema
+}
+
+// object 生成结构体的schema，匿名嵌入的结构体展开到当前对象中
+func (b *schemaBuilder) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			name := jsonFieldName(field)
+			if !field.IsExported() || name == "" {
+				continue
+			}
+
+			property := b.schema(field.Type)
+			if _, ok := property["$ref"]; !ok {
+				applyRules(property, field)
+			}
+			if description := field.Tag.Get("description"); description != "" {
+				property["description"] = description
+			}
+			properties[name] = property
+			if rules := strings.Split(field.Tag.Get("binding"), ","); rules[0] == "required" {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// applyRules 将字段binding中的校验规则转换为schema约束
+func applyRules(schema map[string]interface{}, field reflect.StructField) {
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "max", "min":
+			value, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			switch schema["type"] {
+			case "array":
+				schema[name+"Items"] = value
+			case "string":
+				schema[name+"Length"] = value
+			default:
+				schema[map[string]string{"max": "maximum", "min": "minimum"}[name]] = value
+			}
+		case "oneof":
+			var enum []interface{}
+			for _, value := range strings.Fields(param) {
+				if number, err := strconv.Atoi(value); err == nil && schema["type"] == "integer" {
+					enum = append(enum, number)
+				} else {
+					enum = append(enum, value)
+				}
+			}
+			schema["enum"] = enum
+		case "http_url":
+			schema["format"] = "uri"
+		case "email":
+			schema["format"] = "email"
+		}
+	}
+}
+
+// response 生成统一响应格式的schema，data为nil时不限制data的内容
+func (b *schemaBuilder) response(data map[string]interface{}) map[string]interface{} {
+	dataSchema := map[string]interface{}{"type": "object", "nullable": true}
+	if data != nil {
+		properties := map[string]interface{}{}
+		for name, value := range data {
+			properties[name] = b.schema(reflect.TypeOf(value))
+		}
+		dataSchema = map[string]interface{}{"type": "object", "properties": properties}
+	}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"constants": map[string]interface{}{"type": "integer", "description": "业务错误码，成功时为0"},
+			"message":   map[string]interface{}{"type": "string"},
+			"data":      dataSchema,
+			"trace_id":  map[string]interface{}{"type": "string"},
+		},
+	}
+}
+
+// operationID 以handler的函数名作为operationId，例如handleGetUser为GetUser
+func operationID(handler gin.HandlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	return strings.TrimPrefix(name[strings.LastIndex(name, ".")+1:], "handle")
+}
+
+// operation 生成单个接口的OpenAPI operation
+func (b *schemaBuilder) operation(route apiRoute) map[string]interface{} {
+	var parameters []interface{}
+	for _, segment := range strings.Split(route.Path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			parameters = append(parameters, map[string]interface{}{
+				"name": segment[1:], "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+	for _, param := range route.Params {
+		schema := map[string]interface{}{"type": "string"}
+		if len(param.Enum) > 0 {
+			schema["enum"] = param.Enum
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name": param.Name, "in": "query", "required": param.Required, "description": param.Description, "schema": schema,
+		})
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	content := map[string]interface{}{"application/json": map[string]interface{}{"schema": b.response(route.Data)}}
+	if route.Produces != "" {
+		content = map[string]interface{}{route.Produces: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}}
+	}
+	op := map[string]interface{}{
+		"summary":     route.Summary,
+		"operationId": operationID(route.Handler),
+		"responses": map[string]interface{}{
+			strconv.Itoa(status): map[string]interface{}{"description": http.StatusText(status), "content": content},
+			"default":            map[string]interface{}{"$ref": "#/components/responses/Error"},
+		},
+	}
+
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+
+	switch body := route.Body.(type) {
+	case nil:
+	case rawContent:
+		requestContent := map[string]interface{}{}
+		for contentType, schema := range body {
+			requestContent[contentType] = map[string]interface{}{"schema": schema}
+		}
+		op["requestBody"] = map[string]interface{}{"required": true, "content": requestContent}
+	default:
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": b.schema(reflect.TypeOf(body))}},
+		}
+	}
+	return op
+}
+
+// newOpenAPI 根据接口定义生成OpenAPI 3文档
+func newOpenAPI(routes []apiRoute) map[string]interface{} {
+	b := &schemaBuilder{schemas: map[string]interface{}{}}
+	paths := map[string]interface{}{}
+	for _, route := range routes {
+		segments := strings.Split(route.Path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				segments[i] = "{" + segment[1:] + "}"
+			}
+		}
+		path := strings.Join(segments, "/")
+		if _, ok := paths[path]; !ok {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path].(map[string]interface{})[strings.ToLower(route.Method)] = b.operation(route)
+	}
+
+	b.schemas["FieldError"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"field":   map[string]interface{}{"type": "string", "description": "字段在请求体中的路径"},
+			"message": map[string]interface{}{"type": "string"},
+		},
+	}
+	errorSchema := b.response(nil)
+	errorSchema["properties"].(map[string]interface{})["data"] = map[string]interface{}{
+		"type":        "object",
+		"nullable":    true,
+		"description": "请求体校验失败时包含每个字段的错误",
+		"properties": map[string]interface{}{
+			"errors": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/FieldError"}},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "ldap-http-service",
+			"description": "通过HTTP管理AD域中的用户及群组，请求头中的trace_id会在响应中原样返回",
+			"version":     "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": b.schemas,
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "请求失败，constants为业务错误码",
+					"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": errorSchema}},
+				},
+			},
+		},
+	}
+}
+
+func handleOpenAPI(c *gin.Context) {
+	openAPIOnce.Do(func() {
+		openAPIDoc = newOpenAPI(apiRoutes())
+	})
+	c.JSON(http.StatusOK, openAPIDoc)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/utils"
+	"strconv"
+	"strings"
+)
+
+// 使用JSON Patch风格操作列表时的Content-Type，其他类型均按JSON Merge Patch处理
+const jsonPatchContentType = "application/json-patch+json"
+
+// patchOp JSON Patch风格的单个操作，path为 "/属性名"
+type patchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// objPatch 解析后的对象变更，OU单独记录，由调用方在属性变更后移动对象
+type objPatch struct {
+	Changes []ldap.AttrChange
+	OU      string
+}
+
+// parsePatch 解析PATCH请求体，allowed为允许变更的属性，required为不允许清空的属性
+func parsePatch(c *gin.Context, allowed, required []string) (*objPatch, error) {
+	if strings.HasPrefix(c.ContentType(), jsonPatchContentType) {
+		var ops []patchOp
+		if err := c.ShouldBindJSON(&ops); err != nil {
+			return nil, &ers.InvalidJsonErr{}
+		}
+		return parseJsonPatch(ops, allowed, required)
+	}
+
+	var merge map[string]interface{}
+	if err := c.ShouldBindJSON(&merge); err != nil {
+		return nil, &ers.InvalidJsonErr{}
+	}
+	return parseMergePatch(merge, allowed, required)
+}
+
+// parseMergePatch 按JSON Merge Patch语义解析：null或空值清空属性，其他值替换属性
+func parseMergePatch(merge map[string]interface{}, allowed, required []string) (*objPatch, error) {
+	patch := &objPatch{}
+	for attr, value := range merge {
+		if attr == "OU" {
+			ou, ok := value.(string)
+			if !ok || ou == "" {
+				return nil, &ers.InvalidPatchErr{Message: "OU must be a non-empty string"}
+			}
+			patch.OU = ou
+			continue
+		}
+		if !utils.InSlice(attr, allowed) {
+			return nil, &ers.UnSupportedErr{Object: attr, ObjectType: "attribute"}
+		}
+
+		values, err := patchValues(attr, value)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 && utils.InSlice(attr, required) {
+			return nil, &ers.InvalidPatchErr{Message: fmt.Sprintf("attribute '%s' cannot be cleared", attr)}
+		}
+		patch.Changes = append(patch.Changes, ldap.AttrChange{Op: ldap.OpReplace, Attribute: attr, Values: values})
+	}
+	return patch, nil
+}
+
+// parseJsonPatch 按操作列表解析，add/remove/replace分别对应属性值的增加、删除和替换
+func parseJsonPatch(ops []patchOp, allowed, required []string) (*objPatch, error) {
+	patch := &objPatch{}
+	for _, op := range ops {
+		attr := strings.TrimPrefix(op.Path, "/")
+		if attr == "OU" {
+			ou, ok := op.Value.(string)
+			if op.Op != ldap.OpReplace || !ok || ou == "" {
+				return nil, &ers.InvalidPatchErr{Message: "OU only supports replace with a non-empty string"}
+			}
+			patch.OU = ou
+			continue
+		}
+		if !utils.InSlice(attr, allowed) {
+			return nil, &ers.UnSupportedErr{Object: attr, ObjectType: "attribute"}
+		}
+
+		values, err := patchValues(attr, op.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case ldap.OpAdd:
+			if len(values) == 0 {
+				return nil, &ers.InvalidPatchErr{Message: fmt.Sprintf("add to '%s' requires a value", attr)}
+			}
+		case ldap.OpRemove, ldap.OpReplace:
+			if len(values) == 0 && utils.InSlice(attr, required) {
+				return nil, &ers.InvalidPatchErr{Message: fmt.Sprintf("attribute '%s' cannot be cleared", attr)}
+			}
+		default:
+			return nil, &ers.UnSupportedErr{Object: op.Op, ObjectType: "patch operation"}
+		}
+		patch.Changes = append(patch.Changes, ldap.AttrChange{Op: op.Op, Attribute: attr, Values: values})
+	}
+	return patch, nil
+}
+
+// checkChanges 按PATCH接口的规则校验直接提交的属性变更，例如批量任务中的update_user
+func checkChanges(changes []ldap.AttrChange, allowed, required []string) error {
+	for _, change := range changes {
+		if !utils.InSlice(change.Attribute, allowed) {
+			return &ers.UnSupportedErr{Object: change.Attribute, ObjectType: "attribute"}
+		}
+		switch change.Op {
+		case ldap.OpAdd:
+			if len(change.Values) == 0 {
+				return &ers.InvalidPatchErr{Message: fmt.Sprintf("add to '%s' requires a value", change.Attribute)}
+			}
+		case ldap.OpRemove, ldap.OpReplace:
+			if len(change.Values) == 0 && utils.InSlice(change.Attribute, required) {
+				return &ers.InvalidPatchErr{Message: fmt.Sprintf("attribute '%s' cannot be cleared", change.Attribute)}
+			}
+		default:
+			return &ers.UnSupportedErr{Object: change.Op, ObjectType: "patch operation"}
+		}
+	}
+	return nil
+}
+
+// patchValues 将json值转换为LDAP属性值列表，null和空字符串返回空列表
+func patchValues(attr string, value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return []string{v}, nil
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case bool:
+		return []string{strings.ToUpper(strconv.FormatBool(v))}, nil
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, &ers.InvalidPatchErr{Message: fmt.Sprintf("values of '%s' must be strings", attr)}
+			}
+			values = append(values, str)
+		}
+		return values, nil
+	default:
+		raw, _ := json.Marshal(v)
+		return nil, &ers.InvalidPatchErr{Message: fmt.Sprintf("unsupported value for '%s': %s", attr, raw)}
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"ldap-http-service/core/bulk"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/core/webhook"
+	"ldap-http-service/lib/ers"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// sAMAccountName中不允许出现的字符
+const invalidNameChars = "\"/\\[]:;|=,+*?<>@"
+
+// createUserRequest 创建启用用户的请求体
+type createUserRequest struct {
+	SAMAccountName string `json:"sAMAccountName" binding:"required_unless=AutoName true,omitempty,max=20,samaccountname" description:"用户登录名，auto_name为true时忽略"`
+	AutoName       bool   `json:"auto_name" description:"是否根据givenName和sn按用户名模板自动生成未被占用的用户名"`
+	GivenName      string `json:"givenName" binding:"required_if=AutoName true" description:"名，写入用户的givenName，同时用于自动生成用户名，支持中文"`
+	Sn             string `json:"sn" binding:"required_if=AutoName true" description:"姓，写入用户的sn，同时用于自动生成用户名，支持中文"`
+	DisplayName    string `json:"displayName" description:"显示名称"`
+	OU             string `json:"OU" binding:"required,dn" description:"用户所在OU的DN"`
+	Password       string `json:"password" binding:"required" description:"初始密码，需要满足域的密码策略"`
+	PrimaryDomain  string `json:"primaryDomain" binding:"required" description:"用户主域名，需要是服务支持的域"`
+}
+
+// createComputerRequest 预创建计算机账户的请求体，sAMAccountName为计算机名加$
+type createComputerRequest struct {
+	Name        string `json:"name" binding:"required,max=15,samaccountname" description:"计算机名，即NetBIOS名称"`
+	OU          string `json:"OU" binding:"required,dn" description:"计算机所在OU的DN"`
+	DNSHostName string `json:"dNSHostName" description:"计算机的完整DNS名称"`
+	Description string `json:"description" description:"描述"`
+}
+
+// createContactRequest 创建邮件联系人的请求体，name为联系人的CN
+type createContactRequest struct {
+	Name         string `json:"name" binding:"required,max=64,samaccountname" description:"联系人名称，即CN"`
+	OU           string `json:"OU" binding:"required,dn" description:"联系人所在OU的DN"`
+	Mail         string `json:"mail" binding:"required,email" description:"外部邮件地址，同时作为targetAddress及主SMTP地址"`
+	DisplayName  string `json:"displayName" description:"显示名称，即GAL中展示的名称"`
+	GivenName    string `json:"givenName" description:"名"`
+	Sn           string `json:"sn" description:"姓"`
+	Company      string `json:"company" description:"公司"`
+	Description  string `json:"description" description:"描述"`
+	MailNickname string `json:"mailNickname" description:"邮件别名"`
+}
+
+// setPasswordRequest 设置用户密码的请求体
+type setPasswordRequest struct {
+	Password string `json:"password" binding:"required" description:"新密码，需要满足域的密码策略"`
+}
+
+// proxyAddressRequest 添加SMTP地址的请求体，地址的域名需要是服务支持的域
+type proxyAddressRequest struct {
+	Address string `json:"address" binding:"required,email" description:"SMTP地址，不含smtp:前缀"`
+	Primary bool   `json:"primary" description:"是否设置为主SMTP地址并同步到mail，原主地址保留为别名"`
+}
+
+// primarySMTPAddressRequest 设置主SMTP地址的请求体
+type primarySMTPAddressRequest struct {
+	Address string `json:"address" binding:"required,email" description:"新的主SMTP地址，不含SMTP:前缀，同步到mail"`
+}
+
+// mailboxQuotaRequest 设置邮箱配额的请求体，单位为KB
+type mailboxQuotaRequest struct {
+	UseDefaults        bool  `json:"use_defaults" description:"是否使用邮箱数据库的默认配额，为true时忽略其余字段"`
+	StorageQuota       int64 `json:"storage_quota" binding:"required_unless=UseDefaults true,omitempty,min=1" description:"发出警告的阈值"`
+	OverQuotaLimit     int64 `json:"over_quota_limit" binding:"required_unless=UseDefaults true,omitempty,min=1" description:"禁止发送的阈值，不能小于storage_quota"`
+	OverHardQuotaLimit int64 `json:"over_hard_quota_limit" binding:"required_unless=UseDefaults true,omitempty,min=1" description:"禁止发送和接收的阈值，不能小于over_quota_limit"`
+}
+
+// lookupRequest 批量获取用户或群组的请求体
+type lookupRequest struct {
+	IDs    []string `json:"ids" binding:"required,min=1,max=1000,dive,required" description:"需要获取的对象标识"`
+	IDType string   `json:"id_type" binding:"required" description:"标识对应的LDAP属性，例如sAMAccountName、mail、objectGUID"`
+}
+
+// createGroupRequest 创建群组的请求体，groupType为AD群组类型，负数为安全组
+type createGroupRequest struct {
+	DisplayName    string `json:"displayName" description:"显示名称"`
+	OU             string `json:"OU" binding:"required,dn" description:"群组所在OU的DN"`
+	SAMAccountName string `json:"sAMAccountName" binding:"required,max=64,samaccountname" description:"群组名"`
+	Description    string `json:"description" description:"描述"`
+	GroupType      int    `json:"groupType" binding:"required,oneof=2 4 8 -2147483646 -2147483644 -2147483640" description:"群组类型：2/4/8为全局/本地/通用通讯组，-2147483646/-2147483644/-2147483640为全局/本地/通用安全组"`
+}
+
+// groupMemberRequest 更新群组成员的请求体，成员标识的类型由member_id_type参数指定，
+// 成员标识可以使用 user:、contact:、group: 前缀指定成员类型，未指定时按objectClass自动识别
+type groupMemberRequest struct {
+	AddMembers         []string         `json:"add_members" binding:"required_without_all=AddExpiringMembers RemoveMembers" description:"需要添加的成员，可以使用user:、contact:、group:前缀指定成员类型，会形成循环嵌套的群组不会被添加"`
+	AddExpiringMembers []expiringMember `json:"add_expiring_members" binding:"omitempty,dive" description:"需要限时添加的成员，到期后自动移除"`
+	RemoveMembers      []string         `json:"remove_members" binding:"required_without_all=AddMembers AddExpiringMembers" description:"需要移除的成员，可以使用user:、contact:、group:前缀指定成员类型"`
+}
+
+// expiringMember 限时群组成员，林中启用了特权访问管理功能时由域控制器到期移除，否则由服务到期移除
+type expiringMember struct {
+	Member    string    `json:"member" binding:"required" description:"成员标识，可以使用user:、contact:、group:前缀指定成员类型"`
+	ExpiresAt time.Time `json:"expires_at" binding:"required" description:"到期时间，必须晚于当前时间"`
+}
+
+// managerRequest 设置用户经理的请求体，经理标识的类型由manager_id_type参数指定
+type managerRequest struct {
+	Manager string `json:"manager" binding:"required" description:"经理的用户标识"`
+}
+
+// groupOwnerRequest 设置群组所有者的请求体，所有者标识的类型由owner_id_type参数指定
+type groupOwnerRequest struct {
+	Owner string `json:"owner" binding:"required" description:"群组所有者，可以使用user:、group:前缀指定类型"`
+}
+
+// groupCoManagersRequest 设置群组共同管理者的请求体，管理者标识的类型由manager_id_type参数指定
+type groupCoManagersRequest struct {
+	CoManagers []string `json:"co_managers" binding:"required,min=1,dive,required" description:"共同管理者，整体替换现有的共同管理者"`
+}
+
+// bulkJobRequest 提交批量任务的请求体
+type bulkJobRequest struct {
+	Operations []bulk.Operation `json:"operations" binding:"required,min=1" description:"按顺序执行的操作列表"`
+}
+
+// webhookRequest 创建webhook订阅的请求体
+type webhookRequest struct {
+	URL        string   `json:"url" binding:"required,http_url" description:"接收事件的http(s)地址"`
+	Secret     string   `json:"secret" description:"签名密钥，未指定时自动生成"`
+	EventTypes []string `json:"event_types" description:"订阅的事件类型，为空或包含 \"*\" 时订阅全部事件"`
+	OUs        []string `json:"ous" binding:"dive,dn" description:"只订阅这些OU及其子OU中对象的事件，为空时不过滤"`
+}
+
+func init() {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// 校验错误中使用json字段名，与请求体保持一致
+	engine.RegisterTagNameFunc(jsonFieldName)
+	_ = engine.RegisterValidation("dn", func(fl validator.FieldLevel) bool {
+		return ldap.ValidDN(fl.Field().String())
+	})
+	_ = engine.RegisterValidation("samaccountname", func(fl validator.FieldLevel) bool {
+		return !strings.ContainsAny(fl.Field().String(), invalidNameChars)
+	})
+}
+
+// jsonFieldName 返回结构体字段在json中的名称，不参与序列化的字段返回空字符串
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// bindRequest 解析并校验json请求体，字段不合法时返回包含字段详情的ValidationErr
+func bindRequest(c *gin.Context, obj interface{}) error {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]ers.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			// Namespace的第一段为结构体名称，去除后即为字段在请求体中的路径
+			field := fe.Namespace()
+			if i := strings.Index(field, "."); i >= 0 {
+				field = field[i+1:]
+			}
+			fields = append(fields, ers.FieldError{Field: field, Message: validationMessage(obj, fe)})
+		}
+		return &ers.ValidationErr{Fields: fields}
+	case errors.As(err, &typeErr):
+		field := ers.FieldError{Field: typeErr.Field, Message: fmt.Sprintf("must be of type %s", jsonType(typeErr.Type))}
+		return &ers.ValidationErr{Fields: []ers.FieldError{field}}
+	}
+	return &ers.InvalidJsonErr{}
+}
+
+// validationMessage 将校验规则转换为可读的错误信息，obj为被校验的请求体
+func validationMessage(obj interface{}, fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required when %s is empty", jsonFieldOf(obj, fe.Param()))
+	case "required_if":
+		name, value, _ := strings.Cut(fe.Param(), " ")
+		return fmt.Sprintf("is required when %s is %s", jsonFieldOf(obj, name), value)
+	case "required_unless":
+		name, value, _ := strings.Cut(fe.Param(), " ")
+		return fmt.Sprintf("is required unless %s is %s", jsonFieldOf(obj, name), value)
+	case "required_without_all":
+		var fields []string
+		for _, name := range strings.Fields(fe.Param()) {
+			fields = append(fields, jsonFieldOf(obj, name))
+		}
+		return fmt.Sprintf("is required when %s are empty", strings.Join(fields, " and "))
+	case "max":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at most %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "http_url":
+		return "must be a valid http(s) url"
+	case "email":
+		return "must be a valid email address"
+	case "dn":
+		return "must be a valid distinguished name"
+	case "samaccountname":
+		return fmt.Sprintf("must not contain any of %s", invalidNameChars)
+	}
+	return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+}
+
+// jsonFieldOf 将校验规则参数中的结构体字段名转换为请求体中的json字段名
+func jsonFieldOf(obj interface{}, name string) string {
+	objType := reflect.TypeOf(obj)
+	for objType != nil && objType.Kind() == reflect.Ptr {
+		objType = objType.Elem()
+	}
+	if objType != nil && objType.Kind() == reflect.Struct {
+		if field, ok := objType.FieldByName(name); ok {
+			return jsonFieldName(field)
+		}
+	}
+	return name
+}
+
+// nameAttrs 在创建用户时一并写入的名和姓
+func (r *createUserRequest) nameAttrs() map[string]string {
+	return map[string]string{"givenName": r.GivenName, "sn": r.Sn}
+}
+
+// contact 转换为创建联系人的参数
+func (r *createContactRequest) contact() ldap.NewContact {
+	return ldap.NewContact{
+		Name:         r.Name,
+		OU:           r.OU,
+		DisplayName:  r.DisplayName,
+		GivenName:    r.GivenName,
+		Sn:           r.Sn,
+		Company:      r.Company,
+		Description:  r.Description,
+		Mail:         r.Mail,
+		MailNickname: r.MailNickname,
+	}
+}
+
+// subscription 转换为webhook订阅
+func (r *webhookRequest) subscription() webhook.Subscription {
+	return webhook.Subscription{URL: r.URL, Secret: r.Secret, EventTypes: r.EventTypes, OUs: r.OUs}
+}
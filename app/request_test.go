@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBindRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name string
+		obj  interface{}
+		body string
+		// want 为nil时期望校验通过
+		want []ers.FieldError
+	}{
+		{
+			name: "valid group",
+			obj:  &createGroupRequest{},
+			body: `{"OU":"OU=Groups,DC=example,DC=com","sAMAccountName":"sales","groupType":2}`,
+		},
+		{
+			name: "missing fields use json names",
+			obj:  &createGroupRequest{},
+			body: `{}`,
+			want: []ers.FieldError{
+				{Field: "OU", Message: "is required"},
+				{Field: "sAMAccountName", Message: "is required"},
+				{Field: "groupType", Message: "is required"},
+			},
+		},
+		{
+			name: "custom rules",
+			obj:  &createGroupRequest{},
+			body: `{"OU":"not a dn","sAMAccountName":"a,b","groupType":3}`,
+			want: []ers.FieldError{
+				{Field: "OU", Message: "must be a valid distinguished name"},
+				{Field: "sAMAccountName", Message: "must not contain any of " + invalidNameChars},
+				{Field: "groupType", Message: "must be one of [2, 4, 8, -2147483646, -2147483644, -2147483640]"},
+			},
+		},
+		{
+			name: "conditional rules name the other field",
+			obj:  &createUserRequest{},
+			body: `{"auto_name":true,"OU":"OU=Users,DC=example,DC=com","password":"x","primaryDomain":"example.com"}`,
+			want: []ers.FieldError{
+				{Field: "givenName", Message: "is required when auto_name is true"},
+				{Field: "sn", Message: "is required when auto_name is true"},
+			},
+		},
+		{
+			name: "required unless",
+			obj:  &mailboxQuotaRequest{},
+			body: `{"storage_quota":100}`,
+			want: []ers.FieldError{
+				{Field: "over_quota_limit", Message: "is required unless use_defaults is true"},
+				{Field: "over_hard_quota_limit", Message: "is required unless use_defaults is true"},
+			},
+		},
+		{
+			name: "required without all",
+			obj:  &groupMemberRequest{},
+			body: `{}`,
+			want: []ers.FieldError{
+				{Field: "add_members", Message: "is required when add_expiring_members and remove_members are empty"},
+				{Field: "remove_members", Message: "is required when add_members and add_expiring_members are empty"},
+			},
+		},
+		{
+			name: "slice length and nested path",
+			obj:  &lookupRequest{},
+			body: `{"ids":["a",""],"id_type":"mail"}`,
+			want: []ers.FieldError{{Field: "ids[1]", Message: "is required"}},
+		},
+		{
+			name: "empty slice",
+			obj:  &groupCoManagersRequest{},
+			body: `{"co_managers":[]}`,
+			want: []ers.FieldError{{Field: "co_managers", Message: "must contain at least 1 items"}},
+		},
+		{
+			name: "string length",
+			obj:  &createComputerRequest{},
+			body: `{"name":"abcdefghijklmnop","OU":"OU=Computers,DC=example,DC=com"}`,
+			want: []ers.FieldError{{Field: "name", Message: "must be at most 15 characters"}},
+		},
+		{
+			name: "url and nested dn",
+			obj:  &webhookRequest{},
+			body: `{"url":"ftp://example.com","ous":["bad"]}`,
+			want: []ers.FieldError{
+				{Field: "url", Message: "must be a valid http(s) url"},
+				{Field: "ous[0]", Message: "must be a valid distinguished name"},
+			},
+		},
+		{
+			name: "wrong json type",
+			obj:  &createGroupRequest{},
+			body: `{"groupType":"2"}`,
+			want: []ers.FieldError{{Field: "groupType", Message: "must be of type integer"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			err := bindRequest(c, tt.obj)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("bindRequest() error = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *ers.ValidationErr
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("bindRequest() error = %v, want *ers.ValidationErr", err)
+			}
+			if !reflect.DeepEqual(validationErr.Fields, tt.want) {
+				t.Errorf("bindRequest() fields = %+v, want %+v", validationErr.Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindRequestInvalidJson(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"OU":`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var invalidJsonErr *ers.InvalidJsonErr
+	if err := bindRequest(c, &createGroupRequest{}); !errors.As(err, &invalidJsonErr) {
+		t.Errorf("bindRequest() error = %v, want *ers.InvalidJsonErr", err)
+	}
+}
+
+func TestCreateContactRequest(t *testing.T) {
+	body := `{"name":"Bob Partner","OU":"OU=Contacts,DC=example,DC=com","mail":"bob@partner.com","displayName":"Bob (Partner)","company":"Partner"}`
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var req createContactRequest
+	if err := bindRequest(c, &req); err != nil {
+		t.Fatalf("bindRequest() error = %v", err)
+	}
+	want := ldap.NewContact{Name: "Bob Partner", OU: "OU=Contacts,DC=example,DC=com", Mail: "bob@partner.com", DisplayName: "Bob (Partner)", Company: "Partner"}
+	if got := req.contact(); !reflect.DeepEqual(got, want) {
+		t.Errorf("contact() = %+v, want %+v", got, want)
+	}
+
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"a;b","OU":"OU=Contacts,DC=example,DC=com","mail":"bob"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	var validationErr *ers.ValidationErr
+	if err := bindRequest(c, &createContactRequest{}); !errors.As(err, &validationErr) {
+		t.Fatalf("bindRequest() error = %v, want *ers.ValidationErr", err)
+	}
+	wantFields := []ers.FieldError{
+		{Field: "name", Message: "must not contain any of " + invalidNameChars},
+		{Field: "mail", Message: "must be a valid email address"},
+	}
+	if !reflect.DeepEqual(validationErr.Fields, wantFields) {
+		t.Errorf("bindRequest() fields = %+v, want %+v", validationErr.Fields, wantFields)
+	}
+}
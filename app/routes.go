@@ -0,0 +1,292 @@
+package main
+
+import (
+	"ldap-http-service/core/bulk"
+	"ldap-http-service/core/changes"
+	"ldap-http-service/core/expiry"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/core/transfer"
+	"ldap-http-service/core/webhook"
+	"net/http"
+)
+
+// 常用的查询参数
+var (
+	searchBaseParam = apiParam{Name: "search_base", Description: "搜索的起始DN，未指定时使用BaseDN"}
+	attributesParam = apiParam{Name: "attributes", Description: "需要获取的属性，多个属性以逗号分隔，未指定时获取全部属性"}
+	userQuery       = []apiParam{{Name: "user_id_type", Required: true, Description: "user_id对应的LDAP属性，例如sAMAccountName、objectGUID"}, searchBaseParam}
+	computerQuery   = []apiParam{{Name: "computer_id_type", Required: true, Description: "computer_id对应的LDAP属性，例如sAMAccountName（可省略结尾的$）、objectGUID"}, searchBaseParam}
+	contactQuery    = []apiParam{{Name: "contact_id_type", Required: true, Description: "contact_id对应的LDAP属性，例如mail、objectGUID"}, searchBaseParam}
+	groupQuery      = []apiParam{{Name: "group_id_type", Required: true, Description: "group_id对应的LDAP属性，例如sAMAccountName、objectGUID"}, searchBaseParam}
+	filterParam     = apiParam{Name: "filter", Description: "附加的LDAP过滤条件"}
+	typeParam       = apiParam{Name: "type", Description: "对象类型，默认为user", Enum: []string{transfer.TypeUser, transfer.TypeGroup}}
+	exportTypeParam = apiParam{Name: "type", Description: "对象类型，默认为user", Enum: []string{transfer.TypeUser, transfer.TypeGroup, transfer.TypeComputer}}
+	cursorParam     = apiParam{Name: "since", Description: "上一次返回的游标"}
+)
+
+// patchBody PATCH接口的请求体，按Content-Type区分JSON Merge Patch与JSON Patch风格的操作列表
+func patchBody(allowed []string, withOU bool) rawContent {
+	properties := map[string]interface{}{}
+	for _, attr := range allowed {
+		properties[attr] = map[string]interface{}{
+			"nullable": true,
+			"oneOf":    []interface{}{map[string]interface{}{"type": "string"}, map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}},
+		}
+	}
+	paths := make([]interface{}, 0, len(allowed)+1)
+	for _, attr := range allowed {
+		paths = append(paths, "/"+attr)
+	}
+	if withOU {
+		properties["OU"] = map[string]interface{}{"type": "string", "description": "移动到的OU的DN，在属性变更后执行；仅移动OU时If-Match只在移动前校验，不能保证与移动之间的原子性"}
+		paths = append(paths, "/OU")
+	}
+
+	return rawContent{
+		"application/json": map[string]interface{}{
+			"type":                 "object",
+			"description":          "null或空值清空属性，其他值替换属性",
+			"properties":           properties,
+			"additionalProperties": false,
+		},
+		jsonPatchContentType: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":     "object",
+				"required": []string{"op", "path"},
+				"properties": map[string]interface{}{
+					"op":    map[string]interface{}{"type": "string", "enum": []string{ldap.OpAdd, ldap.OpRemove, ldap.OpReplace}},
+					"path":  map[string]interface{}{"type": "string", "enum": paths},
+					"value": map[string]interface{}{},
+				},
+			},
+		},
+	}
+}
+
+// apiRoutes 返回全部/ldap接口，SCIM接口遵循RFC 7644并通过/scim/v2/Schemas自描述，不在此列出
+func apiRoutes() []apiRoute {
+	return []apiRoute{
+		{Method: http.MethodGet, Path: "/ldap/openapi.json", Handler: handleOpenAPI, Summary: "获取OpenAPI文档", Produces: "application/json"},
+		{Method: http.MethodGet, Path: "/ldap/healthz", Handler: handleHealthz, Summary: "健康检查"},
+		{
+			Method: http.MethodGet, Path: "/ldap/availability", Handler: handleCheckAvailability, Summary: "检查用户名可用性，已被占用时返回409及占用的对象",
+			Params: []apiParam{{Name: "name", Required: true, Description: "需要检查的sAMAccountName"}},
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/availability/suggest", Handler: handleSuggestNames, Summary: "根据姓名按用户名模板生成候选用户名，返回前count个未被占用的用户名，中文姓名转换为拼音",
+			Params: []apiParam{
+				{Name: "given", Required: true, Description: "名"},
+				{Name: "surname", Required: true, Description: "姓"},
+				{Name: "count", Description: "返回的用户名数量，默认为5，最大为50"},
+			},
+			Data: map[string]interface{}{"suggestions": []string{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/user/:user_id", Handler: handleGetUser, Summary: "获取用户信息，ETag为对象版本",
+			Params: append(append([]apiParam{}, userQuery...), attributesParam), Data: map[string]interface{}{"user": ldap.User{}},
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/user", Handler: handleNewEnableUser, Summary: "创建启用的用户",
+			Body: createUserRequest{}, Data: map[string]interface{}{"user": ldap.User{}},
+		},
+		{
+			Method: http.MethodPatch, Path: "/ldap/user/:user_id", Handler: handleUserUpdate, Summary: "更新用户属性，可通过If-Match指定对象版本",
+			Params: append(append([]apiParam{}, userQuery...), attributesParam), Body: patchBody(userPatchAttrs, true), Data: map[string]interface{}{"user": ldap.User{}},
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/user/:user_id/password", Handler: handleUserPwd, Summary: "设置用户密码并解锁账户",
+			Params: userQuery, Body: setPasswordRequest{},
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/user/:user_id/proxy-addresses", Handler: handleAddProxyAddress, Summary: "添加用户的SMTP别名或主地址，地址需要属于服务支持的域且未被其他对象使用，可通过If-Match指定对象版本",
+			Params: userQuery, Body: proxyAddressRequest{}, Data: map[string]interface{}{"user": ldap.User{}},
+		},
+		{
+			Method: http.MethodDelete, Path: "/ldap/user/:user_id/proxy-addresses/:address", Handler: handleRemoveProxyAddress, Summary: "移除用户的SMTP别名，不允许移除主SMTP地址，可通过If-Match指定对象版本",
+			Params: userQuery, Data: map[string]interface{}{"user": ldap.User{}},
+		},
+		{
+			Method: http.MethodPut, Path: "/ldap/user/:user_id/primary-smtp-address", Handler: handleSetPrimarySMTPAddress, Summary: "设置用户的主SMTP地址并同步mail，原主地址保留为别名，可通过If-Match指定对象版本",
+			Params: userQuery, Body: primarySMTPAddressRequest{}, Data: map[string]interface{}{"user": ldap.User{}},
+		},
+		{
+			Method: http.MethodPut, Path: "/ldap/user/:user_id/mailbox-quota", Handler: handleSetMailboxQuota, Summary: "设置用户的邮箱配额，可通过If-Match指定对象版本",
+			Params: userQuery, Body: mailboxQuotaRequest{}, Data: map[string]interface{}{"user": ldap.User{}},
+		},
+		{
+			Method: http.MethodPut, Path: "/ldap/user/:user_id/manager", Handler: handleSetManager, Summary: "设置用户的经理，经理需要是用户且不能形成循环的汇报关系，可通过If-Match指定对象版本",
+			Params: append(append([]apiParam{}, userQuery...), apiParam{Name: "manager_id_type", Required: true, Description: "经理标识对应的LDAP属性"}),
+			Body:   managerRequest{}, Data: map[string]interface{}{"user": ldap.User{}},
+		},
+		{
+			Method: http.MethodDelete, Path: "/ldap/user/:user_id/manager", Handler: handleClearManager, Summary: "清除用户的经理，可通过If-Match指定对象版本",
+			Params: userQuery, Data: map[string]interface{}{"user": ldap.User{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/user/:user_id/orgchart", Handler: handleGetOrgChart, Summary: "获取用户的汇报关系，向上沿经理链、向下沿直接下属各遍历depth层，遇到循环的汇报关系时停止遍历",
+			Params: append(append([]apiParam{}, userQuery...), apiParam{Name: "depth", Description: "向上及向下遍历的层数，默认为1，最大为10"}),
+			Data:   map[string]interface{}{"orgchart": ldap.OrgChart{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/user/:user_id/photo", Handler: handleGetUserPhoto, Summary: "获取用户的照片（thumbnailPhoto），ETag为对象版本",
+			Params: userQuery, Produces: "image/jpeg",
+		},
+		{
+			Method: http.MethodPut, Path: "/ldap/user/:user_id/photo", Handler: handleSetUserPhoto, Summary: "上传用户的照片，支持JPEG、PNG、GIF，超过大小或尺寸限制时缩小并压缩为JPEG，可通过If-Match指定对象版本",
+			Params: userQuery,
+			Body: rawContent{
+				"image/*": map[string]interface{}{"type": "string", "format": "binary"},
+				"multipart/form-data": map[string]interface{}{
+					"type": "object", "required": []string{"photo"},
+					"properties": map[string]interface{}{"photo": map[string]interface{}{"type": "string", "format": "binary"}},
+				},
+			},
+			Data: map[string]interface{}{"photo": map[string]interface{}{}},
+		},
+		{
+			Method: http.MethodDelete, Path: "/ldap/user/:user_id/photo", Handler: handleDeleteUserPhoto, Summary: "删除用户的照片，可通过If-Match指定对象版本",
+			Params: userQuery, Data: map[string]interface{}{"photo": map[string]interface{}{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/search/people", Handler: handleSearchPeople, Summary: "人员选择器搜索，通过ANR按名称、登录名、邮件匹配用户、群组及联系人，按匹配程度排序，结果短时间缓存",
+			Params: []apiParam{
+				{Name: "q", Required: true, Description: "搜索词，不能少于服务端配置的最小字符数（默认为2）"},
+				{Name: "limit", Description: "返回的结果数量，默认为10，不能超过服务端的数量上限"},
+			},
+			Data: map[string]interface{}{"people": []ldap.Person{}},
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/users/lookup", Handler: handleLookupUsers, Summary: "按标识批量获取用户，users为标识到用户的映射，未找到的标识记录在not_found中",
+			Params: []apiParam{searchBaseParam, attributesParam}, Body: lookupRequest{},
+			Data: map[string]interface{}{"users": map[string]ldap.User{}, "not_found": []string{}},
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/groups/lookup", Handler: handleLookupGroups, Summary: "按标识批量获取群组，groups为标识到群组的映射，未找到的标识记录在not_found中",
+			Params: []apiParam{searchBaseParam, attributesParam}, Body: lookupRequest{},
+			Data: map[string]interface{}{"groups": map[string]ldap.Group{}, "not_found": []string{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/group/:group_id", Handler: handleGetGroup, Summary: "获取群组信息，ETag为对象版本",
+			Params: append(append([]apiParam{}, groupQuery...), attributesParam), Data: map[string]interface{}{"group": ldap.Group{}},
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/group", Handler: handleNewGroup, Summary: "创建群组",
+			Body: createGroupRequest{}, Data: map[string]interface{}{"group": ldap.Group{}},
+		},
+		{
+			Method: http.MethodPatch, Path: "/ldap/group/:group_id", Handler: handleGroupUpdate, Summary: "更新群组属性，可通过If-Match指定对象版本",
+			Params: groupQuery, Body: patchBody(groupPatchAttrs, false), Data: map[string]interface{}{"group": ldap.Group{}},
+		},
+		{
+			Method: http.MethodPut, Path: "/ldap/group/:group_id/member", Handler: handleGroupMemberUpdate, Summary: "添加、移除群组成员，失败的成员记录在message中；限时成员到期后自动移除，expirations为由服务记录、尚未到期的成员；携带Basic认证时以owner模式执行，只允许群组的所有者或共同管理者（包括作为所有者的群组的成员）调用；配置OWNER_REQUIREAUTH后必须以owner模式调用",
+			Params: append(append([]apiParam{}, groupQuery...), apiParam{Name: "member_id_type", Required: true, Description: "成员标识对应的LDAP属性，成员可以是用户、联系人或群组"}),
+			Body:   groupMemberRequest{}, Data: map[string]interface{}{"group": ldap.Group{}, "expirations": []expiry.Expiration{}},
+			OwnerMode: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/member/:member_id", Handler: handleGetMember, Summary: "按与更新群组成员相同的规则解析成员，member_id可带有user:、contact:、group:类型提示",
+			Params: []apiParam{{Name: "member_id_type", Required: true, Description: "成员标识对应的LDAP属性，成员可以是用户、联系人或群组"}, searchBaseParam},
+			Data:   map[string]interface{}{"member": ldap.BaseObject{}},
+		},
+		{
+			Method: http.MethodPut, Path: "/ldap/group/:group_id/owner", Handler: handleSetGroupOwner, Summary: "设置群组所有者，可通过If-Match指定对象版本",
+			Params: append(append([]apiParam{}, groupQuery...), apiParam{Name: "owner_id_type", Required: true, Description: "所有者标识对应的LDAP属性"}),
+			Body:   groupOwnerRequest{}, Data: map[string]interface{}{"group": ldap.Group{}},
+		},
+		{
+			Method: http.MethodDelete, Path: "/ldap/group/:group_id/owner", Handler: handleClearGroupOwner, Summary: "清空群组所有者，可通过If-Match指定对象版本",
+			Params: groupQuery, Data: map[string]interface{}{"group": ldap.Group{}},
+		},
+		{
+			Method: http.MethodPut, Path: "/ldap/group/:group_id/co-managers", Handler: handleSetGroupCoManagers, Summary: "替换群组共同管理者，可通过If-Match指定对象版本",
+			Params: append(append([]apiParam{}, groupQuery...), apiParam{Name: "manager_id_type", Required: true, Description: "管理者标识对应的LDAP属性"}),
+			Body:   groupCoManagersRequest{}, Data: map[string]interface{}{"group": ldap.Group{}},
+		},
+		{
+			Method: http.MethodDelete, Path: "/ldap/group/:group_id/co-managers", Handler: handleClearGroupCoManagers, Summary: "清空群组共同管理者，可通过If-Match指定对象版本",
+			Params: groupQuery, Data: map[string]interface{}{"group": ldap.Group{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/computer/:computer_id", Handler: handleGetComputer, Summary: "获取计算机信息，ETag为对象版本",
+			Params: append(append([]apiParam{}, computerQuery...), attributesParam), Data: map[string]interface{}{"computer": ldap.Computer{}},
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/computer", Handler: handleNewComputer, Summary: "在OU中预创建计算机账户",
+			Body: createComputerRequest{}, Data: map[string]interface{}{"computer": ldap.Computer{}},
+		},
+		{
+			Method: http.MethodPatch, Path: "/ldap/computer/:computer_id", Handler: handleComputerUpdate, Summary: "更新计算机属性或移动OU，可通过If-Match指定对象版本",
+			Params: append(append([]apiParam{}, computerQuery...), attributesParam), Body: patchBody(computerPatchAttrs, true), Data: map[string]interface{}{"computer": ldap.Computer{}},
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/computer/:computer_id/disable", Handler: handleComputerDisable, Summary: "禁用计算机账户，已禁用时不做修改",
+			Params: computerQuery, Data: map[string]interface{}{"computer": ldap.Computer{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/contact/:contact_id", Handler: handleGetContact, Summary: "获取联系人信息，ETag为对象版本",
+			Params: append(append([]apiParam{}, contactQuery...), attributesParam), Data: map[string]interface{}{"contact": ldap.Contact{}},
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/contact", Handler: handleNewContact, Summary: "创建邮件联系人，邮件地址不能被其他对象使用",
+			Body: createContactRequest{}, Data: map[string]interface{}{"contact": ldap.Contact{}},
+		},
+		{
+			Method: http.MethodPatch, Path: "/ldap/contact/:contact_id", Handler: handleContactUpdate, Summary: "更新联系人属性或移动OU，可通过If-Match指定对象版本",
+			Params: append(append([]apiParam{}, contactQuery...), attributesParam), Body: patchBody(contactPatchAttrs, true), Data: map[string]interface{}{"contact": ldap.Contact{}},
+		},
+		{
+			Method: http.MethodDelete, Path: "/ldap/contact/:contact_id", Handler: handleDeleteContact, Summary: "删除联系人，可通过If-Match指定对象版本",
+			Params: contactQuery,
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/bulk", Handler: handleNewBulkJob, Summary: "提交异步执行的批量任务，携带Basic认证时以owner模式执行，只允许提交update_members操作",
+			Body: bulkJobRequest{}, Status: http.StatusAccepted, Data: map[string]interface{}{"job": bulk.Job{}},
+			OwnerMode: true,
+		},
+		{Method: http.MethodGet, Path: "/ldap/jobs/:job_id", Handler: handleGetJob, Summary: "获取批量任务状态", Data: map[string]interface{}{"job": bulk.Job{}}},
+		{Method: http.MethodDelete, Path: "/ldap/jobs/:job_id", Handler: handleCancelJob, Summary: "取消批量任务", Data: map[string]interface{}{"job": bulk.Job{}}},
+		{
+			Method: http.MethodGet, Path: "/ldap/export", Handler: handleExport, Summary: "导出用户、群组或计算机，可通过lastLogonTimestamp过滤长期未登录的计算机",
+			Params: []apiParam{
+				{Name: "format", Description: "导出格式，默认为jsonl", Enum: []string{transfer.FormatJSONL, transfer.FormatCSV, transfer.FormatLDIF}},
+				exportTypeParam, filterParam, searchBaseParam, attributesParam,
+			},
+			Produces: "application/octet-stream",
+		},
+		{
+			Method: http.MethodPost, Path: "/ldap/import", Handler: handleImport, Summary: "导入CSV或LDIF，dry_run为true时只校验不写入",
+			Params: []apiParam{
+				{Name: "format", Description: "导入格式，默认为csv", Enum: []string{transfer.FormatCSV, transfer.FormatLDIF}},
+				typeParam,
+				{Name: "mode", Description: "CSV导入模式，默认为create", Enum: []string{transfer.ModeCreate, transfer.ModeUpdate}},
+				{Name: "key", Description: "update模式下用于匹配对象的属性"},
+				{Name: "mapping", Description: "CSV列名与LDAP属性的映射，格式为 列名:属性,列名:属性"},
+				{Name: "dry_run", Description: "为true时只校验不写入", Enum: []string{"true", "false"}},
+			},
+			Body: rawContent{"text/csv": map[string]interface{}{"type": "string"}, "text/plain": map[string]interface{}{"type": "string", "description": "LDIF"}},
+			Data: map[string]interface{}{"report": transfer.Report{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/changes", Handler: handleChanges, Summary: "增量获取目录变更事件",
+			Params: []apiParam{cursorParam, {Name: "limit", Description: "单次返回的最大事件数，1-1000，默认为100"}},
+			Data:   map[string]interface{}{"events": []changes.Event{}, "cursor": int64(0)},
+		},
+		{
+			Method: http.MethodGet, Path: "/ldap/changes/stream", Handler: handleChangeStream, Summary: "以SSE订阅目录变更事件，断线重连时使用Last-Event-ID继续",
+			Params: []apiParam{cursorParam}, Produces: "text/event-stream",
+		},
+		{Method: http.MethodGet, Path: "/ldap/webhooks", Handler: handleListWebhooks, Summary: "获取webhook订阅列表", Data: map[string]interface{}{"webhooks": []webhook.Subscription{}}},
+		{
+			Method: http.MethodPost, Path: "/ldap/webhooks", Handler: handleNewWebhook, Summary: "创建webhook订阅，返回的密钥仅在创建时返回",
+			Body: webhookRequest{}, Data: map[string]interface{}{"webhook": webhook.Subscription{}},
+		},
+		{Method: http.MethodDelete, Path: "/ldap/webhooks/:webhook_id", Handler: handleDeleteWebhook, Summary: "删除webhook订阅"},
+		{
+			Method: http.MethodGet, Path: "/ldap/webhooks/:webhook_id/dead-deliveries", Handler: handleListDeadDeliveries, Summary: "获取超过最大尝试次数而不再重试的投递，超过保留时间后自动清理",
+			Data: map[string]interface{}{"deliveries": []webhook.Delivery{}},
+		},
+		{Method: http.MethodPost, Path: "/ldap/webhooks/:webhook_id/dead-deliveries/:delivery_id/replay", Handler: handleReplayDelivery, Summary: "重新投递失败的投递"},
+		{Method: http.MethodDelete, Path: "/ldap/webhooks/:webhook_id/dead-deliveries/:delivery_id", Handler: handleDeleteDelivery, Summary: "删除失败的投递"},
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"ldap-http-service/core/scim"
+	"ldap-http-service/lib/logger"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const scimContentType = "application/scim+json; charset=utf-8"
+
+// scimJSON 以SCIM的Content-Type返回json响应
+func scimJSON(c *gin.Context, httpCode int, data interface{}) {
+	c.Header("Content-Type", scimContentType)
+	c.JSON(httpCode, data)
+}
+
+// scimError 以SCIM错误格式返回错误，SCIM接口不经过errorHandler
+func scimError(c *gin.Context, err error) {
+	logger.GinLogger.WithContext(c).Errorf("SCIM请求异常：%v", err)
+	status, response := scim.NewErrorResponse(err)
+	scimJSON(c, status, response)
+}
+
+// scimResource 返回单个资源，并以资源版本设置ETag与Location
+func scimResource(c *gin.Context, httpCode int, meta *scim.Meta, resource interface{}) {
+	if meta.Version != "" {
+		c.Header("ETag", meta.Version)
+	}
+	if httpCode == http.StatusCreated {
+		c.Header("Location", meta.Location)
+	}
+	scimJSON(c, httpCode, resource)
+}
+
+// scimCaller 请求携带Basic认证时以owner模式执行群组成员变更，其他认证方式（例如SCIM客户端的Bearer令牌）不作为owner模式处理
+func scimCaller(c *gin.Context) error {
+	if _, _, ok := c.Request.BasicAuth(); !ok {
+		return nil
+	}
+	_, err := ownerCaller(c)
+	return err
+}
+
+// parseListQuery 解析查询请求中的分页参数及excludedAttributes，未指定count时返回-1
+func parseListQuery(c *gin.Context) (startIndex, count int, excluded []string, err error) {
+	startIndex, count = 1, -1
+	if v := c.Query("startIndex"); v != "" {
+		if startIndex, err = strconv.Atoi(v); err != nil {
+			return 0, 0, nil, &scim.Error{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "startIndex must be an integer"}
+		}
+	}
+	if v := c.Query("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil || count < 0 {
+			return 0, 0, nil, &scim.Error{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "count must be a non-negative integer"}
+		}
+	}
+	for _, attr := range strings.Split(c.Query("excludedAttributes"), ",") {
+		if attr = strings.TrimSpace(attr); attr != "" {
+			excluded = append(excluded, attr)
+		}
+	}
+	return startIndex, count, excluded, nil
+}
+
+// bindScim 解析SCIM请求体
+func bindScim(c *gin.Context, obj interface{}) error {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		return &scim.Error{Status: http.StatusBadRequest, ScimType: "invalidSyntax", Detail: "invalid json body"}
+	}
+	return nil
+}
+
+func handleScimServiceProviderConfig(c *gin.Context) {
+	scimJSON(c, http.StatusOK, scim.GetServiceProviderConfig())
+}
+
+func handleScimResourceTypes(c *gin.Context) {
+	resourceTypes := scim.GetResourceTypes()
+	if id := c.Param("id"); id != "" {
+		for _, resourceType := range resourceTypes {
+			if resourceType.(*scim.ResourceType).ID == id {
+				scimJSON(c, http.StatusOK, resourceType)
+				return
+			}
+		}
+		scimError(c, &scim.Error{Status: http.StatusNotFound, Detail: "resource type '" + id + "' not found"})
+		return
+	}
+	scimJSON(c, http.StatusOK, scim.NewListResponse(resourceTypes))
+}
+
+func handleScimSchemas(c *gin.Context) {
+	if id := c.Param("id"); id != "" {
+		schema, err := scim.GetSchema(id)
+		if err != nil {
+			scimError(c, err)
+			return
+		}
+		scimJSON(c, http.StatusOK, schema)
+		return
+	}
+	scimJSON(c, http.StatusOK, scim.NewListResponse(scim.GetSchemas()))
+}
+
+func handleScimListUsers(c *gin.Context) {
+	startIndex, count, excluded, err := parseListQuery(c)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	response, err := scim.ListUsers(c, c.Query("filter"), startIndex, count, excluded)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	scimJSON(c, http.StatusOK, response)
+}
+
+func handleScimGetUser(c *gin.Context) {
+	_, _, excluded, err := parseListQuery(c)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	user, err := scim.GetUser(c, c.Param("id"), excluded)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	scimResource(c, http.StatusOK, user.Meta, user)
+}
+
+func handleScimCreateUser(c *gin.Context) {
+	var u scim.User
+	if err := bindScim(c, &u); err != nil {
+		scimError(c, err)
+		return
+	}
+	user, err := scim.CreateUser(c, &u)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	scimResource(c, http.StatusCreated, user.Meta, user)
+}
+
+func handleScimReplaceUser(c *gin.Context) {
+	var u scim.User
+	if err := bindScim(c, &u); err != nil {
+		scimError(c, err)
+		return
+	}
+	user, err := scim.ReplaceUser(c, c.Param("id"), ifMatch(c), &u)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	scimResource(c, http.StatusOK, user.Meta, user)
+}
+
+func handleScimPatchUser(c *gin.Context) {
+	var patch scim.PatchOp
+	if err := bindScim(c, &patch); err != nil {
+		scimError(c, err)
+		return
+	}
+	user, err := scim.PatchUser(c, c.Param("id"), ifMatch(c), &patch)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	scimResource(c, http.StatusOK, user.Meta, user)
+}
+
+func handleScimDeleteUser(c *gin.Context) {
+	if err := scim.DeleteUser(c, c.Param("id"), ifMatch(c)); err != nil {
+		scimError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func handleScimListGroups(c *gin.Context) {
+	startIndex, count, excluded, err := parseListQuery(c)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	response, err := scim.ListGroups(c, c.Query("filter"), startIndex, count, excluded)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	scimJSON(c, http.StatusOK, response)
+}
+
+func handleScimGetGroup(c *gin.Context) {
+	_, _, excluded, err := parseListQuery(c)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	group, err := scim.GetGroup(c, c.Param("id"), excluded)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	scimResource(c, http.StatusOK, group.Meta, group)
+}
+
+func handleScimCreateGroup(c *gin.Context) {
+	var g scim.Group
+	if err := bindScim(c, &g); err != nil {
+		scimError(c, err)
+		return
+	}
+	if err := scimCaller(c); err != nil {
+		scimError(c, err)
+		return
+	}
+	group, err := scim.CreateGroup(c, &g)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	scimResource(c, http.StatusCreated, group.Meta, group)
+}
+
+func handleScimReplaceGroup(c *gin.Context) {
+	var g scim.Group
+	if err := bindScim(c, &g); err != nil {
+		scimError(c, err)
+		return
+	}
+	if err := scimCaller(c); err != nil {
+		scimError(c, err)
+		return
+	}
+	group, err := scim.ReplaceGroup(c, c.Param("id"), ifMatch(c), &g)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	scimResource(c, http.StatusOK, group.Meta, group)
+}
+
+func handleScimPatchGroup(c *gin.Context) {
+	var patch scim.PatchOp
+	if err := bindScim(c, &patch); err != nil {
+		scimError(c, err)
+		return
+	}
+	if err := scimCaller(c); err != nil {
+		scimError(c, err)
+		return
+	}
+	group, err := scim.PatchGroup(c, c.Param("id"), ifMatch(c), &patch)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	scimResource(c, http.StatusOK, group.Meta, group)
+}
+
+func handleScimDeleteGroup(c *gin.Context) {
+	if err := scim.DeleteGroup(c, c.Param("id"), ifMatch(c)); err != nil {
+		scimError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
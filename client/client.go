@@ -0,0 +1,305 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"ldap-http-service/constants"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/utils"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client ldap-http-service的HTTP客户端，可以被多个goroutine共享使用
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	retryWait  time.Duration
+}
+
+// Option 客户端配置项
+type Option func(*Client)
+
+// WithHTTPClient 使用自定义的http.Client，例如需要配置TLS或代理时
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry 设置失败后的最大重试次数及首次重试的等待时间，之后每次重试等待时间翻倍
+func WithRetry(maxRetries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryWait = wait
+	}
+}
+
+// New 创建客户端，baseURL为服务地址，例如 http://ldap-http-service:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		retryWait:  500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type idempotencyKey struct{}
+
+type ownerCredentials struct {
+	username string
+	password string
+}
+
+// WithTraceID 在上下文中指定请求的trace_id；与服务端一样使用 "trace_id" 作为key，
+// 因此在gin的handler中直接传入*gin.Context时，会沿用当前请求的trace_id
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, "trace_id", traceID)
+}
+
+// WithIdempotencyKey 为写请求指定幂等键，未指定时每次调用自动生成，重试时使用相同的幂等键
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// WithOwner 以指定用户的身份调用群组成员更新接口（owner模式），服务端只允许群组的所有者或共同管理者更新成员
+func WithOwner(ctx context.Context, username, password string) context.Context {
+	return context.WithValue(ctx, ownerCredentials{}, ownerCredentials{username: username, password: password})
+}
+
+// response 服务端统一的响应格式
+type response struct {
+	Code    int             `json:"constants"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	TraceId string          `json:"trace_id"`
+}
+
+// request 单次调用的请求内容
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	version     string
+	contentType string
+	body        interface{}
+}
+
+// do 发送请求并将响应中的data解析到out中；写请求携带幂等键，因此所有请求都可以安全地重试
+func (c *Client) do(ctx context.Context, req request, out interface{}) (*response, error) {
+	var body []byte
+	if raw, ok := req.body.([]byte); ok {
+		// 二进制请求体原样发送，例如上传照片
+		body = raw
+	} else if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, err
+		}
+		if req.contentType == "" {
+			req.contentType = "application/json"
+		}
+	}
+
+	traceID, _ := ctx.Value("trace_id").(string)
+	if traceID == "" {
+		traceID = utils.GenUuid("req")
+	}
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	if key == "" && req.method != http.MethodGet {
+		key = utils.GenUuid("idem")
+	}
+
+	var (
+		resp *response
+		err  error
+	)
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		resp, retry, err = c.send(ctx, req, body, traceID, key)
+		if !retry || attempt >= c.maxRetries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+	if err != nil {
+		return resp, err
+	}
+
+	if out != nil {
+		err = unmarshalData(resp.Data, out)
+	}
+	return resp, err
+}
+
+// send 发送一次请求，返回是否需要重试：网络异常、网关异常以及相同幂等键的请求仍在处理中时重试
+func (c *Client) send(ctx context.Context, req request, body []byte, traceID, key string) (*response, bool, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("trace_id", traceID)
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if key != "" {
+		httpReq.Header.Set("Idempotency-Key", key)
+	}
+	if req.version != "" {
+		httpReq.Header.Set("If-Match", fmt.Sprintf("\"%s\"", req.version))
+	}
+	if owner, ok := ctx.Value(ownerCredentials{}).(ownerCredentials); ok {
+		httpReq.SetBasicAuth(owner.username, owner.password)
+	}
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, ctx.Err() == nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, true, err
+	}
+	resp := &response{}
+	if err = json.Unmarshal(data, resp); err != nil {
+		retry := httpResp.StatusCode == http.StatusBadGateway || httpResp.StatusCode == http.StatusServiceUnavailable || httpResp.StatusCode == http.StatusGatewayTimeout
+		return nil, retry, fmt.Errorf("unexpected response with status %d (trace_id: %s): %s", httpResp.StatusCode, traceID, bytes.TrimSpace(data))
+	}
+	if resp.TraceId == "" {
+		resp.TraceId = traceID
+	}
+
+	if httpResp.StatusCode >= http.StatusBadRequest || resp.Code != 0 {
+		return resp, resp.Code == constants.CodeRequestInProgress, newError(httpResp.StatusCode, resp)
+	}
+	return resp, false, nil
+}
+
+// unmarshalData 解析响应中的data
+func unmarshalData(data []byte, out interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response data: %v", err)
+	}
+	return nil
+}
+
+// download 获取非json格式的响应内容，例如用户照片；出错时服务端仍然以json格式返回错误
+func (c *Client) download(ctx context.Context, req request) ([]byte, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	traceID, _ := ctx.Value("trace_id").(string)
+	if traceID == "" {
+		traceID = utils.GenUuid("req")
+	}
+	httpReq.Header.Set("trace_id", traceID)
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode >= http.StatusBadRequest {
+		resp := &response{}
+		if err = json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+			return nil, fmt.Errorf("unexpected response with status %d (trace_id: %s)", httpResp.StatusCode, traceID)
+		}
+		return nil, newError(httpResp.StatusCode, resp)
+	}
+	return io.ReadAll(httpResp.Body)
+}
+
+// export 通过导出接口以jsonl格式获取对象，逐行交给fn处理；导出过程中的错误无法重试
+func (c *Client) export(ctx context.Context, objType, filter, searchBase string, attributes []string, fn func(line []byte) error) error {
+	query := url.Values{"format": {"jsonl"}, "type": {objType}}
+	if filter != "" {
+		query.Set("filter", filter)
+	}
+	if searchBase != "" {
+		query.Set("search_base", searchBase)
+	}
+	if len(attributes) > 0 {
+		query.Set("attributes", strings.Join(attributes, ","))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/ldap/export?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	traceID, _ := ctx.Value("trace_id").(string)
+	if traceID == "" {
+		traceID = utils.GenUuid("req")
+	}
+	httpReq.Header.Set("trace_id", traceID)
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	// 开始输出前的错误仍然以json格式返回
+	if httpResp.StatusCode >= http.StatusBadRequest {
+		resp := &response{}
+		if err = json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+			return fmt.Errorf("unexpected response with status %d (trace_id: %s)", httpResp.StatusCode, traceID)
+		}
+		return newError(httpResp.StatusCode, resp)
+	}
+
+	reader := bufio.NewReader(httpResp.Body)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			// 导出中途出错时，服务端会在已输出的内容之后追加json格式的错误
+			var resp response
+			if json.Unmarshal(line, &resp) == nil && resp.TraceId != "" && resp.Code != 0 {
+				return newError(http.StatusInternalServerError, &resp)
+			}
+			if fnErr := fn(line); fnErr != nil {
+				return fnErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return &ers.OptErr{Option: "read export stream", Message: err.Error()}
+		}
+	}
+}
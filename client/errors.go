@@ -0,0 +1,137 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"ldap-http-service/constants"
+	"ldap-http-service/lib/ers"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error 服务端返回的错误，实现ers.CustomErr；Unwrap返回按错误码及错误信息还原的lib/ers错误，
+// 可以通过errors.As判断具体的错误类型，例如 *ers.NotFoundError
+type Error struct {
+	httpCode int
+	code     int
+	message  string
+	traceID  string
+	data     json.RawMessage
+	err      ers.CustomErr
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (trace_id: %s)", e.message, e.traceID)
+}
+
+func (e *Error) Code() int {
+	return e.code
+}
+
+func (e *Error) HttpCode() int {
+	return e.httpCode
+}
+
+// TraceID 返回请求的trace_id，用于排查服务端日志
+func (e *Error) TraceID() string {
+	return e.traceID
+}
+
+// Unwrap 返回对应的lib/ers错误，无法识别的错误返回nil
+func (e *Error) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
+
+// errorPattern 通过错误信息还原lib/ers错误，与ers中各错误类型的Error()格式对应
+type errorPattern struct {
+	pattern *regexp.Regexp
+	build   func(match []string) ers.CustomErr
+}
+
+var errorPatterns = []errorPattern{
+	{regexp.MustCompile(`^no object matched '(.*)'$`), func(m []string) ers.CustomErr {
+		return &ers.NotFoundError{Object: m[1]}
+	}},
+	{regexp.MustCompile(`^object already exists err: (.*)$`), func(m []string) ers.CustomErr {
+		return &ers.ObjExistError{Object: m[1]}
+	}},
+	{regexp.MustCompile(`^object '(.*)' has been modified since version '(.*)'$`), func(m []string) ers.CustomErr {
+		return &ers.PreconditionFailedErr{Object: m[1], Version: m[2]}
+	}},
+	{regexp.MustCompile(`^idempotency key '(.*)' has been used by a different request$`), func(m []string) ers.CustomErr {
+		return &ers.IdempotencyKeyReusedErr{Key: m[1]}
+	}},
+	{regexp.MustCompile(`^request with idempotency key '(.*)' is still in progress$`), func(m []string) ers.CustomErr {
+		return &ers.RequestInProgressErr{Key: m[1]}
+	}},
+	{regexp.MustCompile(`^cursor '(-?\d+)' has expired, please resync$`), func(m []string) ers.CustomErr {
+		cursor, _ := strconv.ParseInt(m[1], 10, 64)
+		return &ers.CursorExpiredErr{Cursor: cursor}
+	}},
+	{regexp.MustCompile(`^adding '(.*)' to group '(.*)' would create a membership cycle$`), func(m []string) ers.CustomErr {
+		return &ers.MembershipCycleErr{Member: m[1], Group: m[2]}
+	}},
+	{regexp.MustCompile(`^setting '(.*)' as manager of '(.*)' would create a reporting cycle$`), func(m []string) ers.CustomErr {
+		return &ers.ManagerCycleErr{Manager: m[1], User: m[2]}
+	}},
+	{regexp.MustCompile(`^unauthorized: (.*)$`), func(m []string) ers.CustomErr {
+		return &ers.UnauthorizedErr{Message: m[1]}
+	}},
+	{regexp.MustCompile(`^invalid json body$`), func(m []string) ers.CustomErr {
+		return &ers.InvalidJsonErr{}
+	}},
+	{regexp.MustCompile(`^bad request: (.*)$`), func(m []string) ers.CustomErr {
+		return &ers.BadRequestErr{Message: m[1]}
+	}},
+	{regexp.MustCompile(`^invalid patch: (.*)$`), func(m []string) ers.CustomErr {
+		return &ers.InvalidPatchErr{Message: m[1]}
+	}},
+	{regexp.MustCompile(`^unsupported (.*): '(.*)'$`), func(m []string) ers.CustomErr {
+		return &ers.UnSupportedErr{ObjectType: m[1], Object: m[2]}
+	}},
+	{regexp.MustCompile(`^invalid (.*) format: '(.*)'$`), func(m []string) ers.CustomErr {
+		return &ers.InvalidFormatErr{Name: m[1], Object: m[2]}
+	}},
+	{regexp.MustCompile(`^(.*) timeout after ([\d.]+)s$`), func(m []string) ers.CustomErr {
+		seconds, _ := strconv.ParseFloat(m[2], 64)
+		return &ers.TimeoutErr{Option: m[1], Time: time.Duration(seconds * float64(time.Second))}
+	}},
+	{regexp.MustCompile(`^failed to (.*?): (.*)$`), func(m []string) ers.CustomErr {
+		return &ers.OptErr{Option: m[1], Message: m[2]}
+	}},
+	{regexp.MustCompile(`^interval server error: (.*)$`), func(m []string) ers.CustomErr {
+		return &ers.SystemErr{Message: m[1]}
+	}},
+}
+
+// newError 将错误响应转换为Error
+func newError(httpCode int, resp *response) *Error {
+	e := &Error{httpCode: httpCode, code: resp.Code, message: resp.Message, traceID: resp.TraceId, data: resp.Data}
+
+	switch {
+	case resp.Code == constants.CodeValidationFailed:
+		var details struct {
+			Errors []ers.FieldError `json:"errors"`
+		}
+		_ = json.Unmarshal(resp.Data, &details)
+		e.err = &ers.ValidationErr{Fields: details.Errors}
+	case httpCode == http.StatusForbidden:
+		e.err = &ers.ForbiddenErr{Message: resp.Message}
+	case resp.Code == constants.CodeInternalException && resp.Message == "Internal Server Error":
+		e.err = &ers.BaseErr{}
+	default:
+		for _, p := range errorPatterns {
+			if match := p.pattern.FindStringSubmatch(strings.TrimSpace(resp.Message)); match != nil {
+				e.err = p.build(match)
+				break
+			}
+		}
+	}
+	return e
+}
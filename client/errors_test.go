@@ -0,0 +1,105 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"ldap-http-service/constants"
+	"ldap-http-service/lib/ers"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// 服务端按ers错误的Error()返回message，客户端应还原出相同的错误
+func TestNewErrorRoundTrip(t *testing.T) {
+	tests := []ers.CustomErr{
+		&ers.NotFoundError{Object: "CN=alice,OU=Users,DC=example,DC=com"},
+		&ers.ObjExistError{Object: "alice"},
+		&ers.PreconditionFailedErr{Object: "CN=sales,OU=Groups,DC=example,DC=com", Version: "12345"},
+		&ers.IdempotencyKeyReusedErr{Key: "k1"},
+		&ers.RequestInProgressErr{Key: "k1"},
+		&ers.CursorExpiredErr{Cursor: 42},
+		&ers.MembershipCycleErr{Group: "CN=a,DC=example,DC=com", Member: "CN=b,DC=example,DC=com"},
+		&ers.ManagerCycleErr{User: "CN=a,DC=example,DC=com", Manager: "CN=b,DC=example,DC=com"},
+		&ers.UnauthorizedErr{Message: "invalid credentials"},
+		&ers.InvalidJsonErr{},
+		&ers.BadRequestErr{Message: "operations[0]: unknown type"},
+		&ers.InvalidPatchErr{Message: "path '/cn' is not allowed"},
+		&ers.UnSupportedErr{ObjectType: "user_id_type", Object: "cn"},
+		&ers.InvalidFormatErr{Name: "objectGUID", Object: "x"},
+		&ers.TimeoutErr{Option: "search", Time: 1500 * time.Millisecond},
+		&ers.OptErr{Option: "create user", Message: "constraint violation"},
+		&ers.SystemErr{Message: "connection refused"},
+	}
+
+	for _, want := range tests {
+		t.Run(reflect.TypeOf(want).Elem().Name(), func(t *testing.T) {
+			resp := &response{Code: want.Code(), Message: want.Error(), TraceId: "req-1"}
+			got := newError(want.HttpCode(), resp)
+			if got.Code() != want.Code() || got.HttpCode() != want.HttpCode() || got.TraceID() != "req-1" {
+				t.Errorf("newError() = %d/%d/%s, want %d/%d/req-1", got.HttpCode(), got.Code(), got.TraceID(), want.HttpCode(), want.Code())
+			}
+			if !reflect.DeepEqual(got.Unwrap(), want) {
+				t.Errorf("newError(%q).Unwrap() = %#v, want %#v", want.Error(), got.Unwrap(), want)
+			}
+		})
+	}
+}
+
+func TestNewError(t *testing.T) {
+	fields := []ers.FieldError{{Field: "OU", Message: "is required"}}
+	data, _ := json.Marshal(map[string]interface{}{"errors": fields})
+
+	tests := []struct {
+		name     string
+		httpCode int
+		resp     *response
+		want     error
+	}{
+		{
+			name:     "validation details",
+			httpCode: http.StatusBadRequest,
+			resp:     &response{Code: constants.CodeValidationFailed, Message: "validation failed: OU is required", Data: data},
+			want:     &ers.ValidationErr{Fields: fields},
+		},
+		{
+			name:     "forbidden by status",
+			httpCode: http.StatusForbidden,
+			resp:     &response{Code: constants.CodeInternalException, Message: "owner mode only allows updating group members"},
+			want:     &ers.ForbiddenErr{Message: "owner mode only allows updating group members"},
+		},
+		{
+			name:     "hidden internal error",
+			httpCode: http.StatusInternalServerError,
+			resp:     &response{Code: constants.CodeInternalException, Message: "Internal Server Error"},
+			want:     &ers.BaseErr{},
+		},
+		{
+			name:     "unknown message",
+			httpCode: http.StatusBadGateway,
+			resp:     &response{Code: constants.CodeInternalException, Message: "bad gateway"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newError(tt.httpCode, tt.resp)
+			if !reflect.DeepEqual(got.Unwrap(), tt.want) {
+				t.Errorf("newError().Unwrap() = %#v, want %#v", got.Unwrap(), tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorAs(t *testing.T) {
+	var err error = newError(http.StatusNotFound, &response{Message: "no object matched 'alice'"})
+	var notFound *ers.NotFoundError
+	if !errors.As(err, &notFound) || notFound.Object != "alice" {
+		t.Errorf("errors.As(*ers.NotFoundError) = %v, want object 'alice'", notFound)
+	}
+	var clientErr *Error
+	if !errors.As(err, &clientErr) || clientErr.HttpCode() != http.StatusNotFound {
+		t.Errorf("errors.As(*Error) failed for %v", err)
+	}
+}
@@ -0,0 +1,720 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"ldap-http-service/constants"
+	"ldap-http-service/core/bulk"
+	"ldap-http-service/core/changes"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/core/webhook"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewUser 创建启用用户的参数
+type NewUser struct {
+	SAMAccountName string `json:"sAMAccountName,omitempty"`
+	DisplayName    string `json:"displayName"`
+	OU             string `json:"OU"`
+	Password       string `json:"password"`
+	PrimaryDomain  string `json:"primaryDomain"`
+	// AutoName为true时根据GivenName和Sn自动生成未被占用的用户名，忽略SAMAccountName
+	AutoName  bool   `json:"auto_name,omitempty"`
+	GivenName string `json:"givenName,omitempty"`
+	Sn        string `json:"sn,omitempty"`
+}
+
+// NewGroup 创建群组的参数，GroupType为AD群组类型，负数为安全组
+type NewGroup struct {
+	SAMAccountName string `json:"sAMAccountName"`
+	OU             string `json:"OU"`
+	DisplayName    string `json:"displayName"`
+	Description    string `json:"description"`
+	GroupType      int    `json:"groupType"`
+}
+
+// NewComputer 预创建计算机账户的参数，Name为计算机名，不含结尾的$
+type NewComputer struct {
+	Name        string `json:"name"`
+	OU          string `json:"OU"`
+	DNSHostName string `json:"dNSHostName"`
+	Description string `json:"description"`
+}
+
+// NewContact 创建邮件联系人的参数，Name为联系人的CN
+type NewContact struct {
+	Name         string `json:"name"`
+	OU           string `json:"OU"`
+	Mail         string `json:"mail"`
+	DisplayName  string `json:"displayName"`
+	GivenName    string `json:"givenName"`
+	Sn           string `json:"sn"`
+	Company      string `json:"company"`
+	Description  string `json:"description"`
+	MailNickname string `json:"mailNickname"`
+}
+
+// patchOp JSON Patch风格的单个操作
+type patchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// 以JSON Patch风格提交属性变更，Attribute为OU时移动对象
+func patchOps(changes []ldap.AttrChange) []patchOp {
+	ops := make([]patchOp, 0, len(changes))
+	for _, change := range changes {
+		op := patchOp{Op: change.Op, Path: "/" + change.Attribute, Value: change.Values}
+		if change.Attribute == "OU" && len(change.Values) > 0 {
+			op.Value = change.Values[0]
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// 对象查询参数，idTypeName为空时不设置标识类型，attributes为空时获取全部属性
+func objectQuery(idTypeName, idType, searchBase string, attributes []string) url.Values {
+	query := url.Values{}
+	if idTypeName != "" {
+		query.Set(idTypeName, idType)
+	}
+	if searchBase != "" {
+		query.Set("search_base", searchBase)
+	}
+	if len(attributes) > 0 {
+		query.Set("attributes", strings.Join(attributes, ","))
+	}
+	return query
+}
+
+// Healthz 检查服务及LDAP连接是否可用
+func (c *Client) Healthz(ctx context.Context) error {
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/ldap/healthz"}, nil)
+	return err
+}
+
+// CheckAvailability 检查sAMAccountName是否可用，不可用时返回占用该名称的对象
+func (c *Client) CheckAvailability(ctx context.Context, name string) (bool, *ldap.BaseObject, error) {
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/ldap/availability", query: url.Values{"name": {name}}}, nil)
+	if err == nil {
+		return true, nil, nil
+	}
+
+	var e *Error
+	if !errors.As(err, &e) || e.Code() != constants.CodeObjAlreadyExists {
+		return false, nil, err
+	}
+	var data struct {
+		Object *ldap.BaseObject `json:"object"`
+	}
+	if err = unmarshalData(e.data, &data); err != nil {
+		return false, nil, err
+	}
+	return false, data.Object, nil
+}
+
+// SuggestNames 根据姓名生成前count个未被占用的候选用户名，count为0时使用服务端默认值
+func (c *Client) SuggestNames(ctx context.Context, givenName, surname string, count int) ([]string, error) {
+	query := url.Values{"given": {givenName}, "surname": {surname}}
+	if count > 0 {
+		query.Set("count", strconv.Itoa(count))
+	}
+
+	var data struct {
+		Suggestions []string `json:"suggestions"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/ldap/availability/suggest", query: query}, &data)
+	return data.Suggestions, err
+}
+
+// GetUser 获取用户信息，可通过attributes指定需要获取的属性
+func (c *Client) GetUser(ctx context.Context, userId, userIdType, searchBase string, attributes ...string) (ldap.User, error) {
+	var data struct {
+		User ldap.User `json:"user"`
+	}
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/ldap/user/" + url.PathEscape(userId),
+		query:  objectQuery("user_id_type", userIdType, searchBase, attributes),
+	}, &data)
+	return data.User, err
+}
+
+// CreateUser 创建启用的用户
+func (c *Client) CreateUser(ctx context.Context, user NewUser) (ldap.User, error) {
+	var data struct {
+		User ldap.User `json:"user"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/ldap/user", body: user}, &data)
+	return data.User, err
+}
+
+// UpdateUser 在一次请求中变更用户属性，Attribute为OU的replace变更代表移动用户；version不为空时要求用户的uSNChanged与其一致
+func (c *Client) UpdateUser(ctx context.Context, userId, userIdType, searchBase, version string, changes ...ldap.AttrChange) (ldap.User, error) {
+	var data struct {
+		User ldap.User `json:"user"`
+	}
+	_, err := c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        "/ldap/user/" + url.PathEscape(userId),
+		query:       objectQuery("user_id_type", userIdType, searchBase, nil),
+		version:     version,
+		contentType: "application/json-patch+json",
+		body:        patchOps(changes),
+	}, &data)
+	return data.User, err
+}
+
+// SetPassword 设置用户密码并解锁账户，version不为空时要求用户的uSNChanged与其一致
+func (c *Client) SetPassword(ctx context.Context, userId, userIdType, searchBase, password, version string) error {
+	_, err := c.do(ctx, request{
+		method:  http.MethodPost,
+		path:    "/ldap/user/" + url.PathEscape(userId) + "/password",
+		query:   objectQuery("user_id_type", userIdType, searchBase, nil),
+		version: version,
+		body:    map[string]string{"password": password},
+	}, nil)
+	return err
+}
+
+// AddProxyAddress 添加用户的SMTP别名，primary为true时设置为主SMTP地址并同步mail，返回变更后用户的邮箱属性
+func (c *Client) AddProxyAddress(ctx context.Context, userId, userIdType, searchBase, version, address string, primary bool) (ldap.User, error) {
+	return c.updateUser(ctx, request{
+		method:  http.MethodPost,
+		path:    "/ldap/user/" + url.PathEscape(userId) + "/proxy-addresses",
+		query:   objectQuery("user_id_type", userIdType, searchBase, nil),
+		version: version,
+		body:    map[string]interface{}{"address": address, "primary": primary},
+	})
+}
+
+// RemoveProxyAddress 移除用户的SMTP别名，返回变更后用户的邮箱属性
+func (c *Client) RemoveProxyAddress(ctx context.Context, userId, userIdType, searchBase, version, address string) (ldap.User, error) {
+	return c.updateUser(ctx, request{
+		method:  http.MethodDelete,
+		path:    "/ldap/user/" + url.PathEscape(userId) + "/proxy-addresses/" + url.PathEscape(address),
+		query:   objectQuery("user_id_type", userIdType, searchBase, nil),
+		version: version,
+	})
+}
+
+// SetPrimarySMTPAddress 设置用户的主SMTP地址并同步mail，返回变更后用户的邮箱属性
+func (c *Client) SetPrimarySMTPAddress(ctx context.Context, userId, userIdType, searchBase, version, address string) (ldap.User, error) {
+	return c.updateUser(ctx, request{
+		method:  http.MethodPut,
+		path:    "/ldap/user/" + url.PathEscape(userId) + "/primary-smtp-address",
+		query:   objectQuery("user_id_type", userIdType, searchBase, nil),
+		version: version,
+		body:    map[string]string{"address": address},
+	})
+}
+
+// SetMailboxQuota 设置用户的邮箱配额，单位为KB，返回变更后用户的邮箱属性
+func (c *Client) SetMailboxQuota(ctx context.Context, userId, userIdType, searchBase, version string, quota ldap.MailboxQuota) (ldap.User, error) {
+	return c.updateUser(ctx, request{
+		method:  http.MethodPut,
+		path:    "/ldap/user/" + url.PathEscape(userId) + "/mailbox-quota",
+		query:   objectQuery("user_id_type", userIdType, searchBase, nil),
+		version: version,
+		body: map[string]interface{}{
+			"use_defaults":          quota.UseDefaults,
+			"storage_quota":         quota.StorageQuota,
+			"over_quota_limit":      quota.OverQuotaLimit,
+			"over_hard_quota_limit": quota.OverHardQuotaLimit,
+		},
+	})
+}
+
+// SetManager 设置用户的经理，version不为空时要求用户的uSNChanged与其一致
+func (c *Client) SetManager(ctx context.Context, userId, userIdType, manager, managerIdType, searchBase, version string) (ldap.User, error) {
+	query := objectQuery("user_id_type", userIdType, searchBase, nil)
+	query.Set("manager_id_type", managerIdType)
+	return c.updateUser(ctx, request{
+		method:  http.MethodPut,
+		path:    "/ldap/user/" + url.PathEscape(userId) + "/manager",
+		query:   query,
+		version: version,
+		body:    map[string]string{"manager": manager},
+	})
+}
+
+// ClearManager 清除用户的经理，version不为空时要求用户的uSNChanged与其一致
+func (c *Client) ClearManager(ctx context.Context, userId, userIdType, searchBase, version string) (ldap.User, error) {
+	return c.updateUser(ctx, request{
+		method:  http.MethodDelete,
+		path:    "/ldap/user/" + url.PathEscape(userId) + "/manager",
+		query:   objectQuery("user_id_type", userIdType, searchBase, nil),
+		version: version,
+	})
+}
+
+func (c *Client) updateUser(ctx context.Context, req request) (ldap.User, error) {
+	var data struct {
+		User ldap.User `json:"user"`
+	}
+	_, err := c.do(ctx, req, &data)
+	return data.User, err
+}
+
+// GetOrgChart 获取用户的汇报关系，向上及向下各遍历depth层，depth为0时使用服务端默认值
+func (c *Client) GetOrgChart(ctx context.Context, userId, userIdType, searchBase string, depth int) (*ldap.OrgChart, error) {
+	query := objectQuery("user_id_type", userIdType, searchBase, nil)
+	if depth > 0 {
+		query.Set("depth", strconv.Itoa(depth))
+	}
+
+	var data struct {
+		OrgChart *ldap.OrgChart `json:"orgchart"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/ldap/user/" + url.PathEscape(userId) + "/orgchart", query: query}, &data)
+	return data.OrgChart, err
+}
+
+// GetUserPhoto 获取用户的照片（thumbnailPhoto）原始字节，用户没有照片时返回*ers.NotFoundError
+func (c *Client) GetUserPhoto(ctx context.Context, userId, userIdType, searchBase string) ([]byte, error) {
+	return c.download(ctx, request{
+		method: http.MethodGet,
+		path:   "/ldap/user/" + url.PathEscape(userId) + "/photo",
+		query:  objectQuery("user_id_type", userIdType, searchBase, nil),
+	})
+}
+
+// SetUserPhoto 上传用户的照片，返回服务端实际写入的照片大小；version不为空时要求用户的uSNChanged与其一致
+func (c *Client) SetUserPhoto(ctx context.Context, userId, userIdType, searchBase, version string, photo []byte) (int, error) {
+	return c.updatePhoto(ctx, request{
+		method:      http.MethodPut,
+		path:        "/ldap/user/" + url.PathEscape(userId) + "/photo",
+		query:       objectQuery("user_id_type", userIdType, searchBase, nil),
+		version:     version,
+		contentType: http.DetectContentType(photo),
+		body:        photo,
+	})
+}
+
+// DeleteUserPhoto 删除用户的照片，version不为空时要求用户的uSNChanged与其一致
+func (c *Client) DeleteUserPhoto(ctx context.Context, userId, userIdType, searchBase, version string) error {
+	_, err := c.updatePhoto(ctx, request{
+		method:  http.MethodDelete,
+		path:    "/ldap/user/" + url.PathEscape(userId) + "/photo",
+		query:   objectQuery("user_id_type", userIdType, searchBase, nil),
+		version: version,
+	})
+	return err
+}
+
+func (c *Client) updatePhoto(ctx context.Context, req request) (int, error) {
+	var data struct {
+		Photo struct {
+			Size int `json:"size"`
+		} `json:"photo"`
+	}
+	_, err := c.do(ctx, req, &data)
+	return data.Photo.Size, err
+}
+
+// SearchPeople 人员选择器搜索，返回按匹配程度排序的用户、群组及联系人，limit为0时使用服务端默认值
+func (c *Client) SearchPeople(ctx context.Context, q string, limit int) ([]ldap.Person, error) {
+	query := url.Values{"q": {q}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var data struct {
+		People []ldap.Person `json:"people"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/ldap/search/people", query: query}, &data)
+	return data.People, err
+}
+
+// LookupUsers 按idType批量获取用户，返回标识到用户的映射以及未找到的标识
+func (c *Client) LookupUsers(ctx context.Context, ids []string, idType, searchBase string, attributes ...string) (map[string]ldap.User, []string, error) {
+	var data struct {
+		Users    map[string]ldap.User `json:"users"`
+		NotFound []string             `json:"not_found"`
+	}
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/ldap/users/lookup",
+		query:  objectQuery("", "", searchBase, attributes),
+		body:   map[string]interface{}{"ids": ids, "id_type": idType},
+	}, &data)
+	return data.Users, data.NotFound, err
+}
+
+// LookupGroups 按idType批量获取群组，返回标识到群组的映射以及未找到的标识
+func (c *Client) LookupGroups(ctx context.Context, ids []string, idType, searchBase string, attributes ...string) (map[string]ldap.Group, []string, error) {
+	var data struct {
+		Groups   map[string]ldap.Group `json:"groups"`
+		NotFound []string              `json:"not_found"`
+	}
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/ldap/groups/lookup",
+		query:  objectQuery("", "", searchBase, attributes),
+		body:   map[string]interface{}{"ids": ids, "id_type": idType},
+	}, &data)
+	return data.Groups, data.NotFound, err
+}
+
+// GetGroup 获取群组信息，可通过attributes指定需要获取的属性
+func (c *Client) GetGroup(ctx context.Context, groupId, groupIdType, searchBase string, attributes ...string) (ldap.Group, error) {
+	var data struct {
+		Group ldap.Group `json:"group"`
+	}
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/ldap/group/" + url.PathEscape(groupId),
+		query:  objectQuery("group_id_type", groupIdType, searchBase, attributes),
+	}, &data)
+	return data.Group, err
+}
+
+// GetMember 按与更新群组成员相同的规则解析成员，memberId可带有user:、contact:、group:类型提示
+func (c *Client) GetMember(ctx context.Context, memberId, memberIdType, searchBase string) (ldap.BaseObject, error) {
+	var data struct {
+		Member ldap.BaseObject `json:"member"`
+	}
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/ldap/member/" + url.PathEscape(memberId),
+		query:  objectQuery("member_id_type", memberIdType, searchBase, nil),
+	}, &data)
+	return data.Member, err
+}
+
+// CreateGroup 创建群组
+func (c *Client) CreateGroup(ctx context.Context, group NewGroup) (ldap.Group, error) {
+	var data struct {
+		Group ldap.Group `json:"group"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/ldap/group", body: group}, &data)
+	return data.Group, err
+}
+
+// UpdateGroup 在一次请求中变更群组属性，version不为空时要求群组的uSNChanged与其一致
+func (c *Client) UpdateGroup(ctx context.Context, groupId, groupIdType, searchBase, version string, changes ...ldap.AttrChange) (ldap.Group, error) {
+	var data struct {
+		Group ldap.Group `json:"group"`
+	}
+	_, err := c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        "/ldap/group/" + url.PathEscape(groupId),
+		query:       objectQuery("group_id_type", groupIdType, searchBase, nil),
+		version:     version,
+		contentType: "application/json-patch+json",
+		body:        patchOps(changes),
+	}, &data)
+	return data.Group, err
+}
+
+// UpdateGroupMembers 添加、移除群组成员，返回变更后的群组以及无法解析或执行失败的成员信息
+func (c *Client) UpdateGroupMembers(ctx context.Context, groupId, groupIdType, memberIdType, searchBase, version string, add, remove []string) (ldap.Group, []string, error) {
+	return c.updateGroupMembers(ctx, groupId, groupIdType, memberIdType, searchBase, version, map[string]interface{}{"add_members": add, "remove_members": remove})
+}
+
+// AddExpiringGroupMembers 限时添加群组成员，成员在expiresAt后自动移除，返回变更后的群组以及无法解析或执行失败的成员信息
+func (c *Client) AddExpiringGroupMembers(ctx context.Context, groupId, groupIdType, memberIdType, searchBase, version string, expiresAt time.Time, members []string) (ldap.Group, []string, error) {
+	expiring := make([]map[string]interface{}, 0, len(members))
+	for _, member := range members {
+		expiring = append(expiring, map[string]interface{}{"member": member, "expires_at": expiresAt})
+	}
+	return c.updateGroupMembers(ctx, groupId, groupIdType, memberIdType, searchBase, version, map[string]interface{}{"add_expiring_members": expiring})
+}
+
+func (c *Client) updateGroupMembers(ctx context.Context, groupId, groupIdType, memberIdType, searchBase, version string, body map[string]interface{}) (ldap.Group, []string, error) {
+	query := objectQuery("group_id_type", groupIdType, searchBase, nil)
+	query.Set("member_id_type", memberIdType)
+
+	var data struct {
+		Group ldap.Group `json:"group"`
+	}
+	resp, err := c.do(ctx, request{
+		method:  http.MethodPut,
+		path:    "/ldap/group/" + url.PathEscape(groupId) + "/member",
+		query:   query,
+		version: version,
+		body:    body,
+	}, &data)
+	if err != nil {
+		return data.Group, nil, err
+	}
+
+	// 失败的成员以分号拼接在message中，第一项为ok
+	failures := strings.Split(resp.Message, ";")[1:]
+	return data.Group, failures, nil
+}
+
+// SetGroupOwner 设置群组所有者，返回变更后的所有者及共同管理者；version不为空时要求群组的uSNChanged与其一致
+func (c *Client) SetGroupOwner(ctx context.Context, groupId, groupIdType, owner, ownerIdType, searchBase, version string) (ldap.Group, error) {
+	query := objectQuery("group_id_type", groupIdType, searchBase, nil)
+	query.Set("owner_id_type", ownerIdType)
+	return c.groupManagers(ctx, request{
+		method:  http.MethodPut,
+		path:    "/ldap/group/" + url.PathEscape(groupId) + "/owner",
+		query:   query,
+		version: version,
+		body:    map[string]string{"owner": owner},
+	})
+}
+
+// ClearGroupOwner 清空群组所有者，version不为空时要求群组的uSNChanged与其一致
+func (c *Client) ClearGroupOwner(ctx context.Context, groupId, groupIdType, searchBase, version string) (ldap.Group, error) {
+	return c.groupManagers(ctx, request{
+		method:  http.MethodDelete,
+		path:    "/ldap/group/" + url.PathEscape(groupId) + "/owner",
+		query:   objectQuery("group_id_type", groupIdType, searchBase, nil),
+		version: version,
+	})
+}
+
+// SetGroupCoManagers 替换群组的共同管理者，version不为空时要求群组的uSNChanged与其一致
+func (c *Client) SetGroupCoManagers(ctx context.Context, groupId, groupIdType, managerIdType, searchBase, version string, managers []string) (ldap.Group, error) {
+	query := objectQuery("group_id_type", groupIdType, searchBase, nil)
+	query.Set("manager_id_type", managerIdType)
+	return c.groupManagers(ctx, request{
+		method:  http.MethodPut,
+		path:    "/ldap/group/" + url.PathEscape(groupId) + "/co-managers",
+		query:   query,
+		version: version,
+		body:    map[string][]string{"co_managers": managers},
+	})
+}
+
+// ClearGroupCoManagers 清空群组的共同管理者，version不为空时要求群组的uSNChanged与其一致
+func (c *Client) ClearGroupCoManagers(ctx context.Context, groupId, groupIdType, searchBase, version string) (ldap.Group, error) {
+	return c.groupManagers(ctx, request{
+		method:  http.MethodDelete,
+		path:    "/ldap/group/" + url.PathEscape(groupId) + "/co-managers",
+		query:   objectQuery("group_id_type", groupIdType, searchBase, nil),
+		version: version,
+	})
+}
+
+func (c *Client) groupManagers(ctx context.Context, req request) (ldap.Group, error) {
+	var data struct {
+		Group ldap.Group `json:"group"`
+	}
+	_, err := c.do(ctx, req, &data)
+	return data.Group, err
+}
+
+// GetComputer 获取计算机信息，可通过attributes指定需要获取的属性
+func (c *Client) GetComputer(ctx context.Context, computerId, computerIdType, searchBase string, attributes ...string) (ldap.Computer, error) {
+	var data struct {
+		Computer ldap.Computer `json:"computer"`
+	}
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/ldap/computer/" + url.PathEscape(computerId),
+		query:  objectQuery("computer_id_type", computerIdType, searchBase, attributes),
+	}, &data)
+	return data.Computer, err
+}
+
+// CreateComputer 在OU中预创建计算机账户
+func (c *Client) CreateComputer(ctx context.Context, computer NewComputer) (ldap.Computer, error) {
+	var data struct {
+		Computer ldap.Computer `json:"computer"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/ldap/computer", body: computer}, &data)
+	return data.Computer, err
+}
+
+// UpdateComputer 在一次请求中变更计算机属性，Attribute为OU的replace变更代表移动计算机；version不为空时要求计算机的uSNChanged与其一致
+func (c *Client) UpdateComputer(ctx context.Context, computerId, computerIdType, searchBase, version string, changes ...ldap.AttrChange) (ldap.Computer, error) {
+	var data struct {
+		Computer ldap.Computer `json:"computer"`
+	}
+	_, err := c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        "/ldap/computer/" + url.PathEscape(computerId),
+		query:       objectQuery("computer_id_type", computerIdType, searchBase, nil),
+		version:     version,
+		contentType: "application/json-patch+json",
+		body:        patchOps(changes),
+	}, &data)
+	return data.Computer, err
+}
+
+// DisableComputer 禁用计算机账户，version不为空时要求计算机的uSNChanged与其一致
+func (c *Client) DisableComputer(ctx context.Context, computerId, computerIdType, searchBase, version string) (ldap.Computer, error) {
+	var data struct {
+		Computer ldap.Computer `json:"computer"`
+	}
+	_, err := c.do(ctx, request{
+		method:  http.MethodPost,
+		path:    "/ldap/computer/" + url.PathEscape(computerId) + "/disable",
+		query:   objectQuery("computer_id_type", computerIdType, searchBase, nil),
+		version: version,
+	}, &data)
+	return data.Computer, err
+}
+
+// GetContact 获取联系人信息，可通过attributes指定需要获取的属性
+func (c *Client) GetContact(ctx context.Context, contactId, contactIdType, searchBase string, attributes ...string) (ldap.Contact, error) {
+	var data struct {
+		Contact ldap.Contact `json:"contact"`
+	}
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/ldap/contact/" + url.PathEscape(contactId),
+		query:  objectQuery("contact_id_type", contactIdType, searchBase, attributes),
+	}, &data)
+	return data.Contact, err
+}
+
+// CreateContact 创建邮件联系人
+func (c *Client) CreateContact(ctx context.Context, contact NewContact) (ldap.Contact, error) {
+	var data struct {
+		Contact ldap.Contact `json:"contact"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/ldap/contact", body: contact}, &data)
+	return data.Contact, err
+}
+
+// UpdateContact 在一次请求中变更联系人属性，Attribute为OU的replace变更代表移动联系人；version不为空时要求联系人的uSNChanged与其一致
+func (c *Client) UpdateContact(ctx context.Context, contactId, contactIdType, searchBase, version string, changes ...ldap.AttrChange) (ldap.Contact, error) {
+	var data struct {
+		Contact ldap.Contact `json:"contact"`
+	}
+	_, err := c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        "/ldap/contact/" + url.PathEscape(contactId),
+		query:       objectQuery("contact_id_type", contactIdType, searchBase, nil),
+		version:     version,
+		contentType: "application/json-patch+json",
+		body:        patchOps(changes),
+	}, &data)
+	return data.Contact, err
+}
+
+// DeleteContact 删除联系人，version不为空时要求联系人的uSNChanged与其一致
+func (c *Client) DeleteContact(ctx context.Context, contactId, contactIdType, searchBase, version string) error {
+	_, err := c.do(ctx, request{
+		method:  http.MethodDelete,
+		path:    "/ldap/contact/" + url.PathEscape(contactId),
+		query:   objectQuery("contact_id_type", contactIdType, searchBase, nil),
+		version: version,
+	}, nil)
+	return err
+}
+
+// SubmitBulkJob 提交异步执行的批量任务
+func (c *Client) SubmitBulkJob(ctx context.Context, ops []bulk.Operation) (*bulk.Job, error) {
+	var data struct {
+		Job *bulk.Job `json:"job"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/ldap/bulk", body: map[string]interface{}{"operations": ops}}, &data)
+	return data.Job, err
+}
+
+// GetJob 获取批量任务及其任务项的执行状态
+func (c *Client) GetJob(ctx context.Context, id string) (*bulk.Job, error) {
+	var data struct {
+		Job *bulk.Job `json:"job"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/ldap/jobs/" + url.PathEscape(id)}, &data)
+	return data.Job, err
+}
+
+// CancelJob 取消批量任务
+func (c *Client) CancelJob(ctx context.Context, id string) (*bulk.Job, error) {
+	var data struct {
+		Job *bulk.Job `json:"job"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/ldap/jobs/" + url.PathEscape(id)}, &data)
+	return data.Job, err
+}
+
+// Changes 获取游标之后的目录变更事件，返回下一次请求使用的游标；since小于0时不指定游标，
+// 游标过期时返回的错误可以通过errors.As判断为*ers.CursorExpiredErr
+func (c *Client) Changes(ctx context.Context, since int64, limit int) ([]changes.Event, int64, error) {
+	query := url.Values{}
+	if since >= 0 {
+		query.Set("since", strconv.FormatInt(since, 10))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var data struct {
+		Events []changes.Event `json:"events"`
+		Cursor int64           `json:"cursor"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/ldap/changes", query: query}, &data)
+	return data.Events, data.Cursor, err
+}
+
+// ListWebhooks 获取webhook订阅列表
+func (c *Client) ListWebhooks(ctx context.Context) ([]webhook.Subscription, error) {
+	var data struct {
+		Webhooks []webhook.Subscription `json:"webhooks"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/ldap/webhooks"}, &data)
+	return data.Webhooks, err
+}
+
+// CreateWebhook 创建webhook订阅，返回的订阅包含密钥，仅在创建时返回
+func (c *Client) CreateWebhook(ctx context.Context, subscription webhook.Subscription) (*webhook.Subscription, error) {
+	var data struct {
+		Webhook *webhook.Subscription `json:"webhook"`
+	}
+	body := map[string]interface{}{
+		"url":         subscription.URL,
+		"secret":      subscription.Secret,
+		"event_types": subscription.EventTypes,
+		"ous":         subscription.OUs,
+	}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/ldap/webhooks", body: body}, &data)
+	return data.Webhook, err
+}
+
+// DeleteWebhook 删除webhook订阅
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/ldap/webhooks/" + url.PathEscape(id)}, nil)
+	return err
+}
+
+// ListDeadDeliveries 获取webhook订阅中超过最大尝试次数而不再重试的投递
+func (c *Client) ListDeadDeliveries(ctx context.Context, id string) ([]webhook.Delivery, error) {
+	var data struct {
+		Deliveries []webhook.Delivery `json:"deliveries"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/ldap/webhooks/" + url.PathEscape(id) + "/dead-deliveries"}, &data)
+	return data.Deliveries, err
+}
+
+// ReplayDelivery 重新投递失败的投递
+func (c *Client) ReplayDelivery(ctx context.Context, id, deliveryId string) error {
+	path := "/ldap/webhooks/" + url.PathEscape(id) + "/dead-deliveries/" + url.PathEscape(deliveryId) + "/replay"
+	_, err := c.do(ctx, request{method: http.MethodPost, path: path}, nil)
+	return err
+}
+
+// SearchUsers 按LDAP过滤条件搜索用户，结果以jsonl格式流式返回，逐个交给fn处理
+func (c *Client) SearchUsers(ctx context.Context, filter, searchBase string, attributes []string, fn func(*ldap.User) error) error {
+	return c.export(ctx, "user", filter, searchBase, attributes, func(line []byte) error {
+		var user ldap.User
+		if err := unmarshalData(line, &user); err != nil {
+			return err
+		}
+		return fn(&user)
+	})
+}
+
+// SearchGroups 按LDAP过滤条件搜索群组，结果以jsonl格式流式返回，逐个交给fn处理
+func (c *Client) SearchGroups(ctx context.Context, filter, searchBase string, attributes []string, fn func(*ldap.Group) error) error {
+	return c.export(ctx, "group", filter, searchBase, attributes, func(line []byte) error {
+		var group ldap.Group
+		if err := unmarshalData(line, &group); err != nil {
+			return err
+		}
+		return fn(&group)
+	})
+}
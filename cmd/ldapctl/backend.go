@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"ldap-http-service/client"
+	"ldap-http-service/core/ldap"
+)
+
+// backend ldapctl执行操作的方式：通过运行中的服务，或者使用相同的配置直接连接LDAP
+type backend interface {
+	CheckAvailability(ctx context.Context, name string) (bool, *ldap.BaseObject, error)
+	GetUser(ctx context.Context, userId, userIdType, searchBase string, attributes ...string) (ldap.User, error)
+	CreateUser(ctx context.Context, user client.NewUser) (ldap.User, error)
+	UpdateUser(ctx context.Context, userId, userIdType, searchBase, version string, changes ...ldap.AttrChange) (ldap.User, error)
+	SetPassword(ctx context.Context, userId, userIdType, searchBase, password, version string) error
+	GetGroup(ctx context.Context, groupId, groupIdType, searchBase string, attributes ...string) (ldap.Group, error)
+	GetMember(ctx context.Context, memberId, memberIdType, searchBase string) (ldap.BaseObject, error)
+	UpdateGroupMembers(ctx context.Context, groupId, groupIdType, memberIdType, searchBase, version string, add, remove []string) (ldap.Group, []string, error)
+	SearchUsers(ctx context.Context, filter, searchBase string, attributes []string, fn func(*ldap.User) error) error
+	SearchGroups(ctx context.Context, filter, searchBase string, attributes []string, fn func(*ldap.Group) error) error
+}
+
+// directBackend 直接通过core/ldap操作LDAP，不经过http服务
+type directBackend struct{}
+
+func (directBackend) CheckAvailability(ctx context.Context, name string) (bool, *ldap.BaseObject, error) {
+	return ldap.CheckAvailability(ctx, name)
+}
+
+func (directBackend) GetUser(ctx context.Context, userId, userIdType, searchBase string, attributes ...string) (ldap.User, error) {
+	return ldap.GetUser(ctx, userId, userIdType, searchBase, attributes...)
+}
+
+func (directBackend) CreateUser(ctx context.Context, user client.NewUser) (ldap.User, error) {
+	err := ldap.CreateEnabledUser(ctx, user.SAMAccountName, user.DisplayName, user.OU, user.Password, user.PrimaryDomain)
+	if err != nil {
+		return ldap.User{}, err
+	}
+	return ldap.GetUser(ctx, user.SAMAccountName, "sAMAccountName", "")
+}
+
+func (directBackend) UpdateUser(ctx context.Context, userId, userIdType, searchBase, version string, changes ...ldap.AttrChange) (ldap.User, error) {
+	user, err := ldap.GetUser(ctx, userId, userIdType, searchBase)
+	if err != nil {
+		return user, err
+	}
+	if err = ldap.ApplyChanges(ctx, user.DistinguishedName, version, changes); err != nil {
+		return user, err
+	}
+	return ldap.GetUser(ctx, user.DistinguishedName, "distinguishedName", "")
+}
+
+func (directBackend) SetPassword(ctx context.Context, userId, userIdType, searchBase, password, version string) error {
+	return ldap.SetUserPwd(ctx, userId, userIdType, password, searchBase, version)
+}
+
+func (directBackend) GetGroup(ctx context.Context, groupId, groupIdType, searchBase string, attributes ...string) (ldap.Group, error) {
+	return ldap.GetGroup(ctx, groupId, groupIdType, searchBase, attributes...)
+}
+
+func (directBackend) GetMember(ctx context.Context, memberId, memberIdType, searchBase string) (ldap.BaseObject, error) {
+	return ldap.GetMember(ctx, memberId, memberIdType, searchBase)
+}
+
+// UpdateGroupMembers 与http接口一致：逐个解析成员，无法解析的成员记录在failures中，其余成员一次性添加或移除
+func (directBackend) UpdateGroupMembers(ctx context.Context, groupId, groupIdType, memberIdType, searchBase, version string, add, remove []string) (ldap.Group, []string, error) {
+	group, err := ldap.GetGroup(ctx, groupId, groupIdType, searchBase)
+	if err != nil {
+		return group, nil, err
+	}
+
+	var failures []string
+	resolve := func(action string, ids []string) []string {
+		var dns []string
+		for _, id := range ids {
+			member, err := ldap.GetMember(ctx, id, memberIdType, searchBase)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s failed: %s", action, err.Error()))
+				continue
+			}
+			dns = append(dns, member.DistinguishedName)
+		}
+		return dns
+	}
+	addDNs, removeDNs := resolve("add", add), resolve("remove", remove)
+
+	if len(addDNs) > 0 {
+		if err = ldap.AddGroupMembers(ctx, group.DistinguishedName, "distinguishedName", version, addDNs...); err != nil {
+			failures = append(failures, fmt.Sprintf("add failed: %s", err.Error()))
+		} else {
+			version = ""
+		}
+	}
+	if len(removeDNs) > 0 {
+		if err = ldap.RemoveGroupMembers(ctx, group.DistinguishedName, "distinguishedName", version, removeDNs...); err != nil {
+			failures = append(failures, fmt.Sprintf("remove failed: %s", err.Error()))
+		}
+	}
+
+	group, err = ldap.GetGroup(ctx, group.DistinguishedName, "distinguishedName", "")
+	return group, failures, err
+}
+
+func (directBackend) SearchUsers(ctx context.Context, filter, searchBase string, attributes []string, fn func(*ldap.User) error) error {
+	return ldap.SearchUsers(ctx, filter, searchBase, attributes, fn)
+}
+
+func (directBackend) SearchGroups(ctx context.Context, filter, searchBase string, attributes []string, fn func(*ldap.Group) error) error {
+	return ldap.SearchGroups(ctx, filter, searchBase, attributes, fn)
+}
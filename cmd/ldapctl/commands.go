@@ -0,0 +1,459 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"ldap-http-service/client"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/utils"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// userAccountControl中的禁用标志位
+const accountDisable = 2
+
+// 默认输出的属性
+var (
+	userColumns  = []string{"sAMAccountName", "displayName", "distinguishedName", "mail", "userPrincipalName", "userAccountControl", "whenChanged", "uSNChanged"}
+	groupColumns = []string{"sAMAccountName", "displayName", "distinguishedName", "mail", "groupType", "managedBy", "uSNChanged"}
+	planColumns  = []string{"action", "target", "result"}
+)
+
+func allCommands() []command {
+	return []command{
+		{"user get", "获取用户信息", userGet},
+		{"user create", "创建启用的用户", userCreate},
+		{"user disable", "禁用用户", userDisable},
+		{"user reset-password", "重置用户密码并解锁账户", userResetPassword},
+		{"group get", "获取群组信息，--members时输出成员列表", groupGet},
+		{"group add-member", "添加群组成员", groupAddMember},
+		{"group remove-member", "移除群组成员", groupRemoveMember},
+		{"group sync", "将群组成员同步为指定的成员列表", groupSync},
+		{"availability", "检查sAMAccountName是否可用", availability},
+		{"search", "按LDAP过滤条件搜索用户或群组", search},
+	}
+}
+
+// commandFlags 子命令的参数解析，args中的参数值需要写在最后
+type commandFlags struct {
+	*flag.FlagSet
+	args []string
+}
+
+func newFlags(name, argsUsage string) *commandFlags {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "用法: ldapctl %s [参数] %s\n", name, argsUsage)
+		fs.PrintDefaults()
+	}
+	return &commandFlags{FlagSet: fs}
+}
+
+// parse 解析参数，要求至少n个参数值
+func (f *commandFlags) parse(args []string, n int) error {
+	_ = f.Parse(args)
+	f.args = f.Args()
+	if len(f.args) < n {
+		f.Usage()
+		return fmt.Errorf("%s requires at least %d argument(s)", f.Name(), n)
+	}
+	return nil
+}
+
+// splitAttributes 解析以逗号分隔的属性列表
+func splitAttributes(attributes string) []string {
+	var result []string
+	for _, attr := range strings.Split(attributes, ",") {
+		if attr = strings.TrimSpace(attr); attr != "" {
+			result = append(result, attr)
+		}
+	}
+	return result
+}
+
+func userGet(c *ctl, args []string) error {
+	f := newFlags("user get", "<user_id>")
+	idType := f.String("id-type", "sAMAccountName", "user_id对应的LDAP属性")
+	searchBase := f.String("search-base", "", "搜索的起始DN")
+	attributes := f.String("attributes", "", "需要获取的属性，以逗号分隔")
+	if err := f.parse(args, 1); err != nil {
+		return err
+	}
+
+	attrs := splitAttributes(*attributes)
+	user, err := c.backend.GetUser(c.ctx, f.args[0], *idType, *searchBase, attrs...)
+	if err != nil {
+		return err
+	}
+	columns := userColumns
+	if len(attrs) > 0 {
+		columns = attrs
+	}
+	return c.printer.printObject(columns, ldap.ProjectObject(&user, attrs))
+}
+
+func userCreate(c *ctl, args []string) error {
+	f := newFlags("user create", "<sAMAccountName>")
+	ou := f.String("ou", "", "用户所在OU的DN")
+	displayName := f.String("display-name", "", "显示名称")
+	password := f.String("password", "", "初始密码，为 - 时从标准输入读取")
+	domain := f.String("domain", "", "用户主域名")
+	dryRun := f.Bool("dry-run", false, "只检查用户名是否可用，不创建用户")
+	if err := f.parse(args, 1); err != nil {
+		return err
+	}
+	if *ou == "" || *domain == "" {
+		return fmt.Errorf("--ou and --domain are required")
+	}
+
+	dn := fmt.Sprintf("CN=%s,%s", f.args[0], *ou)
+	if *dryRun {
+		ok, obj, err := c.backend.CheckAvailability(c.ctx, f.args[0])
+		if err != nil {
+			return err
+		}
+		result := "would create"
+		if !ok {
+			result = fmt.Sprintf("name has been used by %s", obj.DistinguishedName)
+		}
+		return c.printer.printRows(planColumns, [][]string{{"create", dn, result}})
+	}
+
+	pwd, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+	user, err := c.backend.CreateUser(c.ctx, client.NewUser{
+		SAMAccountName: f.args[0],
+		DisplayName:    *displayName,
+		OU:             *ou,
+		Password:       pwd,
+		PrimaryDomain:  *domain,
+	})
+	if err != nil {
+		return err
+	}
+	return c.printer.printObject(userColumns, &user)
+}
+
+func userDisable(c *ctl, args []string) error {
+	f := newFlags("user disable", "<user_id>")
+	idType := f.String("id-type", "sAMAccountName", "user_id对应的LDAP属性")
+	searchBase := f.String("search-base", "", "搜索的起始DN")
+	dryRun := f.Bool("dry-run", false, "只输出将要执行的操作")
+	if err := f.parse(args, 1); err != nil {
+		return err
+	}
+
+	user, err := c.backend.GetUser(c.ctx, f.args[0], *idType, *searchBase)
+	if err != nil {
+		return err
+	}
+	uac, err := strconv.Atoi(user.UserAccountControl)
+	if err != nil {
+		return fmt.Errorf("invalid userAccountControl '%s' of %s", user.UserAccountControl, user.DistinguishedName)
+	}
+	if uac&accountDisable != 0 {
+		return c.printer.printRows(planColumns, [][]string{{"disable", user.DistinguishedName, "already disabled"}})
+	}
+	if *dryRun {
+		return c.printer.printRows(planColumns, [][]string{{"disable", user.DistinguishedName, "would disable"}})
+	}
+
+	// 使用读取时的版本，避免覆盖期间其他人对userAccountControl的修改
+	change := ldap.AttrChange{Op: ldap.OpReplace, Attribute: "userAccountControl", Values: []string{strconv.Itoa(uac | accountDisable)}}
+	if _, err = c.backend.UpdateUser(c.ctx, user.DistinguishedName, "distinguishedName", "", user.USNChanged, change); err != nil {
+		return err
+	}
+	return c.printer.printRows(planColumns, [][]string{{"disable", user.DistinguishedName, "disabled"}})
+}
+
+func userResetPassword(c *ctl, args []string) error {
+	f := newFlags("user reset-password", "<user_id>")
+	idType := f.String("id-type", "sAMAccountName", "user_id对应的LDAP属性")
+	searchBase := f.String("search-base", "", "搜索的起始DN")
+	password := f.String("password", "", "新密码，为 - 时从标准输入读取")
+	dryRun := f.Bool("dry-run", false, "只检查用户是否存在，不修改密码")
+	if err := f.parse(args, 1); err != nil {
+		return err
+	}
+
+	user, err := c.backend.GetUser(c.ctx, f.args[0], *idType, *searchBase, "distinguishedName", "uSNChanged")
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		return c.printer.printRows(planColumns, [][]string{{"reset-password", user.DistinguishedName, "would reset"}})
+	}
+
+	pwd, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+	if err = c.backend.SetPassword(c.ctx, user.DistinguishedName, "distinguishedName", "", pwd, user.USNChanged); err != nil {
+		return err
+	}
+	return c.printer.printRows(planColumns, [][]string{{"reset-password", user.DistinguishedName, "reset"}})
+}
+
+// readPassword 为 - 时从标准输入读取一行作为密码，避免密码出现在命令历史中
+func readPassword(password string) (string, error) {
+	if password != "-" {
+		if password == "" {
+			return "", fmt.Errorf("--password is required")
+		}
+		return password, nil
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if line = strings.TrimRight(line, "\r\n"); line == "" {
+		return "", fmt.Errorf("empty password from stdin")
+	}
+	return line, nil
+}
+
+func groupGet(c *ctl, args []string) error {
+	f := newFlags("group get", "<group_id>")
+	idType := f.String("id-type", "sAMAccountName", "group_id对应的LDAP属性")
+	searchBase := f.String("search-base", "", "搜索的起始DN")
+	attributes := f.String("attributes", "", "需要获取的属性，以逗号分隔")
+	members := f.Bool("members", false, "输出成员列表")
+	if err := f.parse(args, 1); err != nil {
+		return err
+	}
+
+	attrs := splitAttributes(*attributes)
+	if *members {
+		attrs = []string{"member"}
+	}
+	group, err := c.backend.GetGroup(c.ctx, f.args[0], *idType, *searchBase, attrs...)
+	if err != nil {
+		return err
+	}
+
+	if *members {
+		rows := make([][]string, 0, len(group.Member))
+		for _, member := range group.Member {
+			rows = append(rows, []string{member})
+		}
+		return c.printer.printRows([]string{"member"}, rows)
+	}
+	columns := groupColumns
+	if len(attrs) > 0 {
+		columns = attrs
+	}
+	return c.printer.printObject(columns, ldap.ProjectObject(&group, attrs))
+}
+
+func groupAddMember(c *ctl, args []string) error {
+	return updateMembers(c, "add-member", args)
+}
+
+func groupRemoveMember(c *ctl, args []string) error {
+	return updateMembers(c, "remove-member", args)
+}
+
+// updateMembers 添加或移除群组成员，dry-run时只解析成员
+func updateMembers(c *ctl, action string, args []string) error {
+	f := newFlags("group "+action, "<group_id> <member_id>...")
+	idType := f.String("id-type", "sAMAccountName", "group_id对应的LDAP属性")
+	memberIdType := f.String("member-id-type", "sAMAccountName", "member_id对应的LDAP属性")
+	searchBase := f.String("search-base", "", "搜索的起始DN")
+	dryRun := f.Bool("dry-run", false, "只解析成员，不修改群组")
+	if err := f.parse(args, 2); err != nil {
+		return err
+	}
+	groupId, members := f.args[0], f.args[1:]
+
+	if *dryRun {
+		group, err := c.backend.GetGroup(c.ctx, groupId, *idType, *searchBase, "distinguishedName")
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(members))
+		for _, member := range members {
+			obj, err := c.backend.GetMember(c.ctx, member, *memberIdType, *searchBase)
+			if err != nil {
+				rows = append(rows, []string{action, member, err.Error()})
+			} else {
+				rows = append(rows, []string{action, obj.DistinguishedName, "would " + strings.TrimSuffix(action, "-member") + " to " + group.DistinguishedName})
+			}
+		}
+		return c.printer.printRows(planColumns, rows)
+	}
+
+	var add, remove []string
+	if action == "add-member" {
+		add = members
+	} else {
+		remove = members
+	}
+	_, failures, err := c.backend.UpdateGroupMembers(c.ctx, groupId, *idType, *memberIdType, *searchBase, "", add, remove)
+	if err != nil {
+		return err
+	}
+	return printFailures(c, action, failures, len(members))
+}
+
+// printFailures 输出成员变更结果，存在失败时返回错误
+func printFailures(c *ctl, action string, failures []string, total int) error {
+	rows := [][]string{{action, fmt.Sprintf("%d member(s)", total), "done"}}
+	for _, failure := range failures {
+		rows = append(rows, []string{action, "", failure})
+	}
+	if err := c.printer.printRows(planColumns, rows); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d member change(s) failed", len(failures))
+	}
+	return nil
+}
+
+func groupSync(c *ctl, args []string) error {
+	f := newFlags("group sync", "<group_id> [member_id...]")
+	idType := f.String("id-type", "sAMAccountName", "group_id对应的LDAP属性")
+	memberIdType := f.String("member-id-type", "sAMAccountName", "member_id对应的LDAP属性")
+	searchBase := f.String("search-base", "", "搜索的起始DN")
+	file := f.String("file", "", "成员列表文件，每行一个成员，为 - 时从标准输入读取")
+	dryRun := f.Bool("dry-run", false, "只输出需要添加、移除的成员")
+	if err := f.parse(args, 1); err != nil {
+		return err
+	}
+
+	targets := f.args[1:]
+	if *file != "" {
+		lines, err := readLines(*file)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, lines...)
+	}
+
+	group, err := c.backend.GetGroup(c.ctx, f.args[0], *idType, *searchBase)
+	if err != nil {
+		return err
+	}
+
+	// 任何目标成员无法解析时都不做修改，避免误删成员
+	var targetDNs, unresolved []string
+	for _, target := range targets {
+		obj, err := c.backend.GetMember(c.ctx, target, *memberIdType, *searchBase)
+		if err != nil {
+			unresolved = append(unresolved, fmt.Sprintf("%s: %v", target, err))
+			continue
+		}
+		targetDNs = append(targetDNs, obj.DistinguishedName)
+	}
+	if len(unresolved) > 0 {
+		return fmt.Errorf("failed to resolve members, nothing changed:\n  %s", strings.Join(unresolved, "\n  "))
+	}
+
+	var add, remove []string
+	var rows [][]string
+	for _, dn := range targetDNs {
+		if !utils.InSliceIC(group.Member, dn) && !utils.InSliceIC(add, dn) {
+			add = append(add, dn)
+			rows = append(rows, []string{"add", dn, "pending"})
+		}
+	}
+	for _, dn := range group.Member {
+		if !utils.InSliceIC(targetDNs, dn) {
+			remove = append(remove, dn)
+			rows = append(rows, []string{"remove", dn, "pending"})
+		}
+	}
+	if *dryRun || len(rows) == 0 {
+		return c.printer.printRows(planColumns, rows)
+	}
+
+	// 以读取成员时的版本执行变更，期间群组被修改时整体失败
+	_, failures, err := c.backend.UpdateGroupMembers(c.ctx, group.DistinguishedName, "distinguishedName", "distinguishedName", "", group.USNChanged, add, remove)
+	if err != nil {
+		return err
+	}
+	return printFailures(c, "sync", failures, len(rows))
+}
+
+// readLines 读取非空且不以#开头的行
+func readLines(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func availability(c *ctl, args []string) error {
+	f := newFlags("availability", "<name>")
+	if err := f.parse(args, 1); err != nil {
+		return err
+	}
+
+	ok, obj, err := c.backend.CheckAvailability(c.ctx, f.args[0])
+	if err != nil {
+		return err
+	}
+	usedBy := ""
+	if obj != nil {
+		usedBy = obj.DistinguishedName
+	}
+	return c.printer.printRows([]string{"name", "available", "used_by"}, [][]string{{f.args[0], strconv.FormatBool(ok), usedBy}})
+}
+
+func search(c *ctl, args []string) error {
+	f := newFlags("search", "")
+	objType := f.String("type", "user", "对象类型：user、group")
+	filter := f.String("filter", "", "附加的LDAP过滤条件，例如 (department=IT)")
+	searchBase := f.String("search-base", "", "搜索的起始DN")
+	attributes := f.String("attributes", "", "需要获取的属性，以逗号分隔")
+	if err := f.parse(args, 0); err != nil {
+		return err
+	}
+
+	attrs := splitAttributes(*attributes)
+	var items []interface{}
+	var err error
+	columns := userColumns
+	switch *objType {
+	case "user":
+		err = c.backend.SearchUsers(c.ctx, *filter, *searchBase, attrs, func(user *ldap.User) error {
+			items = append(items, ldap.ProjectObject(user, attrs))
+			return nil
+		})
+	case "group":
+		columns = groupColumns
+		err = c.backend.SearchGroups(c.ctx, *filter, *searchBase, attrs, func(group *ldap.Group) error {
+			items = append(items, ldap.ProjectObject(group, attrs))
+			return nil
+		})
+	default:
+		return fmt.Errorf("unsupported object type '%s'", *objType)
+	}
+	if err != nil {
+		return err
+	}
+	if len(attrs) > 0 {
+		columns = attrs
+	}
+	return c.printer.printList(columns, items)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"ldap-http-service/client"
+	"ldap-http-service/config"
+	"ldap-http-service/lib/logger"
+	"ldap-http-service/lib/utils"
+	"os"
+	"strings"
+	"time"
+)
+
+// ctl 命令执行时的上下文
+type ctl struct {
+	ctx     context.Context
+	backend backend
+	printer *printer
+}
+
+// command 子命令，name为以空格分隔的命令路径
+type command struct {
+	name  string
+	usage string
+	run   func(c *ctl, args []string) error
+}
+
+func usage(commands []command) {
+	_, _ = fmt.Fprint(os.Stderr, `ldapctl 通过ldap-http-service或直接连接LDAP执行日常运维操作
+
+用法: ldapctl [全局参数] <命令> [命令参数] [参数值...]
+
+全局参数:
+  --server     服务地址，默认读取环境变量LDAPCTL_SERVER，未设置时为 http://127.0.0.1:8080
+  --direct     不经过服务，使用与服务相同的LDAP_*环境变量直接连接LDAP
+  -o, --output 输出格式：table、json、csv，默认为table
+  --verbose    直接连接LDAP时输出调试日志
+
+命令:
+`)
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(os.Stderr, "  %-22s %s\n", cmd.name, cmd.usage)
+	}
+	_, _ = fmt.Fprintln(os.Stderr, "\n命令参数需要写在参数值之前，使用 ldapctl <命令> -h 查看命令参数")
+}
+
+func main() {
+	commands := allCommands()
+
+	global := flag.NewFlagSet("ldapctl", flag.ExitOnError)
+	global.Usage = func() { usage(commands) }
+	server := global.String("server", os.Getenv("LDAPCTL_SERVER"), "")
+	direct := global.Bool("direct", false, "")
+	verbose := global.Bool("verbose", false, "")
+	output := global.String("output", outputTable, "")
+	global.StringVar(output, "o", outputTable, "")
+	_ = global.Parse(os.Args[1:])
+
+	if *output != outputTable && *output != outputJSON && *output != outputCSV {
+		exit(fmt.Errorf("unsupported output format '%s'", *output))
+	}
+
+	c := &ctl{
+		ctx:     client.WithTraceID(context.Background(), utils.GenUuid("ctl")),
+		printer: &printer{w: os.Stdout, format: *output},
+	}
+	if *direct {
+		if err := config.Load(); err != nil {
+			exit(fmt.Errorf("failed to load config: %v", err))
+		}
+		// 日志输出到stderr，默认只输出警告以上的日志，避免干扰命令输出
+		if !*verbose {
+			logger.LdapLogger.Logger.SetLevel(logrus.WarnLevel)
+		}
+		c.backend = directBackend{}
+	} else {
+		if *server == "" {
+			*server = "http://127.0.0.1:8080"
+		}
+		c.backend = client.New(*server, client.WithRetry(2, time.Second))
+	}
+
+	// 按最长匹配查找子命令
+	args := global.Args()
+	for n := 2; n >= 1; n-- {
+		if len(args) < n {
+			continue
+		}
+		name := strings.Join(args[:n], " ")
+		for _, cmd := range commands {
+			if cmd.name == name {
+				if err := cmd.run(c, args[n:]); err != nil {
+					exit(err)
+				}
+				return
+			}
+		}
+	}
+	usage(commands)
+	os.Exit(2)
+}
+
+func exit(err error) {
+	_, _ = fmt.Fprintf(os.Stderr, "ldapctl: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// 输出格式
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+// printer 按输出格式打印对象，table和csv按columns输出列，json输出完整对象
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// 将对象转换为属性名到字符串值的map，多值属性以分号连接
+func flatten(obj interface{}) map[string]string {
+	data, _ := json.Marshal(obj)
+	var values map[string]interface{}
+	_ = json.Unmarshal(data, &values)
+
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			result[key] = v
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			result[key] = strings.Join(items, ";")
+		default:
+			raw, _ := json.Marshal(v)
+			result[key] = string(raw)
+		}
+	}
+	return result
+}
+
+// printList 打印对象列表，json格式输出为数组
+func (p *printer) printList(columns []string, items []interface{}) error {
+	if p.format == outputJSON {
+		if items == nil {
+			items = []interface{}{}
+		}
+		return p.printJSON(items)
+	}
+
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		values := flatten(item)
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			row = append(row, values[column])
+		}
+		rows = append(rows, row)
+	}
+	return p.printRows(columns, rows)
+}
+
+// printObject 打印单个对象，table格式为每行一个属性，未指定columns时输出全部非空属性
+func (p *printer) printObject(columns []string, obj interface{}) error {
+	switch p.format {
+	case outputJSON:
+		return p.printJSON(obj)
+	case outputCSV:
+		return p.printList(columns, []interface{}{obj})
+	}
+
+	values := flatten(obj)
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	for _, column := range columns {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", column, values[column])
+	}
+	return tw.Flush()
+}
+
+// printRows 直接按行打印，用于变更计划等非对象的输出
+func (p *printer) printRows(columns []string, rows [][]string) error {
+	switch p.format {
+	case outputJSON:
+		items := make([]map[string]string, 0, len(rows))
+		for _, row := range rows {
+			item := make(map[string]string, len(columns))
+			for i, column := range columns {
+				item[column] = row[i]
+			}
+			items = append(items, item)
+		}
+		return p.printJSON(items)
+	case outputCSV:
+		writer := csv.NewWriter(p.w)
+		_ = writer.Write(columns)
+		_ = writer.WriteAll(rows)
+		return writer.Error()
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) printJSON(value interface{}) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
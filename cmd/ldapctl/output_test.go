@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type testObject struct {
+	Name    string   `json:"name"`
+	Members []string `json:"member"`
+	Count   int      `json:"count"`
+	Enabled bool     `json:"enabled"`
+	Manager *string  `json:"manager"`
+}
+
+func TestFlatten(t *testing.T) {
+	got := flatten(testObject{Name: "sales", Members: []string{"a", "b"}, Count: 2, Enabled: true})
+	want := map[string]string{"name": "sales", "member": "a;b", "count": "2", "enabled": "true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flatten() = %v, want %v", got, want)
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	items := []interface{}{
+		testObject{Name: "sales", Members: []string{"a", "b"}, Count: 2},
+		testObject{Name: "hr, east", Count: 0},
+	}
+	tests := []struct {
+		name   string
+		format string
+		print  func(p *printer) error
+		want   string
+	}{
+		{
+			name:   "table list",
+			format: outputTable,
+			print:  func(p *printer) error { return p.printList([]string{"name", "member"}, items) },
+			want:   "NAME      MEMBER\nsales     a;b\nhr, east  \n",
+		},
+		{
+			name:   "csv list quotes values",
+			format: outputCSV,
+			print:  func(p *printer) error { return p.printList([]string{"name", "count"}, items) },
+			want:   "name,count\nsales,2\n\"hr, east\",0\n",
+		},
+		{
+			name:   "empty json list",
+			format: outputJSON,
+			print:  func(p *printer) error { return p.printList([]string{"name"}, nil) },
+			want:   "[]\n",
+		},
+		{
+			name:   "table object",
+			format: outputTable,
+			print:  func(p *printer) error { return p.printObject([]string{"name", "count"}, items[0]) },
+			want:   "name   sales\ncount  2\n",
+		},
+		{
+			name:   "csv object",
+			format: outputCSV,
+			print:  func(p *printer) error { return p.printObject([]string{"name"}, items[0]) },
+			want:   "name\nsales\n",
+		},
+		{
+			name:   "json rows",
+			format: outputJSON,
+			print:  func(p *printer) error { return p.printRows([]string{"op", "dn"}, [][]string{{"add", "CN=a"}}) },
+			want:   "[\n  {\n    \"dn\": \"CN=a\",\n    \"op\": \"add\"\n  }\n]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.print(&printer{w: &buf, format: tt.format}); err != nil {
+				t.Fatalf("print error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
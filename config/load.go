@@ -7,14 +7,70 @@ import (
 )
 
 var (
-	LdapConfig ldapConfig
-	GinConfig  ginConfig
+	LdapConfig        ldapConfig
+	GinConfig         ginConfig
+	GrpcConfig        grpcConfig
+	IdempotencyConfig idempotencyConfig
+	BulkConfig        bulkConfig
+	ChangesConfig     changesConfig
+	WebhookConfig     webhookConfig
+	ExpiryConfig      expiryConfig
+	NamingConfig      namingConfig
+	TypeaheadConfig   typeaheadConfig
+	CacheConfig       cacheConfig
+	PhotoConfig       photoConfig
+	ScimConfig        scimConfig
+	OwnerConfig       ownerConfig
 )
 
+// Load 加载所有模块配置，用于直接连接LDAP的命令行工具，与服务使用相同的配置
+func Load() error {
+	err := envconfig.Process("ldap", &LdapConfig)
+	if err == nil {
+		err = envconfig.Process("gin", &GinConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("grpc", &GrpcConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("idempotency", &IdempotencyConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("bulk", &BulkConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("changes", &ChangesConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("webhook", &WebhookConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("scim", &ScimConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("owner", &OwnerConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("expiry", &ExpiryConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("naming", &NamingConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("typeahead", &TypeaheadConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("cache", &CacheConfig)
+	}
+	if err == nil {
+		err = envconfig.Process("photo", &PhotoConfig)
+	}
+	return err
+}
+
 // LoadConfig 项目模块配置加载，用于项目启动时从yaml中加载所有配置信息
 func LoadConfig() {
-	err := envconfig.Process("ldap", &LdapConfig)
-	err = envconfig.Process("gin", &GinConfig)
+	err := Load()
 	fmt.Println(LdapConfig)
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
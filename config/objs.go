@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type ldapConfig struct {
 	Host     string
 	Port     int
@@ -15,3 +17,95 @@ type ginConfig struct {
 	Listen string
 	Port   int
 }
+
+type grpcConfig struct {
+	Enabled bool   `default:"false"`
+	Listen  string `default:"0.0.0.0"`
+	Port    int    `default:"9090"`
+}
+
+type bulkConfig struct {
+	Path          string        `default:"data/jobs"`
+	Concurrency   int           `default:"4"`
+	MaxOperations int           `default:"1000"`
+	Retention     time.Duration `default:"168h"`
+	SaveInterval  time.Duration `default:"1s"`
+}
+
+type changesConfig struct {
+	Enabled   bool          `default:"false"`
+	Path      string        `default:"data/changes.json"`
+	Interval  time.Duration `default:"30s"`
+	Retention int           `default:"10000"`
+}
+
+type cacheConfig struct {
+	Enabled    bool          `default:"false"`
+	TTL        time.Duration `default:"1m"`
+	MaxEntries int           `default:"10000"`
+	// 是否根据目录变更跟踪的事件使缓存失效，需要同时启用变更跟踪；未跟踪的外部变更最多在TTL后生效
+	FollowChanges bool `default:"true"`
+}
+
+type typeaheadConfig struct {
+	SizeLimit int           `default:"100"`
+	CacheTTL  time.Duration `default:"30s"`
+	CacheSize int           `default:"1000"`
+	// 搜索词的最小字符数，过短的搜索词会匹配目录中的大部分对象
+	MinQueryLength int `default:"2"`
+}
+
+type photoConfig struct {
+	// AD中thumbnailPhoto的大小上限为100KB
+	MaxBytes       int   `default:"102400"`
+	MaxDimension   int   `default:"256"`
+	MaxUploadBytes int64 `default:"10485760"`
+}
+
+type namingConfig struct {
+	// 用户名模板，{first}、{last}为名、姓的全拼，{f}、{l}为名、姓各音节的首字母，{n}为数字后缀
+	Patterns  []string `default:"{first}.{last},{f}{last},{first}{l},{first}.{last}{n}"`
+	MaxSuffix int      `default:"99"`
+}
+
+type expiryConfig struct {
+	Path     string        `default:"data/expirations.json"`
+	Interval time.Duration `default:"1m"`
+}
+
+type webhookConfig struct {
+	Path          string        `default:"data/webhooks"`
+	MaxAttempts   int           `default:"10"`
+	Timeout       time.Duration `default:"10s"`
+	Workers       int           `default:"4"`
+	QueueSize     int           `default:"1000"`
+	DeadRetention time.Duration `default:"168h"`
+	// 允许的webhook目标主机，支持 "*.example.com" 形式的后缀匹配，为空时不限制
+	AllowedHosts []string
+	// 允许投递到回环、链路本地等地址，仅用于测试
+	AllowLocal bool `default:"false"`
+}
+
+type scimConfig struct {
+	UserOU       string
+	GroupOU      string
+	UserNameAttr string `default:"sAMAccountName"`
+	BaseURL      string `default:"/scim/v2"`
+	MaxResults   int    `default:"200"`
+}
+
+type ownerConfig struct {
+	// 是否要求群组成员变更以所有者或共同管理者的身份认证，开启后HTTP、gRPC、SCIM及批量任务均不能以服务身份变更成员
+	RequireAuth bool `default:"false"`
+	// 认证成功后缓存的时间，期间相同的凭据不再重复绑定LDAP，为0时不缓存
+	AuthCacheTTL time.Duration `default:"1m"`
+	// 同一用户名在FailureWindow内认证失败MaxFailures次后，直到窗口结束前拒绝认证
+	MaxFailures   int           `default:"5"`
+	FailureWindow time.Duration `default:"5m"`
+}
+
+type idempotencyConfig struct {
+	Path       string        `default:"data/idempotency.json"`
+	TTL        time.Duration `default:"24h"`
+	MaxEntries int           `default:"10000"`
+}
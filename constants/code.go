@@ -1,7 +1,14 @@
 package constants
 
 const (
-	CodeInternalException = 1000
-	CodeObjAlreadyExists  = 68
-	CodeObjNotFound       = 96
+	CodeInternalException    = 1000
+	CodeIdempotencyKeyReused = 1001
+	CodeRequestInProgress    = 1002
+	CodeCursorExpired        = 1003
+	CodeValidationFailed     = 1004
+	CodeMembershipCycle      = 1005
+	CodeManagerCycle         = 1006
+	CodeObjAlreadyExists     = 68
+	CodeObjNotFound          = 96
+	CodePreconditionFailed   = 122
 )
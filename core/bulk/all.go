@@ -0,0 +1,70 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"ldap-http-service/config"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/logger"
+	"sync"
+)
+
+var (
+	manager     *jobManager
+	managerOnce sync.Once
+)
+
+// Init 初始化批量任务管理器，加载本地持久化的任务，并继续执行重启前未完成的任务
+func Init(tractx context.Context) (err error) {
+	managerOnce.Do(func() {
+		logger.LdapLogger.WithContext(tractx).Info("正在初始化批量任务管理器...")
+		manager = newJobManager(config.BulkConfig.Path, config.BulkConfig.Concurrency, config.BulkConfig.Retention, config.BulkConfig.SaveInterval)
+
+		var unfinished []*Job
+		unfinished, err = manager.load()
+		if err != nil {
+			return
+		}
+		for _, job := range unfinished {
+			logger.LdapLogger.WithContext(tractx).Infof("继续执行未完成的批量任务 `%s` ...", job.ID)
+			manager.start(job)
+		}
+	})
+	return
+}
+
+// SubmitJob 校验并提交批量任务，任务在后台执行，返回任务的当前状态
+func SubmitJob(tractx context.Context, ops []Operation) (*Job, error) {
+	if len(ops) == 0 {
+		return nil, &ers.BadRequestErr{Message: "operations cannot be empty"}
+	}
+	if len(ops) > config.BulkConfig.MaxOperations {
+		return nil, &ers.BadRequestErr{Message: fmt.Sprintf("too many operations: %d > %d", len(ops), config.BulkConfig.MaxOperations)}
+	}
+	for i := range ops {
+		if err := ops[i].validate(); err != nil {
+			return nil, &ers.BadRequestErr{Message: fmt.Sprintf("operations[%d]: %s", i, err.Error())}
+		}
+		// 配置要求成员变更必须认证时，以服务身份提交的成员变更在提交时即拒绝
+		if ops[i].Type == OpUpdateMembers {
+			if err := ldap.RequireCaller(tractx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("提交批量任务，共计 %d 项操作", len(ops))
+	return manager.submit(ops, ldap.CallerDN(tractx))
+}
+
+// GetJob 获取批量任务及其任务项的执行状态
+func GetJob(id string) (*Job, error) {
+	return manager.get(id)
+}
+
+// CancelJob 取消批量任务
+func CancelJob(tractx context.Context, id string) error {
+	logger.LdapLogger.WithContext(tractx).Infof("正在取消批量任务 `%s` ...", id)
+	return manager.cancel(id)
+}
@@ -0,0 +1,356 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/logger"
+	"ldap-http-service/lib/utils"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 任务及任务项的状态
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCompleted = "completed"
+	StatusCancelled = "cancelled"
+)
+
+// Item 批量任务中的单个任务项
+type Item struct {
+	Index     int       `json:"index"`
+	Operation Operation `json:"operation"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Job 批量任务
+type Job struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Items     []*Item   `json:"items"`
+	// 以owner模式提交任务的调用方DN，执行成员变更时以该身份校验权限
+	Caller string `json:"caller,omitempty"`
+}
+
+// jobManager 批量任务管理器，以有限的并发执行任务，并将任务状态持久化到本地目录中；
+// 任务项的状态变化按saveInterval批量保存，结束超过retention的任务被清理
+type jobManager struct {
+	path         string
+	concurrency  int
+	retention    time.Duration
+	saveInterval time.Duration
+	mutex        sync.Mutex
+	jobs         map[string]*Job
+	cancels      map[string]context.CancelFunc
+	// 状态已变化但尚未保存的任务
+	dirty map[string]bool
+	// 任务项的密码，提交时从操作中取出，仅保存在内存中，任务项执行结束后删除
+	passwords map[*Item]string
+}
+
+func newJobManager(path string, concurrency int, retention, saveInterval time.Duration) *jobManager {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if saveInterval <= 0 {
+		saveInterval = time.Second
+	}
+	return &jobManager{
+		path:         path,
+		concurrency:  concurrency,
+		retention:    retention,
+		saveInterval: saveInterval,
+		jobs:         make(map[string]*Job),
+		cancels:      make(map[string]context.CancelFunc),
+		dirty:        make(map[string]bool),
+		passwords:    make(map[*Item]string),
+	}
+}
+
+// 从本地目录中加载任务，返回需要继续执行的任务；重启前正在执行的任务项无法确认结果，标记为失败；
+// 密码不会持久化，尚未执行的需要密码的任务项在继续执行时标记为失败；
+// 状态按保存间隔批量保存，重启前最后一个间隔内执行完毕的任务项可能仍然记录为待执行而被再次执行
+func (m *jobManager) load() ([]*Job, error) {
+	files, err := filepath.Glob(filepath.Join(m.path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var unfinished []*Job
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var job Job
+		if err = json.Unmarshal(data, &job); err != nil {
+			return nil, err
+		}
+		m.jobs[job.ID] = &job
+
+		if job.Status != StatusPending && job.Status != StatusRunning {
+			continue
+		}
+		for _, item := range job.Items {
+			if item.Status == StatusRunning {
+				item.Status = StatusFailed
+				item.Error = "interrupted by service restart"
+			}
+		}
+		unfinished = append(unfinished, &job)
+	}
+
+	m.mutex.Lock()
+	m.prune()
+	m.mutex.Unlock()
+	return unfinished, nil
+}
+
+// 将任务写入本地文件，先写临时文件再重命名，避免写入中断导致文件损坏；调用方需持有锁
+func (m *jobManager) save(job *Job) error {
+	delete(m.dirty, job.ID)
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(m.path, 0o700); err != nil {
+		return err
+	}
+	file := filepath.Join(m.path, job.ID+".json")
+	if err = os.WriteFile(file+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// 记录任务状态的变化，由执行任务的协程按保存间隔批量保存；调用方需持有锁
+func (m *jobManager) touch(job *Job) {
+	job.UpdatedAt = time.Now()
+	m.dirty[job.ID] = true
+}
+
+// 删除结束超过保留时间的任务及其本地文件；调用方需持有锁
+func (m *jobManager) prune() {
+	if m.retention <= 0 {
+		return
+	}
+	for id, job := range m.jobs {
+		if _, running := m.cancels[id]; running || job.Status == StatusPending || job.Status == StatusRunning {
+			continue
+		}
+		if time.Since(job.UpdatedAt) <= m.retention {
+			continue
+		}
+		delete(m.jobs, id)
+		if err := os.Remove(filepath.Join(m.path, id+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.LdapLogger.Errorf("删除过期的批量任务 `%s` 失败: %v", id, err)
+		}
+	}
+}
+
+// 创建任务并持久化，随后在后台执行；操作中的密码在任务保存前取出，任务本身不包含明文密码
+func (m *jobManager) submit(ops []Operation, caller string) (*Job, error) {
+	job := &Job{
+		ID:        utils.GenUuid("job"),
+		Status:    StatusPending,
+		CreatedAt: time.Now(),
+		Caller:    caller,
+	}
+	job.UpdatedAt = job.CreatedAt
+	passwords := make(map[*Item]string)
+	for i, op := range ops {
+		item := &Item{Index: i, Operation: op, Status: StatusPending}
+		if op.User != nil {
+			// 复制NewUser，避免清除密码时修改调用方的数据
+			user := *op.User
+			item.Operation.User = &user
+		}
+		if password := item.Operation.takePassword(); password != "" {
+			passwords[item] = password
+		}
+		job.Items = append(job.Items, item)
+	}
+
+	m.mutex.Lock()
+	m.prune()
+	for item, password := range passwords {
+		m.passwords[item] = password
+	}
+	m.jobs[job.ID] = job
+	err := m.save(job)
+	m.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.start(job)
+	return m.get(job.ID)
+}
+
+// 在后台以有限并发执行任务中所有待执行的任务项，任务项按顺序分发给固定数量的执行协程
+func (m *jobManager) start(job *Job) {
+	ctx, cancel := context.WithCancel(context.Background())
+	// 任务执行过程中的日志使用任务ID作为trace_id
+	ctx = context.WithValue(ctx, "trace_id", job.ID)
+	ctx = context.WithValue(ctx, "opt", "执行LDAP批量任务")
+	ctx = ldap.WithoutCache(ctx)
+	if job.Caller != "" {
+		ctx = context.WithValue(ctx, ldap.CallerKey, job.Caller)
+	}
+
+	m.mutex.Lock()
+	m.cancels[job.ID] = cancel
+	job.Status = StatusRunning
+	m.touch(job)
+	_ = m.save(job)
+	m.mutex.Unlock()
+
+	go func() {
+		defer cancel()
+		logger.LdapLogger.WithContext(ctx).Infof("开始执行批量任务，共计 %d 项", len(job.Items))
+
+		done := make(chan struct{})
+		go m.flush(ctx, job, done)
+
+		items := make(chan *Item)
+		var wg sync.WaitGroup
+		for i := 0; i < m.concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for item := range items {
+					m.runItem(ctx, job, item)
+				}
+			}()
+		}
+		for _, item := range job.Items {
+			if item.Status == StatusPending {
+				items <- item
+			}
+		}
+		close(items)
+		wg.Wait()
+		close(done)
+
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		if ctx.Err() != nil {
+			job.Status = StatusCancelled
+		} else {
+			job.Status = StatusCompleted
+		}
+		delete(m.cancels, job.ID)
+		m.touch(job)
+		if err := m.save(job); err != nil {
+			logger.LdapLogger.WithContext(ctx).Errorf("保存批量任务状态失败: %v", err)
+		}
+		logger.LdapLogger.WithContext(ctx).Infof("批量任务执行结束，状态: %s", job.Status)
+	}()
+}
+
+// 按保存间隔保存执行中任务的状态变化，直到done被关闭
+func (m *jobManager) flush(ctx context.Context, job *Job, done <-chan struct{}) {
+	ticker := time.NewTicker(m.saveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			m.mutex.Lock()
+			if m.dirty[job.ID] {
+				if err := m.save(job); err != nil {
+					logger.LdapLogger.WithContext(ctx).Errorf("保存批量任务状态失败: %v", err)
+				}
+			}
+			m.mutex.Unlock()
+		}
+	}
+}
+
+// 执行单个任务项，任务已取消时标记为已取消
+func (m *jobManager) runItem(ctx context.Context, job *Job, item *Item) {
+	if ctx.Err() != nil {
+		m.finishItem(job, item, StatusCancelled, nil)
+		return
+	}
+
+	m.mutex.Lock()
+	password, ok := m.passwords[item]
+	if !ok && item.Operation.needsPassword() {
+		m.mutex.Unlock()
+		m.finishItem(job, item, StatusFailed, errors.New("password is not persisted across service restarts, please resubmit the operation"))
+		return
+	}
+	item.Status = StatusRunning
+	m.touch(job)
+	m.mutex.Unlock()
+
+	if err := item.Operation.execute(ctx, password); err != nil {
+		m.finishItem(job, item, StatusFailed, err)
+	} else {
+		m.finishItem(job, item, StatusSucceeded, nil)
+	}
+}
+
+// 记录任务项的执行结果，并删除内存中保存的密码
+func (m *jobManager) finishItem(job *Job, item *Item, status string, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	item.Status = status
+	if err != nil {
+		item.Error = err.Error()
+	}
+	delete(m.passwords, item)
+	m.touch(job)
+}
+
+// 获取任务的副本，避免调用方读取时与后台执行产生竞争
+func (m *jobManager) get(id string) (*Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, &ers.NotFoundError{Object: "job=" + id}
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot Job
+	err = json.Unmarshal(data, &snapshot)
+	return &snapshot, err
+}
+
+// 取消任务，已开始执行的任务项会继续执行完毕，尚未执行的任务项标记为已取消
+func (m *jobManager) cancel(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return &ers.NotFoundError{Object: "job=" + id}
+	}
+	cancel, running := m.cancels[id]
+	if !running {
+		return &ers.ForbiddenErr{Message: fmt.Sprintf("job '%s' is already %s", id, job.Status)}
+	}
+	cancel()
+	return nil
+}
@@ -0,0 +1,94 @@
+package bulk
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPrune(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	tests := []struct {
+		name   string
+		job    Job
+		cancel bool
+		kept   bool
+	}{
+		{name: "recent completed job", job: Job{Status: StatusCompleted, UpdatedAt: time.Now()}, kept: true},
+		{name: "old completed job", job: Job{Status: StatusCompleted, UpdatedAt: old}},
+		{name: "old cancelled job", job: Job{Status: StatusCancelled, UpdatedAt: old}},
+		{name: "old pending job", job: Job{Status: StatusPending, UpdatedAt: old}, kept: true},
+		{name: "old running job", job: Job{Status: StatusRunning, UpdatedAt: old}, kept: true},
+		{name: "job still being cancelled", job: Job{Status: StatusCompleted, UpdatedAt: old}, cancel: true, kept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newJobManager(t.TempDir(), 1, 24*time.Hour, time.Second)
+			job := tt.job
+			job.ID = "job-1"
+			m.jobs[job.ID] = &job
+			if err := m.save(&job); err != nil {
+				t.Fatal(err)
+			}
+			if tt.cancel {
+				m.cancels[job.ID] = func() {}
+			}
+
+			m.prune()
+			_, kept := m.jobs[job.ID]
+			_, statErr := os.Stat(filepath.Join(m.path, job.ID+".json"))
+			if kept != tt.kept || (statErr == nil) != tt.kept {
+				t.Errorf("kept in memory = %v, file exists = %v, want %v", kept, statErr == nil, tt.kept)
+			}
+		})
+	}
+}
+
+func TestStartResumedJob(t *testing.T) {
+	m := newJobManager(t.TempDir(), 2, time.Hour, 10*time.Millisecond)
+
+	// 重启后继续执行的任务中，需要密码的任务项因密码未持久化而直接失败，不会访问LDAP
+	job := &Job{ID: "job-1", Status: StatusPending, CreatedAt: time.Now()}
+	for i := 0; i < 5; i++ {
+		job.Items = append(job.Items, &Item{Index: i, Operation: Operation{Type: OpSetPassword, ID: "alice"}, Status: StatusPending})
+	}
+	job.Items = append(job.Items, &Item{Index: 5, Operation: Operation{Type: OpSetPassword}, Status: StatusSucceeded})
+	m.jobs[job.ID] = job
+	m.start(job)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		snapshot, err := m.get(job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snapshot.Status == StatusCompleted {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not finish, status = %s", snapshot.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.path, job.ID+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Job
+	if err = json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{StatusFailed, StatusFailed, StatusFailed, StatusFailed, StatusFailed, StatusSucceeded}
+	for i, item := range saved.Items {
+		if item.Status != want[i] {
+			t.Errorf("items[%d].Status = %s, want %s", i, item.Status, want[i])
+		}
+	}
+	if saved.Status != StatusCompleted || len(m.dirty) != 0 {
+		t.Errorf("saved status = %s, dirty = %v", saved.Status, m.dirty)
+	}
+}
@@ -0,0 +1,163 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"strings"
+)
+
+// 批量操作类型
+const (
+	OpCreateUser    = "create_user"
+	OpUpdateUser    = "update_user"
+	OpSetPassword   = "set_password"
+	OpUpdateMembers = "update_members"
+)
+
+// NewUser 创建启用用户所需的信息
+type NewUser struct {
+	SAMAccountName string `json:"sAMAccountName"`
+	DisplayName    string `json:"displayName"`
+	OU             string `json:"OU"`
+	Password       string `json:"password"`
+	PrimaryDomain  string `json:"primaryDomain"`
+}
+
+// Operation 批量任务中的单个操作，根据Type使用不同的字段
+type Operation struct {
+	Type string `json:"type"`
+
+	// 目标用户或群组，用于update_user、set_password、update_members
+	ID     string `json:"id"`
+	IDType string `json:"id_type"`
+
+	// create_user
+	User *NewUser `json:"user,omitempty"`
+
+	// update_user
+	Changes []ldap.AttrChange `json:"changes,omitempty"`
+
+	// set_password
+	Password string `json:"password,omitempty"`
+
+	// update_members
+	MemberIDType  string   `json:"member_id_type,omitempty"`
+	AddMembers    []string `json:"add_members,omitempty"`
+	RemoveMembers []string `json:"remove_members,omitempty"`
+}
+
+// validate 校验操作的必填字段
+func (op *Operation) validate() error {
+	switch op.Type {
+	case OpCreateUser:
+		if op.User == nil || op.User.SAMAccountName == "" || op.User.OU == "" || op.User.Password == "" {
+			return fmt.Errorf("%s requires user.sAMAccountName, user.OU and user.password", op.Type)
+		}
+		return nil
+	case OpUpdateUser:
+		if len(op.Changes) == 0 {
+			return fmt.Errorf("%s requires changes", op.Type)
+		}
+	case OpSetPassword:
+		if op.Password == "" {
+			return fmt.Errorf("%s requires password", op.Type)
+		}
+	case OpUpdateMembers:
+		if len(op.AddMembers) == 0 && len(op.RemoveMembers) == 0 {
+			return fmt.Errorf("%s requires add_members or remove_members", op.Type)
+		}
+		if op.MemberIDType == "" {
+			return fmt.Errorf("%s requires member_id_type", op.Type)
+		}
+	default:
+		return fmt.Errorf("unsupported operation type '%s'", op.Type)
+	}
+
+	if op.ID == "" || op.IDType == "" {
+		return fmt.Errorf("%s requires id and id_type", op.Type)
+	}
+	return nil
+}
+
+// needsPassword 操作执行时是否需要密码
+func (op *Operation) needsPassword() bool {
+	return op.Type == OpCreateUser || op.Type == OpSetPassword
+}
+
+// takePassword 取出并清除操作中的密码，密码只保存在内存中，不随任务持久化或返回给调用方
+func (op *Operation) takePassword() string {
+	password := op.Password
+	op.Password = ""
+	if op.User != nil {
+		if op.Type == OpCreateUser {
+			password = op.User.Password
+		}
+		op.User.Password = ""
+	}
+	return password
+}
+
+// execute 执行单个操作，password为提交任务时从操作中取出的密码
+func (op *Operation) execute(tractx context.Context, password string) error {
+	switch op.Type {
+	case OpCreateUser:
+		return ldap.CreateEnabledUser(tractx, op.User.SAMAccountName, op.User.DisplayName, op.User.OU, password, op.User.PrimaryDomain)
+	case OpUpdateUser:
+		user, err := ldap.GetUser(tractx, op.ID, op.IDType, "")
+		if err != nil {
+			return err
+		}
+		return ldap.ApplyChanges(tractx, user.DistinguishedName, "", op.Changes)
+	case OpSetPassword:
+		return ldap.SetUserPwd(tractx, op.ID, op.IDType, password, "", "")
+	case OpUpdateMembers:
+		return op.updateMembers(tractx)
+	default:
+		return &ers.UnSupportedErr{Object: op.Type, ObjectType: "operation type"}
+	}
+}
+
+// updateMembers 解析成员并更新群组成员，任一成员处理失败时返回汇总的错误；以owner模式提交的任务只能变更调用方管理的群组
+func (op *Operation) updateMembers(tractx context.Context) error {
+	group, err := ldap.GetGroup(tractx, op.ID, op.IDType, "", "distinguishedName", "managedBy", "msExchCoManagedByLink")
+	if err != nil {
+		return err
+	}
+	if err = ldap.AuthorizeMemberChange(tractx, &group); err != nil {
+		return err
+	}
+
+	var failures []string
+	resolve := func(ids []string) []string {
+		var dns []string
+		for _, id := range ids {
+			member, err := ldap.GetMember(tractx, id, op.MemberIDType, "")
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			dns = append(dns, member.DistinguishedName)
+		}
+		return dns
+	}
+	addMembers := resolve(op.AddMembers)
+	removeMembers := resolve(op.RemoveMembers)
+
+	if len(addMembers) > 0 {
+		if err = ldap.AddGroupMembers(tractx, group.DistinguishedName, "distinguishedName", "", addMembers...); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(removeMembers) > 0 {
+		if err = ldap.RemoveGroupMembers(tractx, group.DistinguishedName, "distinguishedName", "", removeMembers...); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return &ers.OptErr{Option: fmt.Sprintf("update members of '%s'", group.DistinguishedName), Message: strings.Join(failures, "; ")}
+	}
+	return nil
+}
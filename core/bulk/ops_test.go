@@ -0,0 +1,48 @@
+package bulk
+
+import "testing"
+
+func TestTakePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       Operation
+		password string
+		needs    bool
+	}{
+		{
+			name:     "create_user",
+			op:       Operation{Type: OpCreateUser, User: &NewUser{SAMAccountName: "alice", Password: "P@ssw0rd!"}},
+			password: "P@ssw0rd!",
+			needs:    true,
+		},
+		{
+			name:     "set_password",
+			op:       Operation{Type: OpSetPassword, ID: "alice", IDType: "sAMAccountName", Password: "P@ssw0rd!"},
+			password: "P@ssw0rd!",
+			needs:    true,
+		},
+		{
+			name: "update_user ignores stray passwords",
+			op:   Operation{Type: OpUpdateUser, Password: "stray", User: &NewUser{Password: "stray"}},
+		},
+		{
+			name: "update_members",
+			op:   Operation{Type: OpUpdateMembers},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.op.needsPassword(); got != tt.needs {
+				t.Errorf("needsPassword() = %v, want %v", got, tt.needs)
+			}
+			password := tt.op.takePassword()
+			if tt.needs && password != tt.password {
+				t.Errorf("takePassword() = %q, want %q", password, tt.password)
+			}
+			if tt.op.Password != "" || (tt.op.User != nil && tt.op.User.Password != "") {
+				t.Errorf("password left in operation: %+v", tt.op)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package changes
+
+import (
+	"context"
+	"ldap-http-service/config"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/logger"
+	"sync"
+)
+
+// 订阅者的事件缓冲区大小
+const subscriberBuffer = 256
+
+var (
+	feed     *changeFeed
+	feedOnce sync.Once
+)
+
+// Init 加载变更跟踪状态，并在后台开始轮询目录变更；未启用变更跟踪时不做任何操作
+func Init(tractx context.Context) (err error) {
+	if !config.ChangesConfig.Enabled {
+		return nil
+	}
+
+	feedOnce.Do(func() {
+		logger.LdapLogger.WithContext(tractx).Info("正在初始化目录变更跟踪...")
+		feed = newChangeFeed(config.ChangesConfig.Path, config.ChangesConfig.Interval, config.ChangesConfig.Retention)
+		feed.invalidateCache = config.CacheConfig.Enabled && config.CacheConfig.FollowChanges
+		if err = feed.load(); err != nil {
+			return
+		}
+
+		ctx := context.WithValue(context.Background(), "opt", "跟踪目录变更")
+		go feed.run(ctx)
+	})
+	return
+}
+
+// Events 获取游标之后的变更事件，返回事件列表以及下一次请求使用的游标
+func Events(cursor int64, limit int) ([]Event, int64, error) {
+	if feed == nil {
+		return nil, cursor, &ers.ForbiddenErr{Message: "change feed is disabled"}
+	}
+
+	feed.mutex.Lock()
+	defer feed.mutex.Unlock()
+
+	events, err := feed.since(cursor, limit)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	next := cursor
+	if len(events) > 0 {
+		next = events[len(events)-1].ID
+	} else if cursor < 0 {
+		next = feed.state.Seq
+	}
+	return events, next, nil
+}
+
+// Subscribe 订阅变更事件，返回游标之后已有的事件以及后续实时事件的通道，游标为负数时仅订阅实时事件；
+// 通道在订阅者处理过慢或调用cancel后关闭
+func Subscribe(cursor int64) (backlog []Event, events <-chan Event, cancel func(), err error) {
+	if feed == nil {
+		return nil, nil, nil, &ers.ForbiddenErr{Message: "change feed is disabled"}
+	}
+
+	feed.mutex.Lock()
+	defer feed.mutex.Unlock()
+
+	if cursor >= 0 {
+		backlog, err = feed.since(cursor, 0)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	feed.subscribers[ch] = struct{}{}
+	cancel = func() {
+		feed.mutex.Lock()
+		defer feed.mutex.Unlock()
+		if _, ok := feed.subscribers[ch]; ok {
+			delete(feed.subscribers, ch)
+			close(ch)
+		}
+	}
+	return backlog, ch, cancel, nil
+}
@@ -0,0 +1,385 @@
+package changes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 变更事件类型
+const (
+	UserCreated        = "user.created"
+	UserUpdated        = "user.updated"
+	UserDisabled       = "user.disabled"
+	UserEnabled        = "user.enabled"
+	UserDeleted        = "user.deleted"
+	GroupCreated       = "group.created"
+	GroupUpdated       = "group.updated"
+	GroupDeleted       = "group.deleted"
+	GroupMemberAdded   = "group.member_added"
+	GroupMemberRemoved = "group.member_removed"
+)
+
+// userAccountControl中代表账户已禁用的标志位
+const uacAccountDisable = 0x2
+
+// 搜索已禁用用户的过滤条件
+const disabledFilter = "(userAccountControl:1.2.840.113556.1.4.803:=2)"
+
+// Event 目录变更事件，ID为单调递增的游标
+type Event struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	ObjectGUID string    `json:"objectGUID"`
+	DN         string    `json:"distinguishedName"`
+	USNChanged int64     `json:"uSNChanged"`
+	Member     string    `json:"member,omitempty"`
+}
+
+// state 需要持久化的变更跟踪状态：游标、最近事件组成的有界队列，以及判断禁用、启用事件所需的已禁用用户集合；
+// 群组成员的变化通过链接值的复制元数据获取，不保存对象或成员的快照
+type state struct {
+	Initialized   bool            `json:"initialized"`
+	HighWaterMark int64           `json:"high_water_mark"`
+	Seq           int64           `json:"seq"`
+	Events        []Event         `json:"events"`
+	Disabled      map[string]bool `json:"disabled,omitempty"`
+}
+
+// changeFeed 通过轮询uSNChanged跟踪目录变更；uSNChanged仅在单个DC内有效，因此始终轮询配置中的同一个DC；
+// 删除通过Deleted Objects容器中的墓碑检测，服务账号没有读取权限时不报告删除事件
+type changeFeed struct {
+	path        string
+	interval    time.Duration
+	retention   int
+	mutex       sync.Mutex
+	state       state
+	subscribers map[chan Event]struct{}
+	// 是否根据变更事件使用户及群组查询缓存失效
+	invalidateCache bool
+	// 无法读取墓碑时只记录一次警告
+	deletesWarned bool
+}
+
+func newChangeFeed(path string, interval time.Duration, retention int) *changeFeed {
+	return &changeFeed{
+		path:        path,
+		interval:    interval,
+		retention:   retention,
+		state:       state{Disabled: make(map[string]bool)},
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// 从本地文件加载上次的游标、事件及已禁用用户
+func (f *changeFeed) load() error {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, &f.state); err != nil {
+		return fmt.Errorf("failed to load change feed state '%s': %v", f.path, err)
+	}
+	if f.state.Disabled == nil {
+		f.state.Disabled = make(map[string]bool)
+	}
+	return nil
+}
+
+// 将状态写入本地文件，先写临时文件再重命名，避免写入中断导致文件损坏；调用方需持有锁
+func (f *changeFeed) save() error {
+	data, err := json.Marshal(f.state)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	if err = os.WriteFile(f.path+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(f.path+".tmp", f.path)
+}
+
+// 按固定间隔轮询目录变更，直到ctx被取消
+func (f *changeFeed) run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		if err := f.poll(ctx); err != nil {
+			logger.LdapLogger.WithContext(ctx).Errorf("轮询目录变更失败: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 首次运行时建立基线：以DC当前的highestCommittedUSN作为游标，并记录已禁用的用户，不产生事件
+func (f *changeFeed) baseline(ctx context.Context) error {
+	highWaterMark, err := ldap.HighestCommittedUSN(ctx)
+	if err != nil {
+		return err
+	}
+	disabled := make(map[string]bool)
+	err = ldap.SearchUsers(ctx, disabledFilter, "", []string{"objectGUID"}, func(user *ldap.User) error {
+		disabled[user.ObjectGUID] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.state.Initialized = true
+	f.state.HighWaterMark = highWaterMark
+	f.state.Disabled = disabled
+	logger.LdapLogger.WithContext(ctx).Infof("目录变更跟踪基线已建立，uSNChanged游标: %d", highWaterMark)
+	return f.save()
+}
+
+// 搜索uSNChanged大于游标的用户、群组及墓碑生成事件，只有游标前进时才写入状态文件
+func (f *changeFeed) poll(ctx context.Context) error {
+	f.mutex.Lock()
+	initialized, since := f.state.Initialized, f.state.HighWaterMark
+	f.mutex.Unlock()
+	if !initialized {
+		return f.baseline(ctx)
+	}
+
+	filter := fmt.Sprintf("(uSNChanged>=%d)", since+1)
+	highWaterMark := since
+	var events []Event
+	// 本次轮询中禁用状态发生变化的用户，轮询成功后再更新到状态中，失败时下次轮询重新比较
+	disabled := make(map[string]bool)
+
+	err := ldap.SearchUsers(ctx, filter, "", []string{"objectGUID", "uSNCreated", "userAccountControl"}, func(user *ldap.User) error {
+		uac, _ := strconv.ParseInt(user.UserAccountControl, 10, 64)
+		isDisabled := uac&uacAccountDisable != 0
+		events = append(events, userEvent(user, isDisabled, f.wasDisabled(user.ObjectGUID), since))
+		disabled[user.ObjectGUID] = isDisabled
+		highWaterMark = maxUSN(highWaterMark, user.USNChanged)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var groups []*ldap.Group
+	err = ldap.SearchGroups(ctx, filter, "", []string{"objectGUID", "uSNCreated"}, func(group *ldap.Group) error {
+		groups = append(groups, group)
+		highWaterMark = maxUSN(highWaterMark, group.USNChanged)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		var changes []ldap.MemberChange
+		if parseUSN(group.USNCreated) <= since {
+			if changes, err = ldap.MemberChangesSince(ctx, group.DistinguishedName, since); err != nil {
+				return err
+			}
+		}
+		events = append(events, groupEvents(group, since, changes)...)
+	}
+
+	deleted, deletedUSN := f.pollDeleted(ctx, since)
+	events = append(events, deleted...)
+	for _, event := range deleted {
+		disabled[event.ObjectGUID] = false
+	}
+	if deletedUSN > highWaterMark {
+		highWaterMark = deletedUSN
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if highWaterMark == since && len(events) == 0 {
+		return nil
+	}
+	for guid, isDisabled := range disabled {
+		if isDisabled {
+			f.state.Disabled[guid] = true
+		} else {
+			delete(f.state.Disabled, guid)
+		}
+	}
+	f.append(events)
+	f.state.HighWaterMark = highWaterMark
+	if err = f.save(); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		f.publish(event)
+	}
+	if len(events) > 0 {
+		logger.LdapLogger.WithContext(ctx).Infof("检测到 %d 个目录变更事件，当前uSNChanged游标: %d", len(events), highWaterMark)
+	}
+	return nil
+}
+
+// 搜索游标之后删除的用户和群组，返回删除事件及其中最大的uSNChanged；没有权限读取墓碑时只记录一次警告
+func (f *changeFeed) pollDeleted(ctx context.Context, since int64) ([]Event, int64) {
+	var events []Event
+	highWaterMark := since
+	filter := fmt.Sprintf("(&(|(&(objectClass=user)(!(objectClass=computer)))(objectClass=group))(uSNChanged>=%d))", since+1)
+	err := ldap.SearchDeleted(ctx, filter, func(obj *ldap.DeletedObject) error {
+		events = append(events, deletedEvent(obj))
+		highWaterMark = maxUSN(highWaterMark, obj.USNChanged)
+		return nil
+	})
+	if err != nil {
+		if !f.deletesWarned {
+			f.deletesWarned = true
+			logger.LdapLogger.WithContext(ctx).Warningf("无法读取已删除的对象，变更跟踪将不包含删除事件: %v", err)
+		}
+		return nil, since
+	}
+	return events, highWaterMark
+}
+
+// 用户在上次轮询后是否处于禁用状态
+func (f *changeFeed) wasDisabled(guid string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.state.Disabled[guid]
+}
+
+// 为事件分配游标并追加到事件队列中，超过保留数量时丢弃最早的事件；调用方需持有锁
+func (f *changeFeed) append(events []Event) {
+	for i := range events {
+		f.state.Seq++
+		events[i].ID = f.state.Seq
+	}
+	f.state.Events = append(f.state.Events, events...)
+	if len(f.state.Events) > f.retention {
+		f.state.Events = append([]Event(nil), f.state.Events[len(f.state.Events)-f.retention:]...)
+	}
+}
+
+// 根据用户当前及上次轮询时的禁用状态生成创建、更新、禁用或启用事件
+func userEvent(user *ldap.User, disabled, wasDisabled bool, since int64) Event {
+	event := newEvent(UserUpdated, &user.BaseObject)
+	switch {
+	case parseUSN(user.USNCreated) > since:
+		event.Type = UserCreated
+	case disabled && !wasDisabled:
+		event.Type = UserDisabled
+	case !disabled && wasDisabled:
+		event.Type = UserEnabled
+	}
+	return event
+}
+
+// 生成群组的创建事件，或者按成员变更生成每个新增、移除成员的事件，成员未变化时生成更新事件
+func groupEvents(group *ldap.Group, since int64, changes []ldap.MemberChange) []Event {
+	if parseUSN(group.USNCreated) > since {
+		return []Event{newEvent(GroupCreated, &group.BaseObject)}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].USN < changes[j].USN })
+	var events []Event
+	for _, change := range changes {
+		event := newEvent(GroupMemberAdded, &group.BaseObject)
+		if change.Removed {
+			event.Type = GroupMemberRemoved
+		}
+		event.Member = change.Member
+		events = append(events, event)
+	}
+
+	// 成员未变化时，代表群组的其他属性发生了变更
+	if len(events) == 0 {
+		events = append(events, newEvent(GroupUpdated, &group.BaseObject))
+	}
+	return events
+}
+
+// 生成删除事件，DN为对象删除前的DN
+func deletedEvent(obj *ldap.DeletedObject) Event {
+	eventType := UserDeleted
+	for _, class := range obj.ObjectClass {
+		if strings.EqualFold(class, "group") {
+			eventType = GroupDeleted
+		}
+	}
+	event := newEvent(eventType, &obj.BaseObject)
+	event.DN = obj.OriginalDN()
+	return event
+}
+
+// 向所有订阅者推送事件，订阅者处理过慢导致缓冲区已满时将其断开，避免阻塞轮询；调用方需持有锁
+func (f *changeFeed) publish(event Event) {
+	if f.invalidateCache {
+		ldap.InvalidateCache(event.DN, event.Member)
+	}
+	for ch := range f.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(f.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// 返回游标之后的事件，游标为负数时从保留的最早事件开始；游标早于保留的最早事件时返回错误；调用方需持有锁
+func (f *changeFeed) since(cursor int64, limit int) ([]Event, error) {
+	events := f.state.Events
+	if cursor >= 0 && len(events) > 0 && cursor < events[0].ID-1 {
+		return nil, &ers.CursorExpiredErr{Cursor: cursor}
+	}
+
+	var result []Event
+	for _, event := range events {
+		if event.ID > cursor {
+			result = append(result, event)
+			if limit > 0 && len(result) >= limit {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func newEvent(eventType string, obj *ldap.BaseObject) Event {
+	return Event{
+		Type:       eventType,
+		Time:       time.Now(),
+		ObjectGUID: obj.ObjectGUID,
+		DN:         obj.DistinguishedName,
+		USNChanged: parseUSN(obj.USNChanged),
+	}
+}
+
+func parseUSN(usn string) int64 {
+	value, _ := strconv.ParseInt(usn, 10, 64)
+	return value
+}
+
+func maxUSN(current int64, usn string) int64 {
+	if value := parseUSN(usn); value > current {
+		return value
+	}
+	return current
+}
@@ -0,0 +1,181 @@
+package changes
+
+import (
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"reflect"
+	"testing"
+)
+
+func TestUserEvent(t *testing.T) {
+	tests := []struct {
+		name        string
+		usnCreated  string
+		disabled    bool
+		wasDisabled bool
+		want        string
+	}{
+		{name: "created after cursor", usnCreated: "120", want: UserCreated},
+		{name: "created disabled", usnCreated: "120", disabled: true, want: UserCreated},
+		{name: "disabled", usnCreated: "10", disabled: true, want: UserDisabled},
+		{name: "enabled", usnCreated: "10", wasDisabled: true, want: UserEnabled},
+		{name: "still disabled", usnCreated: "10", disabled: true, wasDisabled: true, want: UserUpdated},
+		{name: "other attribute", usnCreated: "10", want: UserUpdated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &ldap.User{BaseObject: ldap.BaseObject{ObjectGUID: "guid", DistinguishedName: "CN=alice", USNCreated: tt.usnCreated, USNChanged: "130"}}
+			event := userEvent(user, tt.disabled, tt.wasDisabled, 100)
+			if event.Type != tt.want {
+				t.Errorf("userEvent().Type = %s, want %s", event.Type, tt.want)
+			}
+			if event.USNChanged != 130 || event.DN != "CN=alice" || event.ObjectGUID != "guid" {
+				t.Errorf("userEvent() = %+v", event)
+			}
+		})
+	}
+}
+
+func TestGroupEvents(t *testing.T) {
+	type result struct {
+		Type   string
+		Member string
+	}
+	tests := []struct {
+		name       string
+		usnCreated string
+		changes    []ldap.MemberChange
+		want       []result
+	}{
+		{
+			name:       "created",
+			usnCreated: "120",
+			changes:    []ldap.MemberChange{{Member: "CN=alice", USN: 121}},
+			want:       []result{{Type: GroupCreated}},
+		},
+		{
+			name:       "attribute changed",
+			usnCreated: "10",
+			want:       []result{{Type: GroupUpdated}},
+		},
+		{
+			name:       "members changed in usn order",
+			usnCreated: "10",
+			changes: []ldap.MemberChange{
+				{Member: "CN=bob", Removed: true, USN: 125},
+				{Member: "CN=alice", USN: 110},
+				{Member: "CN=carol", USN: 130},
+			},
+			want: []result{
+				{Type: GroupMemberAdded, Member: "CN=alice"},
+				{Type: GroupMemberRemoved, Member: "CN=bob"},
+				{Type: GroupMemberAdded, Member: "CN=carol"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &ldap.Group{BaseObject: ldap.BaseObject{DistinguishedName: "CN=sales", USNCreated: tt.usnCreated, USNChanged: "130"}}
+			var got []result
+			for _, event := range groupEvents(group, 100, tt.changes) {
+				if event.DN != "CN=sales" {
+					t.Errorf("event DN = %s", event.DN)
+				}
+				got = append(got, result{Type: event.Type, Member: event.Member})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupEvents() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeletedEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     ldap.DeletedObject
+		want    string
+		wantDN  string
+		wantUSN int64
+	}{
+		{
+			name: "user",
+			obj: ldap.DeletedObject{
+				BaseObject:      ldap.BaseObject{DistinguishedName: `CN=Alice\0ADEL:0f9a7c2e-41d3-4b8a-9c1e-5a6b7c8d9e0f,CN=Deleted Objects,DC=example,DC=com`, ObjectClass: []string{"top", "person", "organizationalPerson", "user"}, USNChanged: "150"},
+				LastKnownParent: "OU=People,DC=example,DC=com",
+			},
+			want:    UserDeleted,
+			wantDN:  "CN=Alice,OU=People,DC=example,DC=com",
+			wantUSN: 150,
+		},
+		{
+			name: "group without last known parent",
+			obj: ldap.DeletedObject{
+				BaseObject: ldap.BaseObject{DistinguishedName: `CN=Sales\0ADEL:0f9a7c2e-41d3-4b8a-9c1e-5a6b7c8d9e0f,CN=Deleted Objects,DC=example,DC=com`, ObjectClass: []string{"top", "group"}},
+			},
+			want:   GroupDeleted,
+			wantDN: `CN=Sales\0ADEL:0f9a7c2e-41d3-4b8a-9c1e-5a6b7c8d9e0f,CN=Deleted Objects,DC=example,DC=com`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := deletedEvent(&tt.obj)
+			if event.Type != tt.want || event.DN != tt.wantDN || event.USNChanged != tt.wantUSN {
+				t.Errorf("deletedEvent() = %+v, want type %s, DN %s, uSNChanged %d", event, tt.want, tt.wantDN, tt.wantUSN)
+			}
+		})
+	}
+}
+
+func TestAppendAndSince(t *testing.T) {
+	f := newChangeFeed("", 0, 3)
+	f.append([]Event{{Type: UserCreated}, {Type: UserUpdated}})
+	f.append([]Event{{Type: GroupCreated}, {Type: GroupUpdated}})
+
+	var ids []int64
+	for _, event := range f.state.Events {
+		ids = append(ids, event.ID)
+	}
+	if !reflect.DeepEqual(ids, []int64{2, 3, 4}) {
+		t.Fatalf("retained event ids = %v, want [2 3 4]", ids)
+	}
+
+	tests := []struct {
+		name    string
+		cursor  int64
+		limit   int
+		want    []int64
+		wantErr bool
+	}{
+		{name: "from the oldest", cursor: -1, want: []int64{2, 3, 4}},
+		{name: "cursor just before the oldest", cursor: 1, want: []int64{2, 3, 4}},
+		{name: "with limit", cursor: 2, limit: 1, want: []int64{3}},
+		{name: "up to date", cursor: 4},
+		{name: "expired cursor", cursor: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := f.since(tt.cursor, tt.limit)
+			if tt.wantErr {
+				if _, ok := err.(*ers.CursorExpiredErr); !ok {
+					t.Fatalf("since() error = %v, want *ers.CursorExpiredErr", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int64
+			for _, event := range events {
+				got = append(got, event.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("since(%d, %d) = %v, want %v", tt.cursor, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package expiry
+
+import (
+	"context"
+	"ldap-http-service/config"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/logger"
+	"sync"
+	"time"
+)
+
+var (
+	sched     *scheduler
+	schedOnce sync.Once
+)
+
+// Init 加载本地记录的限时群组成员，注册写入事件的处理函数，并在后台按时移除到期的成员
+func Init(tractx context.Context) (err error) {
+	schedOnce.Do(func() {
+		logger.LdapLogger.WithContext(tractx).Info("正在初始化限时群组成员调度器...")
+		sched = newScheduler(config.ExpiryConfig.Path, config.ExpiryConfig.Interval)
+		if err = sched.load(); err != nil {
+			return
+		}
+
+		ldap.AddWriteHook(sched.onWrite)
+		ctx := ldap.WithoutCache(context.WithValue(context.Background(), "opt", "移除到期的群组成员"))
+		go sched.run(ctx)
+	})
+	return
+}
+
+// AddGroupMembers 添加限时群组成员，成员在expiresAt后被移除；林中启用了特权访问管理功能时使用域控制器的限时成员，
+// 否则添加为普通成员并由服务在本地记录、到期后移除。已在群组中的成员同样会在到期后被移除
+func AddGroupMembers(tractx context.Context, groupDN, version string, expiresAt time.Time, memberDNs ...string) error {
+	if len(memberDNs) == 0 {
+		return nil
+	}
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return &ers.BadRequestErr{Message: "expires_at must be in the future"}
+	}
+
+	if ldap.MemberTTLSupported(tractx) {
+		if err := ldap.AddGroupMembersWithTTL(tractx, groupDN, "distinguishedName", version, ttl, memberDNs...); err != nil {
+			return err
+		}
+		for _, dn := range memberDNs {
+			expiration := Expiration{GroupDN: groupDN, MemberDN: dn, ExpiresAt: expiresAt}
+			logger.AuditLogger.WithContext(tractx).WithFields(expiration.fields()).Info("已添加限时群组成员，到期后由域控制器移除")
+		}
+		return nil
+	}
+
+	if sched == nil {
+		return &ers.UnSupportedErr{ObjectType: "feature", Object: "member expiration"}
+	}
+	if err := ldap.AddGroupMembers(tractx, groupDN, "distinguishedName", version, memberDNs...); err != nil {
+		return err
+	}
+	// 添加成员触发的写入事件会清除这些成员之前的到期时间，之后再记录新的到期时间
+	return sched.schedule(tractx, groupDN, expiresAt, memberDNs)
+}
+
+// Pending 获取群组中由服务记录、尚未移除的限时成员，groupDN为空时返回全部；使用域控制器限时成员时始终为空
+func Pending(groupDN string) []Expiration {
+	if sched == nil {
+		return nil
+	}
+
+	sched.mutex.Lock()
+	defer sched.mutex.Unlock()
+
+	expirations := make([]Expiration, 0)
+	for _, expiration := range sched.list(groupDN) {
+		expirations = append(expirations, *expiration)
+	}
+	return expirations
+}
@@ -0,0 +1,243 @@
+package expiry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/logger"
+	"ldap-http-service/lib/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Expiration 待到期移除的群组成员
+type Expiration struct {
+	GroupDN   string    `json:"group"`
+	MemberDN  string    `json:"member"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	TraceId   string    `json:"trace_id,omitempty"`
+}
+
+func (e *Expiration) key() string {
+	return strings.ToLower(e.GroupDN) + "\n" + strings.ToLower(e.MemberDN)
+}
+
+func (e *Expiration) fields() logrus.Fields {
+	return logrus.Fields{"group": e.GroupDN, "member": e.MemberDN, "expires_at": e.ExpiresAt}
+}
+
+// scheduler 在域控制器不支持限时成员时，由服务在本地记录待到期的成员，并按固定间隔移除已到期的成员
+type scheduler struct {
+	path     string
+	interval time.Duration
+	mutex    sync.Mutex
+	pending  map[string]*Expiration
+}
+
+func newScheduler(path string, interval time.Duration) *scheduler {
+	return &scheduler{
+		path:     path,
+		interval: interval,
+		pending:  make(map[string]*Expiration),
+	}
+}
+
+// 从本地文件加载待到期的成员
+func (s *scheduler) load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var expirations []*Expiration
+	if err = json.Unmarshal(data, &expirations); err != nil {
+		return fmt.Errorf("failed to load expirations '%s': %v", s.path, err)
+	}
+	for _, expiration := range expirations {
+		s.pending[expiration.key()] = expiration
+	}
+	return nil
+}
+
+// 将待到期的成员写入本地文件，先写临时文件再重命名，避免写入中断导致文件损坏；调用方需持有锁
+func (s *scheduler) save() error {
+	data, err := json.Marshal(s.list(""))
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	if err = os.WriteFile(s.path+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(s.path+".tmp", s.path)
+}
+
+// 按到期时间排序返回待到期的成员，groupDN不为空时只返回该群组的成员；调用方需持有锁
+func (s *scheduler) list(groupDN string) []*Expiration {
+	expirations := make([]*Expiration, 0, len(s.pending))
+	for _, expiration := range s.pending {
+		if groupDN == "" || strings.EqualFold(expiration.GroupDN, groupDN) {
+			expirations = append(expirations, expiration)
+		}
+	}
+	sort.Slice(expirations, func(i, j int) bool {
+		return expirations[i].ExpiresAt.Before(expirations[j].ExpiresAt)
+	})
+	return expirations
+}
+
+// 记录群组成员的到期时间，成员已有到期时间时覆盖
+func (s *scheduler) schedule(tractx context.Context, groupDN string, expiresAt time.Time, memberDNs []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var traceId string
+	if id, ok := tractx.Value("trace_id").(string); ok {
+		traceId = id
+	}
+
+	scheduled := make([]*Expiration, 0, len(memberDNs))
+	previous := make(map[string]*Expiration)
+	for _, dn := range memberDNs {
+		expiration := &Expiration{GroupDN: groupDN, MemberDN: dn, ExpiresAt: expiresAt, CreatedAt: time.Now(), TraceId: traceId}
+		previous[expiration.key()] = s.pending[expiration.key()]
+		s.pending[expiration.key()] = expiration
+		scheduled = append(scheduled, expiration)
+	}
+	if err := s.save(); err != nil {
+		for key, expiration := range previous {
+			if expiration == nil {
+				delete(s.pending, key)
+			} else {
+				s.pending[key] = expiration
+			}
+		}
+		return err
+	}
+
+	for _, expiration := range scheduled {
+		logger.AuditLogger.WithContext(tractx).WithFields(expiration.fields()).Info("已记录限时群组成员，到期后由服务移除")
+	}
+	return nil
+}
+
+// 写入事件的处理函数：成员被重新添加或移除、群组或成员被删除后，之前记录的到期时间不再有效
+func (s *scheduler) onWrite(event ldap.WriteEvent) {
+	var match func(expiration *Expiration) bool
+	switch event.Type {
+	case ldap.EventGroupMemberAdded, ldap.EventGroupMemberRemoved:
+		members, _ := event.Data["members"].([]string)
+		match = func(expiration *Expiration) bool {
+			return strings.EqualFold(expiration.GroupDN, event.DN) && utils.InSliceIC(members, expiration.MemberDN)
+		}
+	case ldap.EventObjectDeleted:
+		match = func(expiration *Expiration) bool {
+			return strings.EqualFold(expiration.GroupDN, event.DN) || strings.EqualFold(expiration.MemberDN, event.DN)
+		}
+	default:
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var cancelled bool
+	for key, expiration := range s.pending {
+		if match(expiration) {
+			delete(s.pending, key)
+			cancelled = true
+		}
+	}
+	if cancelled {
+		if err := s.save(); err != nil {
+			logger.LdapLogger.Errorf("保存限时群组成员失败: %v", err)
+		}
+	}
+}
+
+// 按固定间隔移除已到期的成员，直到ctx被取消
+func (s *scheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.expire(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 按群组批量移除已到期的成员；移除失败的成员保留，在下一次检查时重试
+func (s *scheduler) expire(ctx context.Context) {
+	now := time.Now()
+	due := make(map[string][]*Expiration)
+
+	s.mutex.Lock()
+	for _, expiration := range s.pending {
+		if !expiration.ExpiresAt.After(now) {
+			due[expiration.GroupDN] = append(due[expiration.GroupDN], expiration)
+		}
+	}
+	s.mutex.Unlock()
+
+	// 移除成员会触发写入事件，处理函数需要获取锁，因此在锁外执行
+	for groupDN, expirations := range due {
+		memberDNs := make([]string, 0, len(expirations))
+		for _, expiration := range expirations {
+			memberDNs = append(memberDNs, expiration.MemberDN)
+		}
+
+		err := ldap.RemoveGroupMembers(ctx, groupDN, "distinguishedName", "", memberDNs...)
+		var notFoundError *ers.NotFoundError
+		if err != nil && !errors.As(err, &notFoundError) {
+			for _, expiration := range expirations {
+				logger.AuditLogger.WithContext(ctx).WithFields(expiration.fields()).Errorf("移除到期的群组成员失败，将在下次检查时重试: %v", err)
+			}
+			continue
+		}
+
+		for _, expiration := range expirations {
+			if err != nil {
+				logger.AuditLogger.WithContext(ctx).WithFields(expiration.fields()).Warning("群组已不存在，丢弃到期的群组成员")
+			} else {
+				logger.AuditLogger.WithContext(ctx).WithFields(expiration.fields()).Info("已移除到期的群组成员")
+			}
+		}
+		s.done(expirations)
+	}
+}
+
+// 删除已处理的到期记录，处理期间被重新记录的成员保留新的到期时间
+func (s *scheduler) done(expirations []*Expiration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var changed bool
+	for _, expiration := range expirations {
+		if s.pending[expiration.key()] == expiration {
+			delete(s.pending, expiration.key())
+			changed = true
+		}
+	}
+	if changed {
+		if err := s.save(); err != nil {
+			logger.LdapLogger.Errorf("保存限时群组成员失败: %v", err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package expiry
+
+import (
+	"context"
+	"ldap-http-service/core/ldap"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+const (
+	sales   = "CN=Sales,OU=Groups,DC=example,DC=com"
+	finance = "CN=Finance,OU=Groups,DC=example,DC=com"
+	alice   = "CN=Alice,OU=Users,DC=example,DC=com"
+	bob     = "CN=Bob,OU=Users,DC=example,DC=com"
+)
+
+func newTestScheduler(t *testing.T) *scheduler {
+	s := newScheduler(filepath.Join(t.TempDir(), "expirations.json"), time.Minute)
+	expiresAt := time.Now().Add(time.Hour)
+	if err := s.schedule(context.Background(), sales, expiresAt, []string{alice, bob}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.schedule(context.Background(), finance, expiresAt, []string{alice}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// 返回待到期记录的 "群组|成员"，按字典序排序
+func pendingKeys(s *scheduler) []string {
+	keys := make([]string, 0, len(s.pending))
+	for _, expiration := range s.pending {
+		keys = append(keys, expiration.GroupDN+"|"+expiration.MemberDN)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestOnWrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		event ldap.WriteEvent
+		want  []string
+	}{
+		{
+			name:  "member removed",
+			event: ldap.WriteEvent{Type: ldap.EventGroupMemberRemoved, DN: sales, Data: map[string]interface{}{"members": []string{alice}}},
+			want:  []string{finance + "|" + alice, sales + "|" + bob},
+		},
+		{
+			name:  "member re-added without expiry, dn is case insensitive",
+			event: ldap.WriteEvent{Type: ldap.EventGroupMemberAdded, DN: "cn=sales,ou=groups,dc=example,dc=com", Data: map[string]interface{}{"members": []string{"cn=bob,ou=users,dc=example,dc=com"}}},
+			want:  []string{finance + "|" + alice, sales + "|" + alice},
+		},
+		{
+			name:  "group deleted",
+			event: ldap.WriteEvent{Type: ldap.EventObjectDeleted, DN: sales},
+			want:  []string{finance + "|" + alice},
+		},
+		{
+			name:  "member deleted",
+			event: ldap.WriteEvent{Type: ldap.EventObjectDeleted, DN: alice},
+			want:  []string{sales + "|" + bob},
+		},
+		{
+			name:  "other group",
+			event: ldap.WriteEvent{Type: ldap.EventGroupMemberRemoved, DN: "CN=Other,OU=Groups,DC=example,DC=com", Data: map[string]interface{}{"members": []string{alice}}},
+			want:  []string{finance + "|" + alice, sales + "|" + alice, sales + "|" + bob},
+		},
+		{
+			name:  "unrelated event",
+			event: ldap.WriteEvent{Type: ldap.EventUserPasswordSet, DN: alice},
+			want:  []string{finance + "|" + alice, sales + "|" + alice, sales + "|" + bob},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScheduler(t)
+			s.onWrite(tt.event)
+			if got := pendingKeys(s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pending = %v, want %v", got, tt.want)
+			}
+
+			// 取消的记录同时从本地文件中删除
+			reloaded := newScheduler(s.path, time.Minute)
+			if err := reloaded.load(); err != nil {
+				t.Fatal(err)
+			}
+			if got := pendingKeys(reloaded); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reloaded pending = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDone(t *testing.T) {
+	s := newTestScheduler(t)
+	s.mutex.Lock()
+	expirations := s.list(sales)
+	s.mutex.Unlock()
+
+	// 处理期间Bob被重新记录，新的到期时间需要保留
+	rescheduledAt := time.Now().Add(48 * time.Hour)
+	if err := s.schedule(context.Background(), sales, rescheduledAt, []string{bob}); err != nil {
+		t.Fatal(err)
+	}
+	s.done(expirations)
+
+	want := []string{finance + "|" + alice, sales + "|" + bob}
+	if got := pendingKeys(s); !reflect.DeepEqual(got, want) {
+		t.Fatalf("pending = %v, want %v", got, want)
+	}
+	if got := s.list(sales)[0].ExpiresAt; !got.Equal(rescheduledAt) {
+		t.Errorf("rescheduled expiry = %v, want %v", got, rescheduledAt)
+	}
+
+	reloaded := newScheduler(s.path, time.Minute)
+	if err := reloaded.load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := pendingKeys(reloaded); !reflect.DeepEqual(got, want) {
+		t.Errorf("reloaded pending = %v, want %v", got, want)
+	}
+}
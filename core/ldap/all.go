@@ -3,18 +3,27 @@ package ldap
 import (
 	"context"
 	"fmt"
+	"github.com/go-ldap/ldap"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"ldap-http-service/config"
+	"ldap-http-service/lib/cache"
 	"ldap-http-service/lib/ers"
 	"ldap-http-service/lib/logger"
+	"ldap-http-service/lib/photo"
 	"ldap-http-service/lib/utils"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 var (
 	ldapPool *ldapConnPool
 	ldapOnce sync.Once
+
+	// 人员搜索结果缓存，键为小写的搜索词，值为按匹配程度排序的全部结果
+	peopleCache *cache.Cache[[]Person]
 )
 
 // 初始化LDAP连接池
@@ -32,12 +41,20 @@ func initLdapPool(tractx context.Context) *ldapConnPool {
 			pool:     make(chan *pooledLdapConn, config.LdapConfig.PoolSize),
 			counter:  0,
 		}
+		peopleCache = cache.New[[]Person](config.TypeaheadConfig.CacheSize, config.TypeaheadConfig.CacheTTL)
+		initCache()
+		initAuth()
 	})
 	return ldapPool
 }
 
 // CreateEnabledUser 创建启用LDAP用户
 func CreateEnabledUser(tractx context.Context, sAMAccountName, displayName, OU, password, primaryDomain string) error {
+	return CreateEnabledUserWithAttrs(tractx, sAMAccountName, displayName, OU, password, primaryDomain, nil)
+}
+
+// CreateEnabledUserWithAttrs 创建启用的用户，attrs为在创建请求中一并写入的其他属性，例如givenName、sn，值为空的属性不写入
+func CreateEnabledUserWithAttrs(tractx context.Context, sAMAccountName, displayName, OU, password, primaryDomain string, attrs map[string]string) error {
 	initLdapPool(tractx)
 	// 开始创建用户
 	userFields := logrus.Fields{
@@ -67,7 +84,7 @@ func CreateEnabledUser(tractx context.Context, sAMAccountName, displayName, OU,
 
 	// 创建用户
 	logger.LdapLogger.WithContext(tractx).Infof("校验通过，开始创建用户对象 `%s` ...", userDN)
-	err = ldapPool.createUser(userDN, displayName, primaryDomain)
+	err = ldapPool.createUser(userDN, displayName, primaryDomain, attrs)
 	if err != nil {
 		return err
 	}
@@ -75,7 +92,7 @@ func CreateEnabledUser(tractx context.Context, sAMAccountName, displayName, OU,
 	// 为用户设置密码
 	logger.LdapLogger.WithContext(tractx).Infof("正在为用户 `%s` 设置密码...", userDN)
 
-	err = ldapPool.setPassword(userDN, password)
+	err = ldapPool.setPassword(userDN, password, "")
 	if err != nil {
 		return errors.Wrapf(err, "为用户 `%s` 设置密码失败", sAMAccountName)
 	}
@@ -88,21 +105,30 @@ func CreateEnabledUser(tractx context.Context, sAMAccountName, displayName, OU,
 		return errors.Wrapf(err, "启用用户 `%s` 失败", sAMAccountName)
 	}
 
+	emit(tractx, EventUserCreated, userDN, map[string]interface{}{"sAMAccountName": sAMAccountName, "displayName": displayName})
 	return nil
 }
 
-// GetUser 获取LDAP用户信息
-func GetUser(tractx context.Context, userId, userIdType, searchBase string) (User, error) {
-	return initLdapPool(tractx).getUser(userId, userIdType, searchBase)
+// GetUser 获取LDAP用户信息，可通过attributes指定需要获取的属性；启用缓存时优先返回缓存的结果
+func GetUser(tractx context.Context, userId, userIdType, searchBase string, attributes ...string) (User, error) {
+	initLdapPool(tractx)
+	return cachedGet(tractx, userCache, cacheKey(userId, userIdType, searchBase, attributes), func() (User, error) {
+		return ldapPool.getUser(userId, userIdType, searchBase, attributes...)
+	})
 }
 
-// MoveObjectToOU 移动LDAP对象到OU
-func MoveObjectToOU(tractx context.Context, dn, newOU string) error {
-	return initLdapPool(tractx).moveObjectToOU(dn, newOU)
+// MoveObjectToOU 移动LDAP对象到OU，version不为空时要求对象的uSNChanged与其一致；
+// ModifyDN请求不支持携带断言控件，版本只在移动前校验，与移动之间的并发修改无法被检测到
+func MoveObjectToOU(tractx context.Context, dn, newOU, version string) error {
+	if err := initLdapPool(tractx).moveObjectToOU(dn, newOU, version); err != nil {
+		return err
+	}
+	emit(tractx, EventObjectMoved, dn, map[string]interface{}{"OU": newOU})
+	return nil
 }
 
-// SetUserPwd 设置LDAP用户密码
-func SetUserPwd(tractx context.Context, userId, userIdType, password, searchBase string) error {
+// SetUserPwd 设置LDAP用户密码，version不为空时要求用户的uSNChanged与其一致
+func SetUserPwd(tractx context.Context, userId, userIdType, password, searchBase, version string) error {
 	initLdapPool(tractx)
 	userFields := logrus.Fields{
 		userIdType: userId,
@@ -115,7 +141,7 @@ func SetUserPwd(tractx context.Context, userId, userIdType, password, searchBase
 	}
 
 	logger.LdapLogger.WithContext(tractx).WithFields(userFields).Infof("查询目标用户完成，正在设置 `%s` 的用户密码...", user.DistinguishedName)
-	err = ldapPool.setPassword(user.DistinguishedName, password)
+	err = ldapPool.setPassword(user.DistinguishedName, password, version)
 	if err != nil {
 		return errors.Wrapf(err, "查询用户 %s='%s' 失败", userIdType, userId)
 	}
@@ -126,16 +152,20 @@ func SetUserPwd(tractx context.Context, userId, userIdType, password, searchBase
 		return errors.Wrapf(err, "解锁用户 `%s` 失败", user.DistinguishedName)
 	}
 
+	emit(tractx, EventUserPasswordSet, user.DistinguishedName, nil)
 	return nil
 }
 
-// GetGroup 获取LDAP群组信息
-func GetGroup(tractx context.Context, groupId, groupIdType, searchBase string) (Group, error) {
-	return initLdapPool(tractx).getGroup(groupId, groupIdType, searchBase)
+// GetGroup 获取LDAP群组信息，可通过attributes指定需要获取的属性；启用缓存时优先返回缓存的结果
+func GetGroup(tractx context.Context, groupId, groupIdType, searchBase string, attributes ...string) (Group, error) {
+	initLdapPool(tractx)
+	return cachedGet(tractx, groupCache, cacheKey(groupId, groupIdType, searchBase, attributes), func() (Group, error) {
+		return ldapPool.getGroup(groupId, groupIdType, searchBase, attributes...)
+	})
 }
 
-// AddGroupMembers 添加LDAP群组成员
-func AddGroupMembers(tractx context.Context, groupId, groupIdType string, userDNs ...string) error {
+// AddGroupMembers 添加LDAP群组成员，version不为空时要求群组的uSNChanged与其一致
+func AddGroupMembers(tractx context.Context, groupId, groupIdType, version string, userDNs ...string) error {
 	initLdapPool(tractx)
 	groupFields := logrus.Fields{
 		groupIdType: groupId,
@@ -158,17 +188,30 @@ func AddGroupMembers(tractx context.Context, groupId, groupIdType string, userDN
 			i++
 		}
 	}
+
+	// 嵌套群组不允许形成循环
+	cycles, err := ldapPool.findMembershipCycles(group.DistinguishedName, userDNs)
+	if err != nil {
+		return errors.Wrap(err, "校验群组嵌套关系失败")
+	}
+	if len(cycles) > 0 {
+		return &ers.MembershipCycleErr{Group: group.DistinguishedName, Member: cycles[0]}
+	}
+
 	logger.LdapLogger.WithContext(tractx).Infof("预校验待添加成员列表完成, 实际待添加人员共计 %d 人, 开始将以下人员添加到群组: %s", len(userDNs), userDNs)
-	err = ldapPool.addGroupMembers(group.DistinguishedName, userDNs...)
+	err = ldapPool.addGroupMembers(group.DistinguishedName, version, userDNs...)
 	if err != nil {
 		return errors.Wrap(err, "执行群组成员添加失败")
 	}
 
+	if len(userDNs) > 0 {
+		emit(tractx, EventGroupMemberAdded, group.DistinguishedName, map[string]interface{}{"members": userDNs})
+	}
 	return nil
 }
 
-// RemoveGroupMembers 移除LDAP群组成员
-func RemoveGroupMembers(tractx context.Context, groupId, groupIdType string, userDNs ...string) error {
+// RemoveGroupMembers 移除LDAP群组成员，version不为空时要求群组的uSNChanged与其一致
+func RemoveGroupMembers(tractx context.Context, groupId, groupIdType, version string, userDNs ...string) error {
 	initLdapPool(tractx)
 	groupFields := logrus.Fields{
 		groupIdType: groupId,
@@ -193,11 +236,61 @@ func RemoveGroupMembers(tractx context.Context, groupId, groupIdType string, use
 	}
 	logger.LdapLogger.WithContext(tractx).Infof("预校验待添加成员列表完成, 实际待移除人员共计 %d 人, 开始将以下人员从群组移除: %s", len(userDNs), userDNs)
 
-	err = ldapPool.removeGroupMembers(group.DistinguishedName, userDNs...)
+	err = ldapPool.removeGroupMembers(group.DistinguishedName, version, userDNs...)
 	if err != nil {
 		return errors.Wrap(err, "执行群组成员移除失败")
 	}
 
+	if len(userDNs) > 0 {
+		emit(tractx, EventGroupMemberRemoved, group.DistinguishedName, map[string]interface{}{"members": userDNs})
+	}
+	return nil
+}
+
+// MemberTTLSupported 林中是否启用了特权访问管理功能，启用后可以通过AddGroupMembersWithTTL添加限时成员
+func MemberTTLSupported(tractx context.Context) bool {
+	return initLdapPool(tractx).supportsMemberTTL()
+}
+
+// AddGroupMembersWithTTL 以限时成员的方式添加LDAP群组成员，成员在ttl后由域控制器自动移除，要求林中启用了特权访问管理功能；
+// 已在群组中的成员会被更新为限时成员，version不为空时要求群组的uSNChanged与其一致
+func AddGroupMembersWithTTL(tractx context.Context, groupId, groupIdType, version string, ttl time.Duration, userDNs ...string) error {
+	initLdapPool(tractx)
+	groupFields := logrus.Fields{
+		groupIdType: groupId,
+	}
+	if len(userDNs) == 0 {
+		return nil
+	}
+	if !ldapPool.supportsMemberTTL() {
+		return &ers.UnSupportedErr{ObjectType: "feature", Object: "member ttl"}
+	}
+	if ttl <= 0 {
+		return &ers.BadRequestErr{Message: "member ttl must be positive"}
+	}
+
+	logger.LdapLogger.WithContext(tractx).WithFields(groupFields).Info("开始启动限时群组成员添加，获取目标群组信息...")
+	group, err := ldapPool.getGroup(groupId, groupIdType, "", "distinguishedName")
+	if err != nil {
+		return errors.Wrapf(err, "获取群组 %s='%s' 的信息失败", groupIdType, groupId)
+	}
+
+	// 嵌套群组不允许形成循环
+	cycles, err := ldapPool.findMembershipCycles(group.DistinguishedName, userDNs)
+	if err != nil {
+		return errors.Wrap(err, "校验群组嵌套关系失败")
+	}
+	if len(cycles) > 0 {
+		return &ers.MembershipCycleErr{Group: group.DistinguishedName, Member: cycles[0]}
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("开始将以下人员添加到群组，有效期 %s: %s", ttl, userDNs)
+	err = ldapPool.addGroupMembersWithTTL(group.DistinguishedName, version, ttl, userDNs...)
+	if err != nil {
+		return errors.Wrap(err, "执行限时群组成员添加失败")
+	}
+
+	emit(tractx, EventGroupMemberAdded, group.DistinguishedName, map[string]interface{}{"members": userDNs, "ttl": int64(ttl.Seconds())})
 	return nil
 }
 
@@ -231,15 +324,559 @@ func CreateGroup(tractx context.Context, sAMAccountName, OU, displayName, descri
 		return errors.Wrap(err, "创建群组失败")
 	}
 
+	emit(tractx, EventGroupCreated, groupDN, map[string]interface{}{"sAMAccountName": sAMAccountName, "groupType": groupType})
+	return nil
+}
+
+// ApplyChanges 在单次modify请求中对LDAP对象执行增加、删除、替换属性值的变更，version不为空时要求对象的uSNChanged与其一致
+func ApplyChanges(tractx context.Context, dn, version string, changes []AttrChange) error {
+	initLdapPool(tractx)
+	logger.LdapLogger.WithContext(tractx).Infof("正在对 `%s` 执行 %d 项属性变更...", dn, len(changes))
+	if err := ldapPool.applyChanges(dn, version, changes); err != nil {
+		return err
+	}
+
+	var attributes []string
+	for _, change := range changes {
+		attributes = append(attributes, change.Attribute)
+	}
+	emit(tractx, EventObjectUpdated, dn, map[string]interface{}{"attributes": attributes})
 	return nil
 }
 
-// ModifyObj 变更LDAP对象
-func ModifyObj(dn string, replaceAttr map[string][]string) error {
-	return ldapPool.modifyObj(dn, replaceAttr)
+// AddProxyAddress 为用户添加SMTP别名，地址的域名需要是服务支持的域且未被其他对象使用；
+// 用户没有主SMTP地址时，该地址作为主地址并同步到mail，version不为空时要求用户的uSNChanged与其一致
+func AddProxyAddress(tractx context.Context, userDN, version, address string) error {
+	initLdapPool(tractx)
+	address, err := ldapPool.parseSMTPAddress(address)
+	if err != nil {
+		return err
+	}
+
+	user, err := ldapPool.getUser(userDN, "distinguishedName", "", "proxyAddresses", "mail")
+	if err != nil {
+		return err
+	}
+	if indexSMTPAddress(user.ProxyAddresses, address) >= 0 {
+		logger.LdapLogger.WithContext(tractx).Warningf("SMTP地址 `%s` 已存在于 `%s` 中，无需添加", address, userDN)
+		return nil
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在校验SMTP地址 `%s` 的可用性...", address)
+	if err = ldapPool.checkSMTPAddress(userDN, address); err != nil {
+		return err
+	}
+
+	if primarySMTPAddress(user.ProxyAddresses) == "" {
+		return setPrimarySMTP(tractx, userDN, version, address, user.ProxyAddresses)
+	}
+	return ApplyChanges(tractx, userDN, version, []AttrChange{
+		{Op: OpAdd, Attribute: "proxyAddresses", Values: []string{aliasSMTPPrefix + address}},
+	})
+}
+
+// RemoveProxyAddress 移除用户的SMTP别名，不允许移除主SMTP地址，version不为空时要求用户的uSNChanged与其一致
+func RemoveProxyAddress(tractx context.Context, userDN, version, address string) error {
+	initLdapPool(tractx)
+	if strings.HasPrefix(strings.ToLower(address), aliasSMTPPrefix) {
+		address = address[len(aliasSMTPPrefix):]
+	}
+
+	user, err := ldapPool.getUser(userDN, "distinguishedName", "", "proxyAddresses")
+	if err != nil {
+		return err
+	}
+	i := indexSMTPAddress(user.ProxyAddresses, address)
+	if i < 0 {
+		return &ers.NotFoundError{Object: fmt.Sprintf("smtp='%s'", address)}
+	}
+	if strings.HasPrefix(user.ProxyAddresses[i], primarySMTPPrefix) {
+		return &ers.BadRequestErr{Message: fmt.Sprintf("'%s' is the primary smtp address, set another primary address first", address)}
+	}
+
+	return ApplyChanges(tractx, userDN, version, []AttrChange{
+		{Op: OpRemove, Attribute: "proxyAddresses", Values: []string{user.ProxyAddresses[i]}},
+	})
+}
+
+// SetPrimarySMTPAddress 设置用户的主SMTP地址并同步到mail，原主地址保留为别名；
+// 新地址的域名需要是服务支持的域且未被其他对象使用，version不为空时要求用户的uSNChanged与其一致
+func SetPrimarySMTPAddress(tractx context.Context, userDN, version, address string) error {
+	initLdapPool(tractx)
+	address, err := ldapPool.parseSMTPAddress(address)
+	if err != nil {
+		return err
+	}
+
+	user, err := ldapPool.getUser(userDN, "distinguishedName", "", "proxyAddresses", "mail")
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(primarySMTPAddress(user.ProxyAddresses), address) && user.Mail == address {
+		return nil
+	}
+	if indexSMTPAddress(user.ProxyAddresses, address) < 0 {
+		logger.LdapLogger.WithContext(tractx).Infof("正在校验SMTP地址 `%s` 的可用性...", address)
+		if err = ldapPool.checkSMTPAddress(userDN, address); err != nil {
+			return err
+		}
+	}
+	return setPrimarySMTP(tractx, userDN, version, address, user.ProxyAddresses)
+}
+
+func setPrimarySMTP(tractx context.Context, userDN, version, address string, proxyAddresses []string) error {
+	logger.LdapLogger.WithContext(tractx).Infof("正在将 `%s` 的主SMTP地址设置为 `%s` ...", userDN, address)
+	return ApplyChanges(tractx, userDN, version, primarySMTPChanges(proxyAddresses, address))
+}
+
+// SetMailboxQuota 设置用户的邮箱配额，version不为空时要求用户的uSNChanged与其一致
+func SetMailboxQuota(tractx context.Context, userDN, version string, quota MailboxQuota) error {
+	changes, err := quota.changes()
+	if err != nil {
+		return err
+	}
+	return ApplyChanges(tractx, userDN, version, changes)
+}
+
+// SetManager 设置用户的经理，managerDN为空时清除经理；设置后汇报关系形成循环时返回*ers.ManagerCycleErr，
+// version不为空时要求用户的uSNChanged与其一致
+func SetManager(tractx context.Context, userDN, version, managerDN string) error {
+	initLdapPool(tractx)
+	var values []string
+	if managerDN != "" {
+		logger.LdapLogger.WithContext(tractx).Infof("正在校验将 `%s` 设置为 `%s` 的经理是否会形成循环...", managerDN, userDN)
+		if err := ldapPool.checkManagerCycle(userDN, managerDN); err != nil {
+			return err
+		}
+		values = []string{managerDN}
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在将 `%s` 的经理设置为 `%s` ...", userDN, managerDN)
+	return ApplyChanges(tractx, userDN, version, []AttrChange{{Op: OpReplace, Attribute: "manager", Values: values}})
+}
+
+// GetOrgChart 获取用户的汇报关系，向上沿经理链、向下沿直接下属各遍历depth层，遇到循环的汇报关系时停止遍历
+func GetOrgChart(tractx context.Context, userId, userIdType, searchBase string, depth int) (*OrgChart, error) {
+	initLdapPool(tractx)
+	user, err := ldapPool.getUser(userId, userIdType, searchBase, orgAttrs...)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在获取 `%s` 的汇报关系，遍历层数为 %d ...", user.DistinguishedName, depth)
+	chart, err := ldapPool.orgChart(&user, depth)
+	if err != nil {
+		return nil, &ers.SystemErr{Message: err.Error()}
+	}
+	if len(chart.Cycles) > 0 {
+		logger.LdapLogger.WithContext(tractx).Warningf("`%s` 的汇报关系中存在循环：%v", user.DistinguishedName, chart.Cycles)
+	}
+	return chart, nil
+}
+
+// GetUserPhoto 获取用户的照片（thumbnailPhoto），返回照片及用户的DN和版本，用户没有照片时返回*ers.NotFoundError
+func GetUserPhoto(tractx context.Context, userId, userIdType, searchBase string) ([]byte, BaseObject, error) {
+	user, err := initLdapPool(tractx).getUserPhoto(userId, userIdType, searchBase)
+	if err != nil {
+		return nil, BaseObject{}, err
+	}
+	if len(user.ThumbnailPhoto) == 0 {
+		return nil, user.BaseObject, &ers.NotFoundError{Object: fmt.Sprintf("thumbnailPhoto of '%s'", user.DistinguishedName)}
+	}
+	return user.ThumbnailPhoto, user.BaseObject, nil
+}
+
+// SetUserPhoto 校验图片并设置为用户的照片，超过大小或尺寸限制的图片被缩小并压缩为JPEG，返回实际写入的照片；
+// data为空时清除照片，version不为空时要求用户的uSNChanged与其一致
+func SetUserPhoto(tractx context.Context, userDN, version string, data []byte) ([]byte, error) {
+	var values []string
+	if len(data) > 0 {
+		var err error
+		if data, err = photo.Fit(data, config.PhotoConfig.MaxBytes, config.PhotoConfig.MaxDimension); err != nil {
+			return nil, err
+		}
+		values = []string{string(data)}
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在为 `%s` 写入 %d 字节的照片...", userDN, len(data))
+	if err := ApplyChanges(tractx, userDN, version, []AttrChange{{Op: OpReplace, Attribute: "thumbnailPhoto", Values: values}}); err != nil {
+		return nil, err
+	}
+	return data, nil
 }
 
 // CheckAvailability 检查LDAP对象名称可用性
 func CheckAvailability(tractx context.Context, name string) (bool, *BaseObject, error) {
 	return initLdapPool(tractx).checkAvailability(name)
 }
+
+// SuggestNames 按配置的用户名模板，根据名和姓（中文姓名转换为拼音）生成候选用户名，返回前count个可用的用户名
+func SuggestNames(tractx context.Context, givenName, surname string, count int) ([]string, error) {
+	initLdapPool(tractx)
+	candidates := nameCandidates(givenName, surname, config.NamingConfig.Patterns, config.NamingConfig.MaxSuffix)
+	if len(candidates) == 0 {
+		return nil, &ers.BadRequestErr{Message: fmt.Sprintf("cannot generate usernames from '%s %s'", givenName, surname)}
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在校验 %d 个候选用户名的可用性...", len(candidates))
+	names, err := ldapPool.suggestNames(candidates, count)
+	if err != nil {
+		return nil, &ers.SystemErr{Message: err.Error()}
+	}
+	return names, nil
+}
+
+// LookupUsers 按idType批量获取LDAP用户，返回标识到用户的映射以及未找到的标识，可通过attributes指定需要获取的属性
+func LookupUsers(tractx context.Context, ids []string, idType, searchBase string, attributes []string) (map[string]*User, []string, error) {
+	initLdapPool(tractx)
+	logger.LdapLogger.WithContext(tractx).Infof("正在按 %s 批量获取 %d 个用户...", idType, len(ids))
+	found, notFound, err := ldapPool.lookupObjects(func() SpecObject { return &User{} }, memberFilters[MemberUser], ids, idType, searchBase, attributes, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users := make(map[string]*User, len(found))
+	for id, obj := range found {
+		users[id] = obj.(*User)
+	}
+	return users, notFound, nil
+}
+
+// LookupGroups 按idType批量获取LDAP群组，返回标识到群组的映射以及未找到的标识，可通过attributes指定需要获取的属性
+func LookupGroups(tractx context.Context, ids []string, idType, searchBase string, attributes []string) (map[string]*Group, []string, error) {
+	initLdapPool(tractx)
+	logger.LdapLogger.WithContext(tractx).Infof("正在按 %s 批量获取 %d 个群组...", idType, len(ids))
+	found, notFound, err := ldapPool.lookupObjects(func() SpecObject { return &Group{} }, memberFilters[MemberGroup], ids, idType, searchBase, attributes,
+		func(obj SpecObject, conn *pooledLdapConn) (err error) {
+			// 群组成员需要递归获取，复用当前连接单独搜索群组成员
+			group := obj.(*Group)
+			if wantAttr("member", attributes) {
+				group.Member, err = ldapPool.getGroupMembers(conn, group.DistinguishedName, "distinguishedName", 0, []string{})
+			}
+			return err
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groups := make(map[string]*Group, len(found))
+	for id, obj := range found {
+		groups[id] = obj.(*Group)
+	}
+	return groups, notFound, nil
+}
+
+// SearchPeople 通过ANR按名称、登录名、邮件搜索用户、群组及联系人，返回按匹配程度排序的前limit个结果；
+// 从目录中获取有限数量的匹配对象并排序，保留前配置的数量上限个结果并在短时间内缓存
+func SearchPeople(tractx context.Context, q string, limit int) ([]Person, error) {
+	initLdapPool(tractx)
+	key := strings.ToLower(q)
+	people, ok := peopleCache.Get(key)
+	if !ok {
+		var err error
+		if people, err = ldapPool.searchPeople(q, config.TypeaheadConfig.SizeLimit); err != nil {
+			return nil, &ers.SystemErr{Message: err.Error()}
+		}
+		peopleCache.Set(key, people)
+	}
+
+	if len(people) > limit {
+		people = people[:limit]
+	}
+	return people, nil
+}
+
+// SearchUsers 分页搜索所有匹配过滤条件的LDAP用户，每获取一个用户调用一次fn
+func SearchUsers(tractx context.Context, filter, searchBase string, attributes []string, fn func(*User) error) error {
+	if err := checkFilter(filter); err != nil {
+		return err
+	}
+	return initLdapPool(tractx).searchUsers(filter, searchBase, attributes, fn)
+}
+
+// SearchGroups 分页搜索所有匹配过滤条件的LDAP群组，每获取一个群组调用一次fn
+func SearchGroups(tractx context.Context, filter, searchBase string, attributes []string, fn func(*Group) error) error {
+	if err := checkFilter(filter); err != nil {
+		return err
+	}
+	return initLdapPool(tractx).searchGroups(filter, searchBase, attributes, fn)
+}
+
+// HighestCommittedUSN 获取当前DC已提交的最大USN
+func HighestCommittedUSN(tractx context.Context) (int64, error) {
+	return initLdapPool(tractx).highestCommittedUSN()
+}
+
+// MemberChangesSince 获取群组本地USN大于since的成员增加及移除，基于链接值的复制元数据，不需要保存群组成员的快照
+func MemberChangesSince(tractx context.Context, groupDN string, since int64) ([]MemberChange, error) {
+	return initLdapPool(tractx).memberChangesSince(groupDN, since)
+}
+
+// SearchDeleted 分页搜索匹配过滤条件的已删除对象，要求服务账号有权限读取Deleted Objects容器
+func SearchDeleted(tractx context.Context, filter string, fn func(*DeletedObject) error) error {
+	if err := checkFilter(filter); err != nil {
+		return err
+	}
+	return initLdapPool(tractx).searchDeleted(filter, fn)
+}
+
+// AddObj 新增LDAP对象
+func AddObj(tractx context.Context, dn string, attrs map[string][]string) error {
+	initLdapPool(tractx)
+	logger.LdapLogger.WithContext(tractx).Infof("正在新增对象 `%s` ...", dn)
+	if err := ldapPool.addObj(dn, attrs); err != nil {
+		return err
+	}
+	emit(tractx, EventObjectCreated, dn, nil)
+	return nil
+}
+
+// DeleteObj 删除LDAP对象，version不为空时要求对象的uSNChanged与其一致
+func DeleteObj(tractx context.Context, dn, version string) error {
+	initLdapPool(tractx)
+	logger.LdapLogger.WithContext(tractx).Warningf("正在删除对象 `%s` ...", dn)
+	if err := ldapPool.deleteObj(dn, version); err != nil {
+		return err
+	}
+	emit(tractx, EventObjectDeleted, dn, nil)
+	return nil
+}
+
+// RenameObj 重命名LDAP对象，newSuperior不为空时同时移动对象
+func RenameObj(tractx context.Context, dn, newRDN string, deleteOldRDN bool, newSuperior string) error {
+	initLdapPool(tractx)
+	logger.LdapLogger.WithContext(tractx).Infof("正在将对象 `%s` 重命名为 `%s` ...", dn, newRDN)
+	if err := ldapPool.renameObj(dn, newRDN, deleteOldRDN, newSuperior); err != nil {
+		return err
+	}
+	emit(tractx, EventObjectRenamed, dn, map[string]interface{}{"newRDN": newRDN, "newSuperior": newSuperior})
+	return nil
+}
+
+// ObjectExists 检查指定DN的LDAP对象是否存在
+func ObjectExists(tractx context.Context, dn string) (bool, error) {
+	initLdapPool(tractx)
+	conn, err := ldapPool.getConn()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	_, err = ldapPool.currentVersion(conn, dn)
+	var notFoundError *ers.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GUIDFilterValue 将字符串格式的objectGUID转换为可直接用于LDAP过滤条件的转义值
+func GUIDFilterValue(guid string) (string, error) {
+	raw, err := unFormatGUID(guid)
+	if err != nil {
+		return "", &ers.InvalidFormatErr{Name: "objectGUID", Object: guid}
+	}
+	return ldap.EscapeFilter(raw), nil
+}
+
+// ValidDN 校验DN格式是否合法
+func ValidDN(dn string) bool {
+	_, err := ldap.ParseDN(dn)
+	return dn != "" && err == nil
+}
+
+// CreateComputer 在OU中预创建计算机账户，计算机加入域时使用该账户
+func CreateComputer(tractx context.Context, name, OU, dnsHostName, description string) error {
+	initLdapPool(tractx)
+	computerFields := logrus.Fields{
+		"name":        name,
+		"OU":          OU,
+		"dNSHostName": dnsHostName,
+	}
+
+	// 计算机账户的sAMAccountName以$结尾，需要校验带$的名称是否被占用
+	logger.LdapLogger.WithContext(tractx).WithFields(computerFields).Info("开始启动计算机账户预创建，校验计算机名可用性...")
+	ok, _, err := ldapPool.checkAvailability(ComputerAccountName(name))
+	if err != nil {
+		return errors.Wrapf(err, "校验计算机名 `%s` 的可用性失败", name)
+	}
+	if !ok {
+		return &ers.ObjExistError{Object: fmt.Sprintf("sAMAccountName='%s'", ComputerAccountName(name))}
+	}
+
+	computerDN := fmt.Sprintf("CN=%s,%s", name, OU)
+
+	logger.LdapLogger.WithContext(tractx).Infof("校验通过，开始创建计算机对象 `%s` ...", computerDN)
+	if err = ldapPool.createComputer(computerDN, dnsHostName, description); err != nil {
+		return err
+	}
+
+	emit(tractx, EventComputerCreated, computerDN, map[string]interface{}{"sAMAccountName": ComputerAccountName(name), "dNSHostName": dnsHostName})
+	return nil
+}
+
+// GetComputer 获取LDAP计算机信息，可通过attributes指定需要获取的属性
+func GetComputer(tractx context.Context, computerId, computerIdType, searchBase string, attributes ...string) (Computer, error) {
+	return initLdapPool(tractx).getComputer(computerId, computerIdType, searchBase, attributes...)
+}
+
+// DisableComputer 禁用计算机账户，保留userAccountControl中的其他标志位；version不为空时要求计算机的uSNChanged与其一致
+func DisableComputer(tractx context.Context, computerDN, version string) error {
+	initLdapPool(tractx)
+	computer, err := ldapPool.getComputer(computerDN, "distinguishedName", "", "userAccountControl")
+	if err != nil {
+		return err
+	}
+	if version != "" && version != computer.USNChanged {
+		return &ers.PreconditionFailedErr{Object: computerDN, Version: version}
+	}
+
+	uac, err := strconv.Atoi(computer.UserAccountControl)
+	if err != nil {
+		return &ers.InvalidFormatErr{Name: "userAccountControl", Object: computer.UserAccountControl}
+	}
+	if uac&UACAccountDisable != 0 {
+		logger.LdapLogger.WithContext(tractx).Warningf("计算机 `%s` 已处于禁用状态，跳过禁用", computerDN)
+		return nil
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在禁用计算机 `%s` ...", computerDN)
+	change := AttrChange{Op: OpReplace, Attribute: "userAccountControl", Values: []string{strconv.Itoa(uac | UACAccountDisable)}}
+	if err = ldapPool.applyChanges(computerDN, version, []AttrChange{change}); err != nil {
+		return err
+	}
+
+	emit(tractx, EventComputerDisabled, computerDN, nil)
+	return nil
+}
+
+// SearchComputers 分页搜索所有匹配过滤条件的LDAP计算机，每获取一个计算机调用一次fn
+func SearchComputers(tractx context.Context, filter, searchBase string, attributes []string, fn func(*Computer) error) error {
+	if err := checkFilter(filter); err != nil {
+		return err
+	}
+	return initLdapPool(tractx).searchComputers(filter, searchBase, attributes, fn)
+}
+
+// CreateContact 创建邮件联系人，邮件地址不能被其他对象使用
+func CreateContact(tractx context.Context, contact NewContact) error {
+	initLdapPool(tractx)
+	contactFields := logrus.Fields{
+		"name": contact.Name,
+		"OU":   contact.OU,
+		"mail": contact.Mail,
+	}
+
+	logger.LdapLogger.WithContext(tractx).WithFields(contactFields).Info("开始启动联系人创建，校验邮件地址可用性...")
+	ok, _, err := ldapPool.checkMailAvailability(contact.Mail)
+	if err != nil {
+		return errors.Wrapf(err, "校验邮件地址 `%s` 的可用性失败", contact.Mail)
+	}
+	if !ok {
+		return &ers.ObjExistError{Object: fmt.Sprintf("mail='%s'", contact.Mail)}
+	}
+
+	contactDN := fmt.Sprintf("CN=%s,%s", contact.Name, contact.OU)
+
+	logger.LdapLogger.WithContext(tractx).Infof("校验通过，开始创建联系人对象 `%s` ...", contactDN)
+	if err = ldapPool.createContact(contactDN, contact); err != nil {
+		return err
+	}
+
+	emit(tractx, EventContactCreated, contactDN, map[string]interface{}{"mail": contact.Mail, "displayName": contact.DisplayName})
+	return nil
+}
+
+// GetContact 获取LDAP联系人信息，可通过attributes指定需要获取的属性
+func GetContact(tractx context.Context, contactId, contactIdType, searchBase string, attributes ...string) (Contact, error) {
+	return initLdapPool(tractx).getContact(contactId, contactIdType, searchBase, attributes...)
+}
+
+// GetMember 解析群组成员，成员可以是用户、联系人或群组；memberId可以使用 "user:"、"contact:"、"group:" 前缀指定成员类型，
+// 未指定时按objectClass自动识别
+func GetMember(tractx context.Context, memberId, memberIdType, searchBase string) (BaseObject, error) {
+	return initLdapPool(tractx).getMember(memberId, memberIdType, searchBase)
+}
+
+// CheckMembershipCycle 校验将成员添加到群组后是否会形成循环嵌套，存在循环时返回*ers.MembershipCycleErr
+func CheckMembershipCycle(tractx context.Context, groupDN string, memberDNs ...string) error {
+	cycles, err := initLdapPool(tractx).findMembershipCycles(groupDN, memberDNs)
+	if err != nil {
+		return err
+	}
+	if len(cycles) > 0 {
+		return &ers.MembershipCycleErr{Group: groupDN, Member: cycles[0]}
+	}
+	return nil
+}
+
+// Authenticate 校验用户凭据，username为sAMAccountName或userPrincipalName，用户不存在或密码错误时返回*ers.UnauthorizedErr；
+// 认证成功的凭据在AuthCacheTTL内不再重复绑定LDAP，同一用户名连续认证失败过多时暂时拒绝认证
+func Authenticate(tractx context.Context, username, password string) (User, error) {
+	initLdapPool(tractx)
+	if err := authFailures.check(username); err != nil {
+		return User{}, err
+	}
+	key := authKey(username, password)
+	if user, ok := authCache.Get(key); ok {
+		return user, nil
+	}
+
+	usernameType := "sAMAccountName"
+	if strings.Contains(username, "@") {
+		usernameType = "userPrincipalName"
+	}
+
+	user, err := ldapPool.getUser(username, usernameType, "", "distinguishedName", "sAMAccountName")
+	var notFoundError *ers.NotFoundError
+	if errors.As(err, &notFoundError) {
+		authFailures.fail(username)
+		// 与密码错误返回相同的信息，避免泄露用户是否存在
+		return user, &ers.UnauthorizedErr{Message: "invalid username or password"}
+	}
+	if err != nil {
+		return user, err
+	}
+
+	if err = ldapPool.verifyPassword(user.DistinguishedName, password); err != nil {
+		var unauthorizedErr *ers.UnauthorizedErr
+		if errors.As(err, &unauthorizedErr) {
+			authFailures.fail(username)
+		}
+		logger.LdapLogger.WithContext(tractx).Warningf("用户 `%s` 身份认证失败: %v", user.DistinguishedName, err)
+		return user, err
+	}
+	authFailures.reset(username)
+	authCache.Set(key, user)
+	return user, nil
+}
+
+// SetGroupOwner 设置群组所有者，ownerDN为空时清空所有者；version不为空时要求群组的uSNChanged与其一致
+func SetGroupOwner(tractx context.Context, groupDN, ownerDN, version string) error {
+	return setGroupManagers(tractx, groupDN, "managedBy", version, ownerDN)
+}
+
+// SetGroupCoManagers 将群组的共同管理者替换为managerDNs，为空时清空共同管理者；version不为空时要求群组的uSNChanged与其一致
+func SetGroupCoManagers(tractx context.Context, groupDN, version string, managerDNs ...string) error {
+	return setGroupManagers(tractx, groupDN, "msExchCoManagedByLink", version, managerDNs...)
+}
+
+// 替换群组的所有者或共同管理者属性，空值代表清空
+func setGroupManagers(tractx context.Context, groupDN, attribute, version string, dns ...string) error {
+	initLdapPool(tractx)
+	var values []string
+	for _, dn := range dns {
+		if dn != "" {
+			values = append(values, dn)
+		}
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在将群组 `%s` 的 %s 设置为: %s", groupDN, attribute, values)
+	change := AttrChange{Op: OpReplace, Attribute: attribute, Values: values}
+	if err := ldapPool.applyChanges(groupDN, version, []AttrChange{change}); err != nil {
+		return err
+	}
+
+	emit(tractx, EventGroupOwnerChanged, groupDN, map[string]interface{}{attribute: values})
+	return nil
+}
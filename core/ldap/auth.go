@@ -0,0 +1,175 @@
+package ldap
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/go-ldap/ldap"
+	"ldap-http-service/config"
+	"ldap-http-service/lib/cache"
+	"ldap-http-service/lib/ers"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CallerKey 上下文中已认证调用方DN的键，HTTP、gRPC请求及批量任务以owner模式执行时设置
+const CallerKey = "caller"
+
+// 认证缓存的最大条目数
+const authCacheEntries = 10000
+
+var (
+	// 认证成功的凭据缓存，键为以进程内随机密钥计算的凭据HMAC，内存中不保存明文密码或可离线破解的摘要
+	authCache  *cache.Cache[User]
+	authSecret = make([]byte, 32)
+
+	authFailures = &failureCounter{records: make(map[string]*failureRecord)}
+)
+
+// 初始化认证缓存，对象被写入后使其缓存的认证结果失效，例如修改密码、禁用、移动或删除用户
+func initAuth() {
+	_, _ = rand.Read(authSecret)
+	authCache = cache.New[User](authCacheEntries, config.OwnerConfig.AuthCacheTTL)
+	AddWriteHook(func(event WriteEvent) {
+		authCache.DeleteFunc(func(_ string, user User) bool {
+			return affectedDN(user.DistinguishedName, []string{event.DN})
+		})
+	})
+}
+
+// 计算凭据的缓存键
+func authKey(username, password string) string {
+	mac := hmac.New(sha256.New, authSecret)
+	mac.Write([]byte(strings.ToLower(username) + "\x00" + password))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type failureRecord struct {
+	count int
+	since time.Time
+}
+
+// failureCounter 按用户名统计窗口内的认证失败次数
+type failureCounter struct {
+	mutex   sync.Mutex
+	records map[string]*failureRecord
+}
+
+// 用户名在窗口内的失败次数达到上限时返回错误，窗口结束后自动解除
+func (f *failureCounter) check(username string) error {
+	if config.OwnerConfig.MaxFailures <= 0 {
+		return nil
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	record, ok := f.records[strings.ToLower(username)]
+	if !ok {
+		return nil
+	}
+	if time.Since(record.since) > config.OwnerConfig.FailureWindow {
+		delete(f.records, strings.ToLower(username))
+		return nil
+	}
+	if record.count >= config.OwnerConfig.MaxFailures {
+		return &ers.UnauthorizedErr{Message: "too many failed attempts, please try again later"}
+	}
+	return nil
+}
+
+// 记录一次认证失败，记录数过多时清理已过期的记录
+func (f *failureCounter) fail(username string) {
+	if config.OwnerConfig.MaxFailures <= 0 {
+		return
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.records) >= authCacheEntries {
+		for key, record := range f.records {
+			if time.Since(record.since) > config.OwnerConfig.FailureWindow {
+				delete(f.records, key)
+			}
+		}
+	}
+	record, ok := f.records[strings.ToLower(username)]
+	if !ok || time.Since(record.since) > config.OwnerConfig.FailureWindow {
+		record = &failureRecord{since: time.Now()}
+		f.records[strings.ToLower(username)] = record
+	}
+	record.count++
+}
+
+// 认证成功后清除失败记录
+func (f *failureCounter) reset(username string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.records, strings.ToLower(username))
+}
+
+// CallerDN 返回上下文中已认证调用方的DN，以服务身份执行时返回空字符串
+func CallerDN(tractx context.Context) string {
+	caller, _ := tractx.Value(CallerKey).(string)
+	return caller
+}
+
+// RequireCaller 配置要求成员变更必须认证时，以服务身份执行返回*ers.UnauthorizedErr
+func RequireCaller(tractx context.Context) error {
+	if config.OwnerConfig.RequireAuth && CallerDN(tractx) == "" {
+		return &ers.UnauthorizedErr{Message: "changing group members requires the credentials of a group owner"}
+	}
+	return nil
+}
+
+// AuthorizeMemberChange 校验调用方是否可以变更群组成员，group需要包含managedBy及msExchCoManagedByLink属性；
+// 以服务身份执行时只受RequireAuth限制，owner模式下调用方需要是群组的所有者、共同管理者，
+// 或者是作为所有者、共同管理者的群组的直接或间接成员
+func AuthorizeMemberChange(tractx context.Context, group *Group) error {
+	caller := CallerDN(tractx)
+	if caller == "" {
+		return RequireCaller(tractx)
+	}
+
+	managed, err := initLdapPool(tractx).isGroupManager(group, caller)
+	if err != nil {
+		return err
+	}
+	if !managed {
+		return &ers.ForbiddenErr{Message: fmt.Sprintf("'%s' is not an owner of group '%s'", caller, group.DistinguishedName)}
+	}
+	return nil
+}
+
+// 判断dn是否为群组的所有者或共同管理者，所有者或共同管理者为群组时，其直接或间接成员同样视为管理者
+func (l *ldapConnPool) isGroupManager(group *Group, dn string) (bool, error) {
+	if group.IsManagedBy(dn) {
+		return true, nil
+	}
+
+	owners := append([]string{}, group.MsExchCoManagedByLink...)
+	if group.ManagedBy != "" {
+		owners = append(owners, group.ManagedBy)
+	}
+	if len(owners) == 0 {
+		return false, nil
+	}
+
+	var b strings.Builder
+	for _, owner := range owners {
+		b.WriteString(fmt.Sprintf("(distinguishedName=%s)", ldap.EscapeFilter(owner)))
+	}
+	filter := fmt.Sprintf("(&%s(|%s)(member:%s:=%s))", memberFilters[MemberGroup], b.String(), matchingRuleInChain, ldap.EscapeFilter(dn))
+
+	managed := false
+	err := l.searchLdapObjects(func() SpecObject { return &BaseObject{} }, filter, "", []string{"distinguishedName"},
+		func(SpecObject, *pooledLdapConn) error {
+			managed = true
+			return nil
+		},
+	)
+	return managed, err
+}
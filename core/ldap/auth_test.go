@@ -0,0 +1,78 @@
+package ldap
+
+import (
+	"ldap-http-service/config"
+	"testing"
+	"time"
+)
+
+func TestAuthKey(t *testing.T) {
+	base := authKey("alice", "secret")
+	tests := []struct {
+		name     string
+		username string
+		password string
+		same     bool
+	}{
+		{name: "same credentials", username: "alice", password: "secret", same: true},
+		{name: "username is case insensitive", username: "ALICE", password: "secret", same: true},
+		{name: "password is case sensitive", username: "alice", password: "Secret"},
+		{name: "username and password boundary", username: "alices", password: "ecret"},
+		{name: "other user", username: "bob", password: "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := authKey(tt.username, tt.password); (got == base) != tt.same {
+				t.Errorf("authKey(%q, %q) equal to base = %v, want %v", tt.username, tt.password, got == base, tt.same)
+			}
+		})
+	}
+}
+
+func TestFailureCounter(t *testing.T) {
+	saved := config.OwnerConfig
+	defer func() { config.OwnerConfig = saved }()
+
+	tests := []struct {
+		name        string
+		maxFailures int
+		window      time.Duration
+		failures    int
+		since       time.Duration
+		reset       bool
+		wantBlocked bool
+	}{
+		{name: "below the limit", maxFailures: 3, window: time.Minute, failures: 2},
+		{name: "limit reached", maxFailures: 3, window: time.Minute, failures: 3, wantBlocked: true},
+		{name: "window ended", maxFailures: 3, window: time.Minute, failures: 3, since: 2 * time.Minute},
+		{name: "reset after success", maxFailures: 3, window: time.Minute, failures: 3, reset: true},
+		{name: "disabled", maxFailures: 0, window: time.Minute, failures: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.OwnerConfig.MaxFailures = tt.maxFailures
+			config.OwnerConfig.FailureWindow = tt.window
+			f := &failureCounter{records: make(map[string]*failureRecord)}
+
+			for i := 0; i < tt.failures; i++ {
+				f.fail("Alice")
+			}
+			if record, ok := f.records["alice"]; ok {
+				record.since = record.since.Add(-tt.since)
+			}
+			if tt.reset {
+				f.reset("ALICE")
+			}
+
+			// 用户名不区分大小写
+			if err := f.check("alice"); (err != nil) != tt.wantBlocked {
+				t.Errorf("check() error = %v, want blocked %v", err, tt.wantBlocked)
+			}
+			if err := f.check("bob"); err != nil {
+				t.Errorf("check() of another user error = %v", err)
+			}
+		})
+	}
+}
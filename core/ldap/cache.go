@@ -0,0 +1,131 @@
+package ldap
+
+import (
+	"context"
+	"ldap-http-service/config"
+	"ldap-http-service/lib/cache"
+	"ldap-http-service/lib/utils"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// 变更后会影响其他对象反向链接属性（例如memberOf、directReports）的属性，这些属性变更时清空全部缓存
+var linkedAttrs = []string{"member", "manager", "managedBy", "msExchCoManagedByLink"}
+
+var (
+	// 用户及群组查询结果缓存，键由标识类型、标识、搜索基准及属性列表组成
+	userCache  *cache.Cache[User]
+	groupCache *cache.Cache[Group]
+
+	// 缓存失效的次数，查询开始后发生过失效时不写入查询结果，避免旧数据覆盖失效操作
+	cacheGeneration atomic.Uint64
+)
+
+// 初始化查询缓存，未启用时缓存不保存任何条目
+func initCache() {
+	maxEntries := 0
+	if config.CacheConfig.Enabled {
+		maxEntries = config.CacheConfig.MaxEntries
+	}
+	userCache = cache.New[User](maxEntries, config.CacheConfig.TTL)
+	groupCache = cache.New[Group](maxEntries, config.CacheConfig.TTL)
+	if config.CacheConfig.Enabled {
+		AddWriteHook(invalidateOnWrite)
+	}
+}
+
+// 生成缓存键，目录中的标识及属性名均不区分大小写，属性列表与顺序无关
+func cacheKey(id, idType, searchBase string, attributes []string) string {
+	attrs := make([]string, 0, len(attributes))
+	for _, attr := range attributes {
+		attrs = append(attrs, strings.ToLower(attr))
+	}
+	sort.Strings(attrs)
+	return strings.ToLower(idType) + "=" + strings.ToLower(id) + "|" + strings.ToLower(searchBase) + "|" + strings.Join(attrs, ",")
+}
+
+// noCacheKey 上下文中标记跳过缓存读取的键
+type noCacheKey struct{}
+
+// 请求是否要求跳过缓存，例如请求头中包含Cache-Control: no-cache
+func noCache(tractx context.Context) bool {
+	skip, _ := tractx.Value(noCacheKey{}).(bool)
+	return skip
+}
+
+// WithoutCache 返回跳过缓存读取的上下文，用于写入前的校验、权限判断等需要目录最新状态的读取
+func WithoutCache(tractx context.Context) context.Context {
+	return context.WithValue(tractx, noCacheKey{}, true)
+}
+
+// 优先从缓存中读取对象，未命中时调用fetch并在期间没有发生缓存失效时写入缓存；要求跳过缓存的请求仍会刷新缓存
+func cachedGet[T any](tractx context.Context, c *cache.Cache[T], key string, fetch func() (T, error)) (T, error) {
+	if !noCache(tractx) {
+		if obj, ok := c.Get(key); ok {
+			return obj, nil
+		}
+	}
+
+	generation := cacheGeneration.Load()
+	obj, err := fetch()
+	if err == nil && cacheGeneration.Load() == generation {
+		c.Set(key, obj)
+	}
+	return obj, err
+}
+
+// 判断dn是否为dns中的某个对象或其子对象
+func affectedDN(dn string, dns []string) bool {
+	for _, target := range dns {
+		if target == "" {
+			continue
+		}
+		if strings.EqualFold(dn, target) || strings.HasSuffix(strings.ToLower(dn), ","+strings.ToLower(target)) {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidateCache 使指定对象及其子对象的缓存失效
+func InvalidateCache(dns ...string) {
+	cacheGeneration.Add(1)
+	userCache.DeleteFunc(func(_ string, user User) bool {
+		return affectedDN(user.DistinguishedName, dns)
+	})
+	groupCache.DeleteFunc(func(_ string, group Group) bool {
+		return affectedDN(group.DistinguishedName, dns)
+	})
+}
+
+// 清空全部缓存
+func purgeCache() {
+	cacheGeneration.Add(1)
+	userCache.Purge()
+	groupCache.Purge()
+}
+
+// 本服务写入对象后使相关缓存失效：成员变更同时影响成员的memberOf；
+// 移动、重命名、删除对象以及变更链接属性（包括群组的所有者及共同管理者）会影响其他对象的反向链接属性，直接清空全部缓存
+func invalidateOnWrite(event WriteEvent) {
+	switch event.Type {
+	case EventObjectMoved, EventObjectRenamed, EventObjectDeleted, EventGroupOwnerChanged:
+		purgeCache()
+		return
+	case EventObjectUpdated:
+		attributes, _ := event.Data["attributes"].([]string)
+		for _, attr := range attributes {
+			if utils.InSliceIC(linkedAttrs, attr) {
+				purgeCache()
+				return
+			}
+		}
+	}
+
+	dns := []string{event.DN}
+	if members, ok := event.Data["members"].([]string); ok {
+		dns = append(dns, members...)
+	}
+	InvalidateCache(dns...)
+}
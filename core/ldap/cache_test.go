@@ -0,0 +1,121 @@
+package ldap
+
+import (
+	"context"
+	"ldap-http-service/lib/cache"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+const (
+	cacheAlice = "CN=Alice,OU=Users,DC=example,DC=com"
+	cacheBob   = "CN=Bob,OU=Users,DC=example,DC=com"
+	cacheSales = "CN=Sales,OU=Groups,DC=example,DC=com"
+)
+
+// 以缓存的用户及群组初始化缓存
+func fillCache() {
+	userCache = cache.New[User](100, time.Hour)
+	groupCache = cache.New[Group](100, time.Hour)
+	for _, dn := range []string{cacheAlice, cacheBob} {
+		userCache.Set(dn, User{BaseObject: BaseObject{DistinguishedName: dn}})
+	}
+	groupCache.Set(cacheSales, Group{BaseObject: BaseObject{DistinguishedName: cacheSales}})
+}
+
+// 返回仍在缓存中的对象DN
+func cachedDNs() []string {
+	var dns []string
+	for _, dn := range []string{cacheAlice, cacheBob} {
+		if _, ok := userCache.Get(dn); ok {
+			dns = append(dns, dn)
+		}
+	}
+	if _, ok := groupCache.Get(cacheSales); ok {
+		dns = append(dns, cacheSales)
+	}
+	sort.Strings(dns)
+	return dns
+}
+
+func TestInvalidateOnWrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		event WriteEvent
+		want  []string
+	}{
+		{
+			name:  "object updated",
+			event: WriteEvent{Type: EventObjectUpdated, DN: cacheAlice, Data: map[string]interface{}{"attributes": []string{"displayName"}}},
+			want:  []string{cacheBob, cacheSales},
+		},
+		{
+			name:  "linked attribute updated",
+			event: WriteEvent{Type: EventObjectUpdated, DN: cacheAlice, Data: map[string]interface{}{"attributes": []string{"description", "Manager"}}},
+		},
+		{
+			name:  "members changed invalidates their memberOf",
+			event: WriteEvent{Type: EventGroupMemberAdded, DN: cacheSales, Data: map[string]interface{}{"members": []string{cacheBob}}},
+			want:  []string{cacheAlice},
+		},
+		{
+			name:  "dn is case insensitive",
+			event: WriteEvent{Type: EventUserPasswordSet, DN: "cn=bob,ou=users,dc=example,dc=com"},
+			want:  []string{cacheAlice, cacheSales},
+		},
+		{
+			name:  "children of an ou",
+			event: WriteEvent{Type: EventObjectUpdated, DN: "OU=Users,DC=example,DC=com"},
+			want:  []string{cacheSales},
+		},
+		{name: "object moved", event: WriteEvent{Type: EventObjectMoved, DN: cacheAlice}},
+		{name: "object renamed", event: WriteEvent{Type: EventObjectRenamed, DN: cacheAlice}},
+		{name: "object deleted", event: WriteEvent{Type: EventObjectDeleted, DN: cacheBob}},
+		{name: "owner changed", event: WriteEvent{Type: EventGroupOwnerChanged, DN: cacheSales}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fillCache()
+			generation := cacheGeneration.Load()
+			invalidateOnWrite(tt.event)
+
+			if got := cachedDNs(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cached = %v, want %v", got, tt.want)
+			}
+			if cacheGeneration.Load() == generation {
+				t.Error("cache generation did not change")
+			}
+		})
+	}
+}
+
+func TestCachedGet(t *testing.T) {
+	fillCache()
+	fetched := 0
+	fetch := func() (User, error) {
+		fetched++
+		return User{BaseObject: BaseObject{DistinguishedName: cacheAlice, Description: "fresh"}}, nil
+	}
+
+	// 命中缓存时不读取目录
+	if user, _ := cachedGet(context.Background(), userCache, cacheAlice, fetch); user.Description != "" || fetched != 0 {
+		t.Fatalf("cachedGet() = %q after %d fetches, want the cached user", user.Description, fetched)
+	}
+
+	// 跳过缓存时读取目录，并刷新缓存
+	if user, _ := cachedGet(WithoutCache(context.Background()), userCache, cacheAlice, fetch); user.Description != "fresh" || fetched != 1 {
+		t.Fatalf("cachedGet() without cache = %q after %d fetches, want the fresh user", user.Description, fetched)
+	}
+	if user, _ := userCache.Get(cacheAlice); user.Description != "fresh" {
+		t.Errorf("cache was not refreshed, got %q", user.Description)
+	}
+
+	// 只有使用WithoutCache设置的标记生效，同名的字符串键不会跳过缓存
+	ctx := context.WithValue(context.Background(), "no_cache", true)
+	if noCache(ctx) {
+		t.Error("noCache() = true for a string key")
+	}
+}
@@ -21,6 +21,16 @@ type ldapConnPool struct {
 	pool     chan *pooledLdapConn
 	counter  int
 	mutex    sync.Mutex
+
+	// 服务端是否支持断言控件，首次使用时通过rootDSE检测，检测失败时下次使用重新检测
+	assertionMutex     sync.Mutex
+	assertionChecked   bool
+	assertionSupported bool
+
+	// 林中是否启用了特权访问管理（PAM）功能，启用后群组成员支持TTL，首次使用时检测，检测失败时下次使用重新检测
+	pamMutex   sync.Mutex
+	pamChecked bool
+	pamEnabled bool
 }
 
 type pooledLdapConn struct {
@@ -47,20 +57,47 @@ func (c *pooledLdapConn) IsAlive() bool {
 	}
 }
 
-func (l *ldapConnPool) newConn(SN int) (*pooledLdapConn, error) {
+func (l *ldapConnPool) dial() (*ldap.Conn, error) {
 	address := fmt.Sprintf("%s:%d", l.Host, l.Port)
-	ldapConn, err := ldap.DialTLS("tcp", address, &tls.Config{InsecureSkipVerify: true})
+	return ldap.DialTLS("tcp", address, &tls.Config{InsecureSkipVerify: true})
+}
+
+func (l *ldapConnPool) newConn(SN int) (*pooledLdapConn, error) {
+	ldapConn, err := l.dial()
 	if err != nil {
 		return nil, err
 	}
 
 	err = ldapConn.Bind(l.username, l.password)
 	if err != nil {
+		ldapConn.Close()
 		return nil, err
 	}
 	return &pooledLdapConn{ldapConn, l, SN}, nil
 }
 
+// 使用独立的连接以指定用户的身份绑定，校验用户密码，不影响连接池中服务账号的连接
+func (l *ldapConnPool) verifyPassword(dn, password string) error {
+	// 空密码会被服务端视为匿名绑定而成功，需要提前拒绝
+	if password == "" {
+		return &ers.UnauthorizedErr{Message: "password is required"}
+	}
+
+	ldapConn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer ldapConn.Close()
+
+	if err = ldapConn.Bind(dn, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return &ers.UnauthorizedErr{Message: "invalid username or password"}
+		}
+		return &ers.OptErr{Option: "verify user credentials", Message: err.Error()}
+	}
+	return nil
+}
+
 func (l *ldapConnPool) getConn() (conn *pooledLdapConn, err error) {
 	timeOut := 5 * time.Second
 	timeout, cancel := context.WithTimeout(context.Background(), timeOut)
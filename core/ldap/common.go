@@ -7,6 +7,7 @@ import (
 	"ldap-http-service/lib/ers"
 	"ldap-http-service/lib/utils"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -20,6 +21,12 @@ func (ft FileTime) MarshalJSON() ([]byte, error) {
 	return []byte(stamp), nil
 }
 
+func (ft *FileTime) UnmarshalJSON(data []byte) error {
+	t, err := unmarshalTime(data)
+	*ft = FileTime(t)
+	return err
+}
+
 // GeneralizedTime 定义结构体用于Windows中GeneralizedTime类型的时间字段的处理
 type GeneralizedTime time.Time
 
@@ -29,6 +36,25 @@ func (gt GeneralizedTime) MarshalJSON() ([]byte, error) {
 	return []byte(stamp), nil
 }
 
+func (gt *GeneralizedTime) UnmarshalJSON(data []byte) error {
+	t, err := unmarshalTime(data)
+	*gt = GeneralizedTime(t)
+	return err
+}
+
+// 解析MarshalJSON输出的RFC3339时间，null或空字符串为零值
+func unmarshalTime(data []byte) (time.Time, error) {
+	stamp := string(data)
+	if stamp == "null" || stamp == `""` {
+		return time.Time{}, nil
+	}
+	unquoted, err := strconv.Unquote(stamp)
+	if err != nil {
+		return time.Time{}, &ers.InvalidFormatErr{Name: "time", Object: stamp}
+	}
+	return time.Parse(time.RFC3339, unquoted)
+}
+
 // BaseObject ldap对象基础结构体，包含AD域中ldap对象通用的ldap属性
 type BaseObject struct {
 	Name              string          `ldap:"name" json:"name"`
@@ -42,6 +68,16 @@ type BaseObject struct {
 	ObjectCategory    string          `ldap:"objectCategory" json:"objectCategory"`
 	ObjectGUID        string          `ldap:"objectGUID" json:"objectGUID"`
 	ObjectSid         string          `ldap:"objectSid" json:"objectSid"`
+	USNChanged        string          `ldap:"uSNChanged" json:"uSNChanged"`
+	USNCreated        string          `ldap:"uSNCreated" json:"uSNCreated"`
+}
+
+// ETag 基于uSNChanged生成对象的版本标识，用于乐观并发控制
+func (b *BaseObject) ETag() string {
+	if b.USNChanged == "" {
+		return ""
+	}
+	return fmt.Sprintf("\"%s\"", b.USNChanged)
 }
 
 // SpecObject 定义接口，表示具体的Ldap对象：如用户、群组、计算机等，所有具体的Ldap对象类型都需要嵌套BaseObject
@@ -81,7 +117,7 @@ func (l *ldapConnPool) checkAvailability(name string) (bool, *BaseObject, error)
 	}
 	filter = fmt.Sprintf("(|%s)", filter)
 
-	err := l.searchLdapObject(&obj, filter, "")
+	err := l.searchLdapObject(&obj, filter, "", nil)
 	// 如果获得了不存在错误，则代表其可用
 	var notFoundError *ers.NotFoundError
 	if errors.As(err, &notFoundError) {
@@ -97,14 +133,19 @@ func (l *ldapConnPool) checkAvailability(name string) (bool, *BaseObject, error)
 	return false, &obj, nil
 }
 
-// 移动对象到OU
-func (l *ldapConnPool) moveObjectToOU(dn, newOU string) error {
+// 移动对象到OU，version不为空时要求对象的uSNChanged与其一致
+func (l *ldapConnPool) moveObjectToOU(dn, newOU, version string) error {
 	conn, err := l.getConn()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
+	// ModifyDN请求不支持携带控件，只能在移动前校验版本
+	if _, err = l.versionControls(conn, dn, version); err != nil {
+		return err
+	}
+
 	// 分离原始 DN 的 CN 和 OU 部分
 	parts := strings.SplitN(dn, ",", 2)
 	if len(parts) != 2 {
@@ -120,70 +161,264 @@ func (l *ldapConnPool) moveObjectToOU(dn, newOU string) error {
 	return nil
 }
 
-// 修改对象
+// 分页搜索时每页返回的条目数
+const searchPageSize = 500
+
+// 属性变更操作类型，分别对应LDAP的Add/Delete/Replace
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// AttrChange 单个属性的变更，Values为空的remove/replace代表清空该属性
+type AttrChange struct {
+	Op        string   `json:"op"`
+	Attribute string   `json:"attribute"`
+	Values    []string `json:"values"`
+}
+
+// 修改对象，将所有属性替换为replaceAttr中的值
 func (l *ldapConnPool) modifyObj(dn string, replaceAttr map[string][]string) error {
-	if len(replaceAttr) == 0 {
+	var changes []AttrChange
+	for k, v := range replaceAttr {
+		changes = append(changes, AttrChange{Op: OpReplace, Attribute: k, Values: v})
+	}
+	return l.applyChanges(dn, "", changes)
+}
+
+// 在一次modify请求中对对象执行所有的属性变更，version不为空时要求对象的uSNChanged与其一致
+func (l *ldapConnPool) applyChanges(dn, version string, changes []AttrChange) error {
+	if len(changes) == 0 {
 		return &ers.OptErr{Option: fmt.Sprintf("modify obj '%s'", dn), Message: "no valid field in replaceAttr"}
 	}
+
 	conn, err := l.getConn()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	modReq := ldap.NewModifyRequest(dn, []ldap.Control{})
-	for k, v := range replaceAttr {
-		modReq.Replace(k, v)
+	controls, err := l.versionControls(conn, dn, version)
+	if err != nil {
+		return err
 	}
+
+	modReq := ldap.NewModifyRequest(dn, controls)
+	for _, change := range changes {
+		switch change.Op {
+		case OpAdd:
+			modReq.Add(change.Attribute, change.Values)
+		case OpRemove:
+			modReq.Delete(change.Attribute, change.Values)
+		case OpReplace:
+			modReq.Replace(change.Attribute, change.Values)
+		default:
+			return &ers.UnSupportedErr{Object: change.Op, ObjectType: "modify operation"}
+		}
+	}
+
 	if err = conn.Modify(modReq); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultAssertionFailed) {
+			return &ers.PreconditionFailedErr{Object: dn, Version: version}
+		}
 		return &ers.OptErr{Option: fmt.Sprintf("modify obj '%s'", dn), Message: err.Error()}
 	}
 	return nil
 }
 
-// 通用方法，通过指定的Object类型，和搜索过滤条件，返回查找的Ldap对象结构体；
-func (l *ldapConnPool) searchLdapObject(obj SpecObject, filter string, ou string) error {
-	// 如果没有传入搜索OU，则全局搜索
-	if ou == "" {
-		ou = l.BaseDN
+// 返回对象结构体中所有字段的LDAP标签，包含嵌套的BaseObject通用字段
+func objectAttrs(obj SpecObject) []string {
+	var attrs []string
+
+	baseObjType := reflect.TypeOf(*obj.ReturnBaseObj())
+	for i := 0; i < baseObjType.NumField(); i++ {
+		attrs = append(attrs, baseObjType.Field(i).Tag.Get("ldap"))
+	}
+
+	objType := obj.GetSpecType()
+	for i := 0; i < objType.NumField(); i++ {
+		if tag := objType.Field(i).Tag.Get("ldap"); tag != "" {
+			attrs = append(attrs, tag)
+		}
+	}
+	return attrs
+}
+
+// 校验调用方指定的属性是否都是对象所支持的属性，不支持的属性属于请求错误
+func checkAttributes(obj SpecObject, attributes []string) error {
+	known := objectAttrs(obj)
+	for _, attr := range attributes {
+		if !utils.InSliceIC(known, attr) {
+			return &ers.BadRequestErr{Message: fmt.Sprintf("unsupported attribute '%s'", attr)}
+		}
+	}
+	return nil
+}
+
+// 判断属性是否被请求，未指定属性列表时代表请求全部属性；distinguishedName和uSNChanged始终返回，供后续的变更操作及版本校验使用
+func wantAttr(tag string, attributes []string) bool {
+	return len(attributes) == 0 || utils.InSliceIC([]string{"distinguishedName", "uSNChanged"}, tag) || utils.InSliceIC(attributes, tag)
+}
+
+// ProjectObject 按照指定的属性列表裁剪对象，返回仅包含被请求属性的map，用于json响应；未指定属性时原样返回对象
+func ProjectObject(obj SpecObject, attributes []string) interface{} {
+	if len(attributes) == 0 {
+		return obj
+	}
+
+	projected := make(map[string]interface{})
+	collect := func(value reflect.Value) {
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			tag := field.Tag.Get("ldap")
+			if tag == "" || !wantAttr(tag, attributes) {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			projected[name] = value.Field(i).Interface()
+		}
+	}
+
+	collect(reflect.ValueOf(obj.ReturnBaseObj()).Elem())
+	collect(reflect.ValueOf(obj).Elem())
+	return projected
+}
+
+// AttrNames 返回对象中被请求的属性名，顺序与结构体字段一致
+func AttrNames(obj SpecObject, attributes []string) []string {
+	var names []string
+	for _, tag := range objectAttrs(obj) {
+		if wantAttr(tag, attributes) {
+			names = append(names, tag)
+		}
+	}
+	return names
+}
+
+// ObjectValues 将对象中被请求的属性转换为LDAP字符串值，时间类型使用RFC3339格式，空值不返回
+func ObjectValues(obj SpecObject, attributes []string) map[string][]string {
+	values := make(map[string][]string)
+	collect := func(value reflect.Value) {
+		for i := 0; i < value.NumField(); i++ {
+			tag := value.Type().Field(i).Tag.Get("ldap")
+			if tag == "" || !wantAttr(tag, attributes) {
+				continue
+			}
+
+			switch v := value.Field(i).Interface().(type) {
+			case string:
+				if v != "" {
+					values[tag] = []string{v}
+				}
+			case []string:
+				if len(v) > 0 {
+					values[tag] = v
+				}
+			case int64:
+				values[tag] = []string{strconv.FormatInt(v, 10)}
+			case bool:
+				values[tag] = []string{strings.ToUpper(strconv.FormatBool(v))}
+			case GeneralizedTime:
+				if !time.Time(v).IsZero() {
+					values[tag] = []string{time.Time(v).Format(time.RFC3339)}
+				}
+			case FileTime:
+				if !time.Time(v).IsZero() {
+					values[tag] = []string{time.Time(v).Format(time.RFC3339)}
+				}
+			}
+		}
 	}
 
+	collect(reflect.ValueOf(obj.ReturnBaseObj()).Elem())
+	collect(reflect.ValueOf(obj).Elem())
+	return values
+}
+
+// 新增对象
+func (l *ldapConnPool) addObj(dn string, attrs map[string][]string) error {
 	conn, err := l.getConn()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	// 获取Value的类型信息（即结构体的类型）
-	objType := obj.GetSpecType()
-	baseObjType := reflect.TypeOf(*obj.ReturnBaseObj())
+	addRequest := ldap.NewAddRequest(dn, []ldap.Control{})
+	for k, v := range attrs {
+		addRequest.Attribute(k, v)
+	}
+	if err = conn.Add(addRequest); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
+			return &ers.ObjExistError{Object: dn}
+		}
+		return &ers.OptErr{Option: fmt.Sprintf("add obj '%s'", dn), Message: err.Error()}
+	}
+	return nil
+}
 
-	// 初始化一个空字符串切片，用于存储LDAP属性
-	var searchAttr []string
+// 删除对象
+func (l *ldapConnPool) deleteObj(dn, version string) error {
+	conn, err := l.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
 
-	// 先增加通用的Object字段
-	for i := 0; i < baseObjType.NumField(); i++ {
-		field := baseObjType.Field(i)
-		tag := field.Tag.Get("ldap")
-		searchAttr = append(searchAttr, tag)
+	controls, err := l.versionControls(conn, dn, version)
+	if err != nil {
+		return err
 	}
 
-	// 遍历结构体的所有字段
-	for i := 0; i < objType.NumField(); i++ {
-		// 获取字段的LDAP标签值
-		field := objType.Field(i)
-		tag := field.Tag.Get("ldap")
-		// 如果标签值不为空，则将其添加到searchAttr切片中
-		if tag != "" && tag != "member" {
-			searchAttr = append(searchAttr, tag)
+	if err = conn.Del(ldap.NewDelRequest(dn, controls)); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return &ers.NotFoundError{Object: dn}
 		}
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultAssertionFailed) {
+			return &ers.PreconditionFailedErr{Object: dn, Version: version}
+		}
+		return &ers.OptErr{Option: fmt.Sprintf("delete obj '%s'", dn), Message: err.Error()}
+	}
+	return nil
+}
+
+// 重命名对象，newSuperior不为空时同时移动对象
+func (l *ldapConnPool) renameObj(dn, newRDN string, deleteOldRDN bool, newSuperior string) error {
+	conn, err := l.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	modDNReq := ldap.NewModifyDNRequest(dn, newRDN, deleteOldRDN, newSuperior)
+	if err = conn.ModifyDN(modDNReq); err != nil {
+		return &ers.OptErr{Option: fmt.Sprintf("rename obj '%s' to '%s'", dn, newRDN), Message: err.Error()}
 	}
+	return nil
+}
+
+// 通用方法，通过指定的Object类型，和搜索过滤条件，返回查找的Ldap对象结构体；attributes为空时获取全部属性
+func (l *ldapConnPool) searchLdapObject(obj SpecObject, filter string, ou string, attributes []string) error {
+	// 如果没有传入搜索OU，则全局搜索
+	if ou == "" {
+		ou = l.BaseDN
+	}
+
+	if err := checkAttributes(obj, attributes); err != nil {
+		return err
+	}
+
+	conn, err := l.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
 
 	searchRequest := ldap.NewSearchRequest(
 		ou,
 		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
 		filter,
-		searchAttr, // 使用从结构体标签生成的搜索属性列表
+		searchAttrs(obj, attributes), // 使用从结构体标签生成的搜索属性列表
 		nil,
 	)
 	sr, err := conn.Search(searchRequest)
@@ -195,8 +430,94 @@ func (l *ldapConnPool) searchLdapObject(obj SpecObject, filter string, ou string
 		return &ers.NotFoundError{Object: filter}
 	}
 
-	entry := sr.Entries[0]
+	return mapEntry(obj, sr.Entries[0], attributes)
+}
+
+// errStopSearch 由searchLdapObjects的fn返回时放弃剩余的分页并结束搜索，searchLdapObjects返回nil
+var errStopSearch = errors.New("stop search")
+
+// 通用方法，分页搜索所有匹配过滤条件的Ldap对象，每解析出一个对象调用一次fn；
+// newObj用于创建承载搜索结果的对象，fn中可以复用当前连接执行额外的搜索，返回errStopSearch时提前结束搜索
+func (l *ldapConnPool) searchLdapObjects(newObj func() SpecObject, filter, ou string, attributes []string, fn func(obj SpecObject, conn *pooledLdapConn) error) error {
+	if ou == "" {
+		ou = l.BaseDN
+	}
+
+	if err := checkAttributes(newObj(), attributes); err != nil {
+		return err
+	}
+
+	conn, err := l.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	paging := ldap.NewControlPaging(searchPageSize)
+	searchRequest := ldap.NewSearchRequest(
+		ou,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		filter,
+		searchAttrs(newObj(), attributes),
+		[]ldap.Control{paging},
+	)
+
+	for {
+		sr, err := conn.Search(searchRequest)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range sr.Entries {
+			obj := newObj()
+			if err = mapEntry(obj, entry, attributes); err != nil {
+				return err
+			}
+			if err = fn(obj, conn); errors.Is(err, errStopSearch) {
+				return abandonPaging(conn, searchRequest, paging, sr.Controls)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// 服务端返回的分页cookie为空时代表已获取全部结果
+		control, ok := ldap.FindControl(sr.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+		if !ok || len(control.Cookie) == 0 {
+			return nil
+		}
+		paging.SetCookie(control.Cookie)
+	}
+}
+
+// 放弃未获取的分页，按RFC 2696以分页大小0及当前cookie再次搜索，使服务端释放分页搜索的状态
+func abandonPaging(conn *pooledLdapConn, searchRequest *ldap.SearchRequest, paging *ldap.ControlPaging, controls []ldap.Control) error {
+	control, ok := ldap.FindControl(controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+	if !ok || len(control.Cookie) == 0 {
+		return nil
+	}
+	paging.PagingSize = 0
+	paging.SetCookie(control.Cookie)
+	_, _ = conn.Search(searchRequest)
+	return nil
+}
+
+// 根据对象结构体的ldap标签生成需要搜索的属性列表，群组成员需要分页获取，不在此处搜索
+func searchAttrs(obj SpecObject, attributes []string) []string {
+	var searchAttr []string
+	for _, tag := range objectAttrs(obj) {
+		if tag != "member" && wantAttr(tag, attributes) {
+			searchAttr = append(searchAttr, tag)
+		}
+	}
+	return searchAttr
+}
 
+// 将搜索到的条目的属性反序列化至对象结构体，跳过未被请求的字段
+func mapEntry(obj SpecObject, entry *ldap.Entry, attributes []string) error {
+	// 获取Value的类型信息（即结构体的类型）
+	objType := obj.GetSpecType()
+	baseObjType := reflect.TypeOf(*obj.ReturnBaseObj())
 	objValue := reflect.ValueOf(obj).Elem()
 
 	// 遍历entry.Attributes（LDAP属性列表）
@@ -214,21 +535,19 @@ func (l *ldapConnPool) searchLdapObject(obj SpecObject, filter string, ou string
 					structField := objectValue.Type().Field(j)
 					objectTag := structField.Tag.Get("ldap")
 					fieldName = baseObjType.Field(j).Name
-					// 检查Object内的字段的LDAP标签
-					if strings.EqualFold(objectTag, attr.Name) {
+					// 检查Object内的字段的LDAP标签，跳过未被请求的字段
+					if strings.EqualFold(objectTag, attr.Name) && wantAttr(objectTag, attributes) {
 						objectField := objectValue.Field(j)
-						err = setLdapAttr(fieldName, objectField, attr)
-						if err != nil {
+						if err := setLdapAttr(fieldName, objectField, attr); err != nil {
 							return err
 						}
 					}
 				}
-			} else if strings.EqualFold(tag, attr.Name) {
+			} else if strings.EqualFold(tag, attr.Name) && wantAttr(tag, attributes) {
 				fieldName = objType.Field(i).Name
 				// 获取该字段的反射Value
 				field := objValue.FieldByName(fieldName)
-				err = setLdapAttr(fieldName, field, attr)
-				if err != nil {
+				if err := setLdapAttr(fieldName, field, attr); err != nil {
 					return err
 				}
 			}
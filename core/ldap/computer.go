@@ -0,0 +1,107 @@
+package ldap
+
+import (
+	"fmt"
+	"github.com/go-ldap/ldap"
+	"ldap-http-service/lib/ers"
+	"reflect"
+	"strings"
+)
+
+// 计算机账户的userAccountControl：预创建时为工作站信任账户且允许空密码，以便计算机以该名称加入域
+const (
+	UACAccountDisable          = 0x0002
+	uacPasswordNotRequired     = 0x0020
+	uacWorkstationTrustAccount = 0x1000
+	uacPrestagedComputer       = uacWorkstationTrustAccount | uacPasswordNotRequired
+)
+
+type Computer struct {
+	BaseObject
+	DNSHostName            string   `ldap:"dNSHostName" json:"dNSHostName"`
+	OperatingSystem        string   `ldap:"operatingSystem" json:"operatingSystem"`
+	OperatingSystemVersion string   `ldap:"operatingSystemVersion" json:"operatingSystemVersion"`
+	UserAccountControl     string   `ldap:"userAccountControl" json:"userAccountControl"`
+	ManagedBy              string   `ldap:"managedBy" json:"managedBy"`
+	MemberOf               []string `ldap:"memberOf" json:"memberOf"`
+	LastLogonTimestamp     FileTime `ldap:"lastLogonTimestamp" json:"lastLogonTimestamp"`
+	PwdLastSet             FileTime `ldap:"pwdLastSet" json:"pwdLastSet"`
+	// 旧版LAPS与Windows LAPS的本地管理员密码过期时间，未部署对应的LAPS时为零值
+	LapsExpiryTime        FileTime `ldap:"ms-Mcs-AdmPwdExpirationTime" json:"ms-Mcs-AdmPwdExpirationTime"`
+	WindowsLapsExpiryTime FileTime `ldap:"msLAPS-PasswordExpirationTime" json:"msLAPS-PasswordExpirationTime"`
+}
+
+func (c *Computer) ReturnBaseObj() *BaseObject {
+	return &c.BaseObject
+}
+
+func (c *Computer) GetSpecType() reflect.Type {
+	return reflect.TypeOf(*c)
+}
+
+// ComputerAccountName 返回计算机名对应的sAMAccountName，计算机账户的sAMAccountName以$结尾
+func ComputerAccountName(name string) string {
+	if strings.HasSuffix(name, "$") {
+		return name
+	}
+	return name + "$"
+}
+
+// 预创建计算机账户，computerDN的CN为计算机名
+func (l *ldapConnPool) createComputer(computerDN, dnsHostName, description string) error {
+	conn, err := l.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	name := strings.TrimPrefix(strings.Split(computerDN, ",")[0], "CN=")
+
+	addRequest := ldap.NewAddRequest(computerDN, []ldap.Control{})
+	addRequest.Attribute("objectClass", []string{"top", "person", "organizationalPerson", "user", "computer"})
+	addRequest.Attribute("name", []string{name})
+	addRequest.Attribute("sAMAccountName", []string{ComputerAccountName(strings.ToUpper(name))})
+	addRequest.Attribute("userAccountControl", []string{fmt.Sprintf("%d", uacPrestagedComputer)})
+	if dnsHostName != "" {
+		addRequest.Attribute("dNSHostName", []string{dnsHostName})
+	}
+	if description != "" {
+		addRequest.Attribute("description", []string{description})
+	}
+
+	if err = conn.Add(addRequest); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
+			return &ers.ObjExistError{Object: computerDN}
+		}
+		return &ers.OptErr{Option: "create computer", Message: err.Error()}
+	}
+	return nil
+}
+
+// 获取计算机信息，按sAMAccountName查找时可以省略结尾的$；attributes为需要获取的属性，为空时获取全部属性
+func (l *ldapConnPool) getComputer(computerId, computerIdType, searchBase string, attributes ...string) (computer Computer, err error) {
+	switch {
+	case computerIdType == "objectGUID":
+		computerId, err = unFormatGUID(computerId)
+		if err != nil {
+			return
+		}
+	case strings.EqualFold(computerIdType, "sAMAccountName"):
+		computerId = ComputerAccountName(computerId)
+	}
+
+	filter := fmt.Sprintf("(&(objectClass=computer)(objectCategory=computer)(%s=%s))", computerIdType, ldap.EscapeFilter(computerId))
+
+	err = l.searchLdapObject(&computer, filter, searchBase, attributes)
+	return
+}
+
+// 搜索所有匹配过滤条件的计算机，filter为附加的LDAP过滤条件，为空时返回全部计算机
+func (l *ldapConnPool) searchComputers(filter, searchBase string, attributes []string, fn func(*Computer) error) error {
+	filter = fmt.Sprintf("(&(objectClass=computer)(objectCategory=computer)%s)", filter)
+	return l.searchLdapObjects(func() SpecObject { return &Computer{} }, filter, searchBase, attributes,
+		func(obj SpecObject, _ *pooledLdapConn) error {
+			return fn(obj.(*Computer))
+		},
+	)
+}
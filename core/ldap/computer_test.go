@@ -0,0 +1,31 @@
+package ldap
+
+import "testing"
+
+func TestComputerAccountName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "WS01", want: "WS01$"},
+		{name: "WS01$", want: "WS01$"},
+		{name: "ws01", want: "ws01$"},
+		{name: "", want: "$"},
+	}
+
+	for _, tt := range tests {
+		if got := ComputerAccountName(tt.name); got != tt.want {
+			t.Errorf("ComputerAccountName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrestagedComputerAccountControl(t *testing.T) {
+	// 工作站信任账户（0x1000）且允许空密码（0x20），与ADUC预创建计算机账户时写入的值一致
+	if uacPrestagedComputer != 4128 {
+		t.Errorf("uacPrestagedComputer = %d, want 4128", uacPrestagedComputer)
+	}
+	if uacPrestagedComputer&UACAccountDisable != 0 {
+		t.Error("prestaged computer account must be enabled")
+	}
+}
@@ -0,0 +1,115 @@
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap"
+	"ldap-http-service/lib/ers"
+	"reflect"
+)
+
+// Contact 邮件联系人，用于在GAL及通讯组中展示外部合作方
+type Contact struct {
+	BaseObject
+	CN              string   `ldap:"cn" json:"cn"`
+	GivenName       string   `ldap:"givenName" json:"givenName"`
+	Sn              string   `ldap:"sn" json:"sn"`
+	Company         string   `ldap:"company" json:"company"`
+	Department      string   `ldap:"department" json:"department"`
+	TelephoneNumber string   `ldap:"telephoneNumber" json:"telephoneNumber"`
+	Mail            string   `ldap:"mail" json:"mail"`
+	MailNickname    string   `ldap:"mailNickname" json:"mailNickname"`
+	TargetAddress   string   `ldap:"targetAddress" json:"targetAddress"`
+	ProxyAddresses  []string `ldap:"proxyAddresses" json:"proxyAddresses"`
+	MemberOf        []string `ldap:"memberOf" json:"memberOf"`
+}
+
+func (c *Contact) ReturnBaseObj() *BaseObject {
+	return &c.BaseObject
+}
+
+func (c *Contact) GetSpecType() reflect.Type {
+	return reflect.TypeOf(*c)
+}
+
+// NewContact 创建联系人的参数，Name为联系人的CN
+type NewContact struct {
+	Name         string
+	OU           string
+	DisplayName  string
+	GivenName    string
+	Sn           string
+	Company      string
+	Description  string
+	Mail         string
+	MailNickname string
+}
+
+// 创建联系人，外部邮件地址同时写入targetAddress及主SMTP地址，使其可以出现在GAL中并接收邮件
+func (l *ldapConnPool) createContact(contactDN string, contact NewContact) error {
+	conn, err := l.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	addRequest := ldap.NewAddRequest(contactDN, []ldap.Control{})
+	addRequest.Attribute("objectClass", []string{"top", "person", "organizationalPerson", "contact"})
+	addRequest.Attribute("mail", []string{contact.Mail})
+	addRequest.Attribute("targetAddress", []string{"SMTP:" + contact.Mail})
+	addRequest.Attribute("proxyAddresses", []string{"SMTP:" + contact.Mail})
+
+	optional := map[string]string{
+		"displayName":  contact.DisplayName,
+		"givenName":    contact.GivenName,
+		"sn":           contact.Sn,
+		"company":      contact.Company,
+		"description":  contact.Description,
+		"mailNickname": contact.MailNickname,
+	}
+	for attr, value := range optional {
+		if value != "" {
+			addRequest.Attribute(attr, []string{value})
+		}
+	}
+
+	if err = conn.Add(addRequest); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
+			return &ers.ObjExistError{Object: contactDN}
+		}
+		return &ers.OptErr{Option: "create contact", Message: err.Error()}
+	}
+	return nil
+}
+
+// 获取联系人信息，attributes为需要获取的属性，为空时获取全部属性
+func (l *ldapConnPool) getContact(contactId, contactIdType, searchBase string, attributes ...string) (contact Contact, err error) {
+	if contactIdType == "objectGUID" {
+		contactId, err = unFormatGUID(contactId)
+		if err != nil {
+			return
+		}
+	}
+
+	filter := fmt.Sprintf("(&(objectClass=contact)(objectCategory=person)(%s=%s))", contactIdType, ldap.EscapeFilter(contactId))
+
+	err = l.searchLdapObject(&contact, filter, searchBase, attributes)
+	return
+}
+
+// 检查邮件地址是否已被任意对象作为mail或代理地址使用
+func (l *ldapConnPool) checkMailAvailability(mail string) (bool, *BaseObject, error) {
+	var obj BaseObject
+	escaped := ldap.EscapeFilter(mail)
+	filter := fmt.Sprintf("(|(mail=%s)(proxyAddresses=smtp:%s))", escaped, escaped)
+
+	err := l.searchLdapObject(&obj, filter, "", nil)
+	var notFoundError *ers.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return true, nil, nil
+	}
+	if err != nil {
+		return false, nil, &ers.SystemErr{Message: err.Error()}
+	}
+	return false, &obj, nil
+}
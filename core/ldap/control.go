@@ -0,0 +1,96 @@
+package ldap
+
+import (
+	"fmt"
+	"github.com/go-ldap/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/utils"
+)
+
+// LDAP断言控件（RFC 4528），仅当目标对象满足断言中的过滤条件时服务端才会执行操作
+const controlTypeAssertion = "1.3.6.1.1.12"
+
+// 检测服务端rootDSE的supportedControl中是否包含断言控件，检测失败时视为不支持，只缓存检测成功的结果
+func (l *ldapConnPool) supportsAssertion(conn *pooledLdapConn) bool {
+	l.assertionMutex.Lock()
+	defer l.assertionMutex.Unlock()
+
+	if !l.assertionChecked {
+		sr, err := conn.Search(ldap.NewSearchRequest(
+			"",
+			ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+			"(objectClass=*)",
+			[]string{"supportedControl"},
+			nil,
+		))
+		if err != nil || len(sr.Entries) == 0 {
+			return false
+		}
+		l.assertionChecked = true
+		l.assertionSupported = utils.InSlice(controlTypeAssertion, sr.Entries[0].GetAttributeValues("supportedControl"))
+	}
+	return l.assertionSupported
+}
+
+// 校验对象版本，用于没有实际变更需要执行时仍然返回与变更一致的版本校验结果
+func (l *ldapConnPool) checkVersion(dn, version string) error {
+	if version == "" {
+		return nil
+	}
+
+	conn, err := l.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = l.versionControls(conn, dn, version)
+	return err
+}
+
+// 获取对象当前的uSNChanged
+func (l *ldapConnPool) currentVersion(conn *pooledLdapConn, dn string) (string, error) {
+	sr, err := conn.Search(ldap.NewSearchRequest(
+		dn,
+		ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)",
+		[]string{"uSNChanged"},
+		nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return "", &ers.NotFoundError{Object: dn}
+		}
+		return "", &ers.OptErr{Option: fmt.Sprintf("read version of '%s'", dn), Message: err.Error()}
+	}
+	if len(sr.Entries) == 0 {
+		return "", &ers.NotFoundError{Object: dn}
+	}
+	return sr.Entries[0].GetAttributeValue("uSNChanged"), nil
+}
+
+// 校验对象版本，version为期望的uSNChanged，为空时不做校验；
+// 版本一致且服务端支持断言控件时，返回携带uSNChanged断言的控件，使后续的修改操作在服务端原子地完成版本校验
+func (l *ldapConnPool) versionControls(conn *pooledLdapConn, dn, version string) ([]ldap.Control, error) {
+	if version == "" {
+		return []ldap.Control{}, nil
+	}
+
+	current, err := l.currentVersion(conn, dn)
+	if err != nil {
+		return nil, err
+	}
+	if current != version {
+		return nil, &ers.PreconditionFailedErr{Object: dn, Version: version}
+	}
+
+	if !l.supportsAssertion(conn) {
+		return []ldap.Control{}, nil
+	}
+
+	filter, err := ldap.CompileFilter(fmt.Sprintf("(uSNChanged=%s)", ldap.EscapeFilter(version)))
+	if err != nil {
+		return nil, err
+	}
+	return []ldap.Control{ldap.NewControlString(controlTypeAssertion, true, string(filter.Bytes()))}, nil
+}
@@ -0,0 +1,71 @@
+package ldap
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 通过本服务成功写入目录后产生的事件类型
+const (
+	EventUserCreated        = "user.created"
+	EventUserPasswordSet    = "user.password_set"
+	EventGroupCreated       = "group.created"
+	EventGroupMemberAdded   = "group.member_added"
+	EventGroupMemberRemoved = "group.member_removed"
+	EventGroupOwnerChanged  = "group.owner_changed"
+	EventComputerCreated    = "computer.created"
+	EventComputerDisabled   = "computer.disabled"
+	EventContactCreated     = "contact.created"
+	EventObjectCreated      = "object.created"
+	EventObjectUpdated      = "object.updated"
+	EventObjectMoved        = "object.moved"
+	EventObjectRenamed      = "object.renamed"
+	EventObjectDeleted      = "object.deleted"
+)
+
+// EventTypes 所有的写入事件类型
+var EventTypes = []string{
+	EventUserCreated, EventUserPasswordSet,
+	EventGroupCreated, EventGroupMemberAdded, EventGroupMemberRemoved, EventGroupOwnerChanged,
+	EventComputerCreated, EventComputerDisabled, EventContactCreated,
+	EventObjectCreated, EventObjectUpdated, EventObjectMoved, EventObjectRenamed, EventObjectDeleted,
+}
+
+// WriteEvent 写入事件，DN为被写入的对象
+type WriteEvent struct {
+	Type    string                 `json:"type"`
+	DN      string                 `json:"distinguishedName"`
+	Time    time.Time              `json:"time"`
+	TraceId string                 `json:"trace_id,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// WriteHook 写入事件的处理函数，在写入成功后同步调用，不应执行耗时操作
+type WriteHook func(event WriteEvent)
+
+var (
+	writeHooks []WriteHook
+	hookMutex  sync.RWMutex
+)
+
+// AddWriteHook 注册写入事件的处理函数
+func AddWriteHook(hook WriteHook) {
+	hookMutex.Lock()
+	defer hookMutex.Unlock()
+	writeHooks = append(writeHooks, hook)
+}
+
+// 触发写入事件，从上下文中获取trace_id以便关联到原始请求
+func emit(tractx context.Context, eventType, dn string, data map[string]interface{}) {
+	event := WriteEvent{Type: eventType, DN: dn, Time: time.Now(), Data: data}
+	if traceId, ok := tractx.Value("trace_id").(string); ok {
+		event.TraceId = traceId
+	}
+
+	hookMutex.RLock()
+	defer hookMutex.RUnlock()
+	for _, hook := range writeHooks {
+		hook(event)
+	}
+}
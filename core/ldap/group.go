@@ -1,15 +1,38 @@
 package ldap
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-ldap/ldap"
 	"ldap-http-service/lib/ers"
 	"ldap-http-service/lib/utils"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// 成员类型提示，成员标识以 "类型:" 开头时只在对应类型的对象中解析，例如 group:sales
+const (
+	MemberUser    = "user"
+	MemberContact = "contact"
+	MemberGroup   = "group"
+)
+
+// 各类型成员的过滤条件
+var memberFilters = map[string]string{
+	MemberUser:    "(&(objectClass=user)(objectCategory=person))",
+	MemberContact: "(&(objectClass=contact)(objectCategory=person))",
+	MemberGroup:   "(&(objectClass=group)(objectCategory=group))",
+}
+
+// 群组嵌套关系的链式匹配规则，可以匹配间接所属的群组
+const matchingRuleInChain = "1.2.840.113556.1.4.1941"
+
+// 特权访问管理功能在配置分区中的RDN，启用后记录在Partitions容器的msDS-EnabledFeature中
+const pamFeatureRDN = "CN=Privileged Access Management Feature,"
+
 type Group struct {
 	BaseObject
 	CN                    string   `ldap:"cn" json:"cn"`
@@ -30,7 +53,13 @@ func (g *Group) GetSpecType() reflect.Type {
 	return reflect.TypeOf(*g)
 }
 
-func (l *ldapConnPool) getGroup(groupId, groupIdType, searchBase string) (group Group, err error) {
+// IsManagedBy 判断dn是否直接为群组的所有者或共同管理者，不展开作为所有者的群组的成员，owner模式的权限校验见AuthorizeMemberChange
+func (g *Group) IsManagedBy(dn string) bool {
+	return dn != "" && (strings.EqualFold(g.ManagedBy, dn) || utils.InSliceIC(g.MsExchCoManagedByLink, dn))
+}
+
+// 获取群组信息，attributes为需要获取的属性，为空时获取全部属性
+func (l *ldapConnPool) getGroup(groupId, groupIdType, searchBase string, attributes ...string) (group Group, err error) {
 	if groupIdType == "objectGUID" {
 		groupId, err = unFormatGUID(groupId)
 		if err != nil {
@@ -40,7 +69,15 @@ func (l *ldapConnPool) getGroup(groupId, groupIdType, searchBase string) (group
 	// 设置ldap搜索过滤条件
 	filter := fmt.Sprintf("(&(objectClass=group)(objectCategory=group)(%s=%s))", groupIdType, ldap.EscapeFilter(groupId))
 
-	err = l.searchLdapObject(&group, filter, searchBase)
+	err = l.searchLdapObject(&group, filter, searchBase, attributes)
+	if err != nil {
+		return
+	}
+
+	// 未请求群组成员时，跳过成员的递归获取
+	if !wantAttr("member", attributes) {
+		return
+	}
 
 	// 群组成员需要递归获取，单独搜索群组成员
 	conn, err := l.getConn()
@@ -94,11 +131,104 @@ func (l *ldapConnPool) getGroupMembers(conn *pooledLdapConn, groupId, groupIdTyp
 	return l.getGroupMembers(conn, groupId, groupIdType, pageTop, members)
 }
 
-// 将用户添加到指定的群组
-func (l *ldapConnPool) addGroupMembers(groupDN string, userDNs ...string) error {
-	// 如果userDNs为空列表或nil，则直接返回，否则会导致群组清空！
+// splitMemberHint 拆分成员标识中的类型提示，没有类型提示时memberType为空
+func splitMemberHint(member string) (memberType, memberId string) {
+	if prefix, id, found := strings.Cut(member, ":"); found {
+		if _, ok := memberFilters[strings.ToLower(prefix)]; ok {
+			return strings.ToLower(prefix), id
+		}
+	}
+	return "", member
+}
+
+// 解析群组成员，成员可以是用户、联系人或群组；没有类型提示时按objectClass自动识别，匹配到多个对象时返回错误
+func (l *ldapConnPool) getMember(member, memberIdType, searchBase string) (obj BaseObject, err error) {
+	memberType, memberId := splitMemberHint(member)
+	if memberIdType == "objectGUID" {
+		memberId, err = unFormatGUID(memberId)
+		if err != nil {
+			return
+		}
+	}
+
+	classFilter := memberFilters[memberType]
+	if memberType == "" {
+		classFilter = fmt.Sprintf("(|%s%s%s)", memberFilters[MemberUser], memberFilters[MemberContact], memberFilters[MemberGroup])
+	}
+	filter := fmt.Sprintf("(&%s(%s=%s))", classFilter, memberIdType, ldap.EscapeFilter(memberId))
+
+	var matched []*BaseObject
+	err = l.searchLdapObjects(func() SpecObject { return &BaseObject{} }, filter, searchBase, nil,
+		func(obj SpecObject, _ *pooledLdapConn) error {
+			matched = append(matched, obj.ReturnBaseObj())
+			if len(matched) > 1 {
+				return &ers.BadRequestErr{Message: fmt.Sprintf("member '%s' matches multiple objects, add a type hint such as group:%s", member, memberId)}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return
+	}
+	if len(matched) == 0 {
+		err = &ers.NotFoundError{Object: filter}
+		return
+	}
+	return *matched[0], nil
+}
+
+// 返回memberDNs中添加到群组后会形成循环嵌套的成员：群组自身，以及直接或间接包含该群组的群组
+func (l *ldapConnPool) findMembershipCycles(groupDN string, memberDNs []string) ([]string, error) {
+	var cycles, candidates []string
+	for _, dn := range memberDNs {
+		if strings.EqualFold(dn, groupDN) {
+			cycles = append(cycles, dn)
+		} else {
+			candidates = append(candidates, dn)
+		}
+	}
+	if len(candidates) == 0 {
+		return cycles, nil
+	}
+
+	// 先通过一次搜索判断群组是否间接属于任一成员，只有存在循环时才逐个确认
+	chainFilter := func(dns []string) string {
+		var b strings.Builder
+		for _, dn := range dns {
+			b.WriteString(fmt.Sprintf("(memberOf:%s:=%s)", matchingRuleInChain, ldap.EscapeFilter(dn)))
+		}
+		return fmt.Sprintf("(&%s(distinguishedName=%s)(|%s))", memberFilters[MemberGroup], ldap.EscapeFilter(groupDN), b.String())
+	}
+	inChain := func(dns []string) (bool, error) {
+		var obj BaseObject
+		err := l.searchLdapObject(&obj, chainFilter(dns), "", []string{"distinguishedName"})
+		var notFoundError *ers.NotFoundError
+		if errors.As(err, &notFoundError) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	found, err := inChain(candidates)
+	if err != nil || !found {
+		return cycles, err
+	}
+	for _, dn := range candidates {
+		if found, err = inChain([]string{dn}); err != nil {
+			return cycles, err
+		}
+		if found {
+			cycles = append(cycles, dn)
+		}
+	}
+	return cycles, nil
+}
+
+// 将用户添加到指定的群组，version不为空时要求群组的uSNChanged与其一致
+func (l *ldapConnPool) addGroupMembers(groupDN, version string, userDNs ...string) error {
+	// 如果userDNs为空列表或nil，则只校验版本后返回，否则会导致群组清空！
 	if len(userDNs) == 0 || userDNs == nil {
-		return nil
+		return l.checkVersion(groupDN, version)
 	}
 
 	conn, err := l.getConn()
@@ -107,23 +237,100 @@ func (l *ldapConnPool) addGroupMembers(groupDN string, userDNs ...string) error
 	}
 	defer conn.Close()
 
-	modifyRequest := ldap.NewModifyRequest(groupDN, []ldap.Control{})
+	controls, err := l.versionControls(conn, groupDN, version)
+	if err != nil {
+		return err
+	}
+
+	modifyRequest := ldap.NewModifyRequest(groupDN, controls)
 	// 添加成员
 	modifyRequest.Add("member", userDNs)
 
 	err = conn.Modify(modifyRequest)
 	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultAssertionFailed) {
+			return &ers.PreconditionFailedErr{Object: groupDN, Version: version}
+		}
 		return &ers.OptErr{Option: fmt.Sprintf("add member to group '%s'", groupDN), Message: err.Error()}
 	}
 
 	return nil
 }
 
-// 从指定群组中移除用户
-func (l *ldapConnPool) removeGroupMembers(groupDN string, userDNs ...string) error {
-	// 如果userDNs为空列表或nil，则直接返回，传入空列表/nil会导致删除所有成员
+// 林中是否启用了特权访问管理功能（Windows Server 2016及以上林功能级别），检测失败时视为未启用，只缓存检测成功的结果
+func (l *ldapConnPool) supportsMemberTTL() bool {
+	l.pamMutex.Lock()
+	defer l.pamMutex.Unlock()
+
+	if !l.pamChecked {
+		enabled, err := l.probeMemberTTL()
+		if err != nil {
+			return false
+		}
+		l.pamChecked, l.pamEnabled = true, enabled
+	}
+	return l.pamEnabled
+}
+
+// 通过配置分区中的msDS-EnabledFeature检测林中是否启用了特权访问管理功能
+func (l *ldapConnPool) probeMemberTTL() (bool, error) {
+	conn, err := l.getConn()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	sr, err := conn.Search(ldap.NewSearchRequest(
+		"",
+		ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)",
+		[]string{"configurationNamingContext"},
+		nil,
+	))
+	if err != nil {
+		return false, err
+	}
+	if len(sr.Entries) == 0 {
+		return false, &ers.NotFoundError{Object: "rootDSE"}
+	}
+
+	sr, err = conn.Search(ldap.NewSearchRequest(
+		"CN=Partitions,"+sr.Entries[0].GetAttributeValue("configurationNamingContext"),
+		ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)",
+		[]string{"msDS-EnabledFeature"},
+		nil,
+	))
+	if err != nil {
+		return false, err
+	}
+	if len(sr.Entries) == 0 {
+		return false, &ers.NotFoundError{Object: "CN=Partitions"}
+	}
+	for _, feature := range sr.Entries[0].GetAttributeValues("msDS-EnabledFeature") {
+		if strings.HasPrefix(strings.ToUpper(feature), strings.ToUpper(pamFeatureRDN)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// 以带TTL的成员链接将用户添加到指定的群组，到期后由域控制器自动移除；
+// 已在群组中的成员同样写入，用于设置或更新其TTL，version不为空时要求群组的uSNChanged与其一致
+func (l *ldapConnPool) addGroupMembersWithTTL(groupDN, version string, ttl time.Duration, userDNs ...string) error {
+	seconds := int64(math.Ceil(ttl.Seconds()))
+	values := make([]string, 0, len(userDNs))
+	for _, dn := range userDNs {
+		values = append(values, fmt.Sprintf("<TTL=%d,%s>", seconds, dn))
+	}
+	return l.addGroupMembers(groupDN, version, values...)
+}
+
+// 从指定群组中移除用户，version不为空时要求群组的uSNChanged与其一致
+func (l *ldapConnPool) removeGroupMembers(groupDN, version string, userDNs ...string) error {
+	// 如果userDNs为空列表或nil，则只校验版本后返回，传入空列表/nil会导致删除所有成员
 	if len(userDNs) == 0 || userDNs == nil {
-		return nil
+		return l.checkVersion(groupDN, version)
 	}
 
 	conn, err := l.getConn()
@@ -132,12 +339,20 @@ func (l *ldapConnPool) removeGroupMembers(groupDN string, userDNs ...string) err
 	}
 	defer conn.Close()
 
-	modifyRequest := ldap.NewModifyRequest(groupDN, []ldap.Control{})
+	controls, err := l.versionControls(conn, groupDN, version)
+	if err != nil {
+		return err
+	}
+
+	modifyRequest := ldap.NewModifyRequest(groupDN, controls)
 	// 移除成员
 	modifyRequest.Delete("member", userDNs)
 
 	err = conn.Modify(modifyRequest)
 	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultAssertionFailed) {
+			return &ers.PreconditionFailedErr{Object: groupDN, Version: version}
+		}
 		return &ers.OptErr{Option: fmt.Sprintf("remove member from group '%s'", groupDN), Message: err.Error()}
 	}
 
@@ -173,3 +388,21 @@ func (l *ldapConnPool) createGroup(groupDN, displayName, description string, gro
 
 	return nil
 }
+
+// 搜索所有匹配过滤条件的群组，filter为附加的LDAP过滤条件，为空时返回全部群组
+func (l *ldapConnPool) searchGroups(filter, searchBase string, attributes []string, fn func(*Group) error) error {
+	filter = fmt.Sprintf("(&(objectClass=group)(objectCategory=group)%s)", filter)
+	return l.searchLdapObjects(func() SpecObject { return &Group{} }, filter, searchBase, attributes,
+		func(obj SpecObject, conn *pooledLdapConn) (err error) {
+			group := obj.(*Group)
+			// 群组成员需要递归获取，复用当前连接单独搜索群组成员
+			if wantAttr("member", attributes) {
+				group.Member, err = l.getGroupMembers(conn, group.DistinguishedName, "distinguishedName", 0, []string{})
+				if err != nil {
+					return err
+				}
+			}
+			return fn(group)
+		},
+	)
+}
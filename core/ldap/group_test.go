@@ -0,0 +1,29 @@
+package ldap
+
+import "testing"
+
+func TestSplitMemberHint(t *testing.T) {
+	tests := []struct {
+		member     string
+		memberType string
+		memberId   string
+	}{
+		{member: "alice", memberType: "", memberId: "alice"},
+		{member: "user:alice", memberType: MemberUser, memberId: "alice"},
+		{member: "Contact:bob@example.com", memberType: MemberContact, memberId: "bob@example.com"},
+		{member: "GROUP:sales", memberType: MemberGroup, memberId: "sales"},
+		{member: "group:", memberType: MemberGroup, memberId: ""},
+		{member: "printer:hp01", memberType: "", memberId: "printer:hp01"},
+		{member: "CN=a:b,OU=People,DC=example,DC=com", memberType: "", memberId: "CN=a:b,OU=People,DC=example,DC=com"},
+		{member: "user:domain:alice", memberType: MemberUser, memberId: "domain:alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.member, func(t *testing.T) {
+			memberType, memberId := splitMemberHint(tt.member)
+			if memberType != tt.memberType || memberId != tt.memberId {
+				t.Errorf("splitMemberHint(%q) = (%q, %q), want (%q, %q)", tt.member, memberType, memberId, tt.memberType, tt.memberId)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/utils"
+	"strings"
+)
+
+// 单次搜索解析的标识数量，避免过滤条件过长
+const lookupBatch = 100
+
+// 批量解析对象标识，标识按lookupBatch分组后以OR过滤条件搜索，搜索结果按idType属性的值对应回标识（不区分大小写，
+// objectGUID规范化后比较）；返回标识到对象的映射，以及按输入顺序排列的未找到的标识，存在格式错误的objectGUID时返回*ers.BadRequestErr
+func (l *ldapConnPool) lookupObjects(newObj func() SpecObject, classFilter string, ids []string, idType, searchBase string, attributes []string,
+	each func(obj SpecObject, conn *pooledLdapConn) error) (map[string]SpecObject, []string, error) {
+	if !utils.InSliceIC(objectAttrs(newObj()), idType) {
+		return nil, nil, &ers.UnSupportedErr{Object: idType, ObjectType: "id type"}
+	}
+	// 指定了属性列表时需要额外获取idType属性，用于将结果对应回标识
+	if len(attributes) > 0 && !utils.InSliceIC(attributes, idType) {
+		attributes = append(append([]string{}, attributes...), idType)
+	}
+
+	var (
+		pending  []string
+		keys     = make(map[string]string)
+		found    = make(map[string]SpecObject)
+		notFound []string
+	)
+	for _, id := range ids {
+		key, err := lookupKey(idType, id)
+		if err != nil {
+			return nil, nil, &ers.BadRequestErr{Message: fmt.Sprintf("invalid objectGUID '%s'", id)}
+		}
+		if _, ok := keys[key]; !ok {
+			keys[key] = id
+			pending = append(pending, id)
+		}
+	}
+
+	for start := 0; start < len(pending); start += lookupBatch {
+		batch := pending[start:]
+		if len(batch) > lookupBatch {
+			batch = batch[:lookupBatch]
+		}
+
+		var filter strings.Builder
+		for _, id := range batch {
+			value := id
+			if strings.EqualFold(idType, "objectGUID") {
+				value, _ = unFormatGUID(id)
+			}
+			filter.WriteString(fmt.Sprintf("(%s=%s)", idType, ldap.EscapeFilter(value)))
+		}
+
+		err := l.searchLdapObjects(newObj, fmt.Sprintf("(&%s(|%s))", classFilter, filter.String()), searchBase, attributes,
+			func(obj SpecObject, conn *pooledLdapConn) error {
+				for attr, values := range ObjectValues(obj, []string{idType}) {
+					if !strings.EqualFold(attr, idType) {
+						continue
+					}
+					for _, value := range values {
+						key, err := lookupKey(idType, value)
+						if err != nil {
+							continue
+						}
+						if id, ok := keys[key]; ok {
+							found[id] = obj
+						}
+					}
+				}
+				if each != nil {
+					return each(obj, conn)
+				}
+				return nil
+			})
+		if err != nil {
+			var customErr ers.CustomErr
+			if errors.As(err, &customErr) {
+				return nil, nil, err
+			}
+			return nil, nil, &ers.SystemErr{Message: err.Error()}
+		}
+	}
+
+	for _, id := range pending {
+		if _, ok := found[id]; !ok {
+			notFound = append(notFound, id)
+		}
+	}
+	return found, notFound, nil
+}
+
+// 标识与搜索结果对应时使用的键，objectGUID规范化为小写带破折号的形式，其他标识不区分大小写
+func lookupKey(idType, id string) (string, error) {
+	if strings.EqualFold(idType, "objectGUID") {
+		return canonicalGUID(id)
+	}
+	return strings.ToLower(id), nil
+}
@@ -0,0 +1,56 @@
+package ldap
+
+import "testing"
+
+func TestLookupKey(t *testing.T) {
+	const guid = "0f9a7c2e-41d3-4b8a-9c1e-5a6b7c8d9e0f"
+	tests := []struct {
+		name    string
+		idType  string
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{name: "canonical guid", idType: "objectGUID", id: guid, want: guid},
+		{name: "uppercase guid", idType: "objectGUID", id: "0F9A7C2E-41D3-4B8A-9C1E-5A6B7C8D9E0F", want: guid},
+		{name: "dashless guid", idType: "objectGUID", id: "0f9a7c2e41d34b8a9c1e5a6b7c8d9e0f", want: guid},
+		{name: "id type is case insensitive", idType: "objectguid", id: "0F9A7C2E41D34B8A9C1E5A6B7C8D9E0F", want: guid},
+		{name: "too short", idType: "objectGUID", id: "0f9a7c2e-41d3-4b8a-9c1e", wantErr: true},
+		{name: "not hex", idType: "objectGUID", id: "zz9a7c2e-41d3-4b8a-9c1e-5a6b7c8d9e0f", wantErr: true},
+		{name: "empty", idType: "objectGUID", id: "", wantErr: true},
+		{name: "other id types are lowercased", idType: "sAMAccountName", id: "Alice", want: "alice"},
+		{name: "dn", idType: "distinguishedName", id: "CN=Alice,OU=People,DC=example,DC=com", want: "cn=alice,ou=people,dc=example,dc=com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lookupKey(tt.idType, tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("lookupKey(%q, %q) error = %v, wantErr %v", tt.idType, tt.id, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("lookupKey(%q, %q) = %q, want %q", tt.idType, tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalGUIDMatchesFormatGUID(t *testing.T) {
+	for _, guid := range []string{"0f9a7c2e-41d3-4b8a-9c1e-5a6b7c8d9e0f", "00000000-0000-0000-0000-000000000001"} {
+		raw, err := unFormatGUID(guid)
+		if err != nil {
+			t.Fatalf("unFormatGUID(%q): %v", guid, err)
+		}
+		formatted, err := formatGUID([]byte(raw))
+		if err != nil {
+			t.Fatalf("formatGUID: %v", err)
+		}
+		canonical, err := canonicalGUID(guid)
+		if err != nil {
+			t.Fatalf("canonicalGUID(%q): %v", guid, err)
+		}
+		if canonical != formatted {
+			t.Errorf("canonicalGUID(%q) = %q, formatGUID = %q", guid, canonical, formatted)
+		}
+	}
+}
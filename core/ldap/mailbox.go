@@ -0,0 +1,126 @@
+package ldap
+
+import (
+	"fmt"
+	"github.com/go-ldap/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/utils"
+	"net/mail"
+	"strconv"
+	"strings"
+)
+
+// proxyAddresses中SMTP地址的前缀，大写为主地址，小写为别名
+const (
+	primarySMTPPrefix = "SMTP:"
+	aliasSMTPPrefix   = "smtp:"
+)
+
+// MailboxQuota 邮箱配额，单位为KB；UseDefaults为true时使用邮箱数据库的默认配额，其余字段被忽略
+type MailboxQuota struct {
+	UseDefaults        bool
+	StorageQuota       int64
+	OverQuotaLimit     int64
+	OverHardQuotaLimit int64
+}
+
+// 校验SMTP地址格式及其域名是否为服务支持的域，返回去除前缀后的地址
+func (l *ldapConnPool) parseSMTPAddress(address string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(address), aliasSMTPPrefix) {
+		address = address[len(aliasSMTPPrefix):]
+	}
+
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address {
+		return "", &ers.InvalidFormatErr{Name: "smtp address", Object: address}
+	}
+	domain := address[strings.LastIndex(address, "@")+1:]
+	if !utils.InSliceIC(l.Zones, domain) {
+		return "", &ers.UnSupportedErr{Object: domain, ObjectType: "domain"}
+	}
+	return address, nil
+}
+
+// 校验SMTP地址未被其他对象作为mail或代理地址使用，dn为地址的目标对象；检查所有使用该地址的对象，不只是第一个
+func (l *ldapConnPool) checkSMTPAddress(dn, address string) error {
+	escaped := ldap.EscapeFilter(address)
+	filter := fmt.Sprintf("(|(mail=%s)(proxyAddresses=smtp:%s))", escaped, escaped)
+	return l.searchLdapObjects(func() SpecObject { return &BaseObject{} }, filter, "", []string{"distinguishedName"},
+		func(obj SpecObject, _ *pooledLdapConn) error {
+			if !strings.EqualFold(obj.ReturnBaseObj().DistinguishedName, dn) {
+				return &ers.ObjExistError{Object: fmt.Sprintf("smtp='%s'", address)}
+			}
+			return nil
+		},
+	)
+}
+
+// 返回proxyAddresses中与address相同的SMTP地址的下标，不存在时返回-1
+func indexSMTPAddress(proxyAddresses []string, address string) int {
+	for i, proxy := range proxyAddresses {
+		if strings.EqualFold(proxy, aliasSMTPPrefix+address) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 返回proxyAddresses中的主SMTP地址，不存在时返回空字符串
+func primarySMTPAddress(proxyAddresses []string) string {
+	for _, proxy := range proxyAddresses {
+		if strings.HasPrefix(proxy, primarySMTPPrefix) {
+			return proxy[len(primarySMTPPrefix):]
+		}
+	}
+	return ""
+}
+
+// 将address设置为主SMTP地址所需的属性变更：原主地址降级为别名，与address相同的别名被移除，并同步到mail；
+// proxyAddresses只删除、添加单个值，不会覆盖其他请求同时对其他地址的修改
+func primarySMTPChanges(proxyAddresses []string, address string) []AttrChange {
+	var changes []AttrChange
+	isPrimary := false
+	for _, proxy := range proxyAddresses {
+		switch {
+		case proxy == primarySMTPPrefix+address:
+			isPrimary = true
+		case strings.HasPrefix(proxy, primarySMTPPrefix):
+			changes = append(changes, AttrChange{Op: OpRemove, Attribute: "proxyAddresses", Values: []string{proxy}})
+			if old := proxy[len(primarySMTPPrefix):]; !strings.EqualFold(old, address) {
+				changes = append(changes, AttrChange{Op: OpAdd, Attribute: "proxyAddresses", Values: []string{aliasSMTPPrefix + old}})
+			}
+		case strings.EqualFold(proxy, aliasSMTPPrefix+address):
+			changes = append(changes, AttrChange{Op: OpRemove, Attribute: "proxyAddresses", Values: []string{proxy}})
+		}
+	}
+	if !isPrimary {
+		changes = append(changes, AttrChange{Op: OpAdd, Attribute: "proxyAddresses", Values: []string{primarySMTPPrefix + address}})
+	}
+	return append(changes, AttrChange{Op: OpReplace, Attribute: "mail", Values: []string{address}})
+}
+
+// 邮箱配额对应的属性变更，使用默认配额时清空各项配额
+func (q MailboxQuota) changes() ([]AttrChange, error) {
+	if q.UseDefaults {
+		return []AttrChange{
+			{Op: OpReplace, Attribute: "mDBUseDefaults", Values: []string{"TRUE"}},
+			{Op: OpReplace, Attribute: "mDBStorageQuota"},
+			{Op: OpReplace, Attribute: "mDBOverQuotaLimit"},
+			{Op: OpReplace, Attribute: "mDBOverHardQuotaLimit"},
+		}, nil
+	}
+
+	// 依次为发出警告、禁止发送、禁止发送和接收的阈值，后者不能小于前者
+	if q.StorageQuota <= 0 || q.OverQuotaLimit <= 0 || q.OverHardQuotaLimit <= 0 {
+		return nil, &ers.BadRequestErr{Message: "mailbox quotas must be positive"}
+	}
+	if q.StorageQuota > q.OverQuotaLimit || q.OverQuotaLimit > q.OverHardQuotaLimit {
+		return nil, &ers.BadRequestErr{Message: "mailbox quotas must satisfy storage_quota <= over_quota_limit <= over_hard_quota_limit"}
+	}
+	return []AttrChange{
+		{Op: OpReplace, Attribute: "mDBUseDefaults", Values: []string{"FALSE"}},
+		{Op: OpReplace, Attribute: "mDBStorageQuota", Values: []string{strconv.FormatInt(q.StorageQuota, 10)}},
+		{Op: OpReplace, Attribute: "mDBOverQuotaLimit", Values: []string{strconv.FormatInt(q.OverQuotaLimit, 10)}},
+		{Op: OpReplace, Attribute: "mDBOverHardQuotaLimit", Values: []string{strconv.FormatInt(q.OverHardQuotaLimit, 10)}},
+	}, nil
+}
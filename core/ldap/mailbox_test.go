@@ -0,0 +1,55 @@
+package ldap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrimarySMTPChanges(t *testing.T) {
+	mail := AttrChange{Op: OpReplace, Attribute: "mail", Values: []string{"new@example.com"}}
+	add := func(value string) AttrChange {
+		return AttrChange{Op: OpAdd, Attribute: "proxyAddresses", Values: []string{value}}
+	}
+	remove := func(value string) AttrChange {
+		return AttrChange{Op: OpRemove, Attribute: "proxyAddresses", Values: []string{value}}
+	}
+
+	tests := []struct {
+		name           string
+		proxyAddresses []string
+		want           []AttrChange
+	}{
+		{
+			name: "no addresses",
+			want: []AttrChange{add("SMTP:new@example.com"), mail},
+		},
+		{
+			name:           "demote old primary",
+			proxyAddresses: []string{"SMTP:old@example.com", "smtp:other@example.com", "X500:/o=org"},
+			want:           []AttrChange{remove("SMTP:old@example.com"), add("smtp:old@example.com"), add("SMTP:new@example.com"), mail},
+		},
+		{
+			name:           "promote existing alias",
+			proxyAddresses: []string{"SMTP:old@example.com", "smtp:New@example.com"},
+			want:           []AttrChange{remove("SMTP:old@example.com"), add("smtp:old@example.com"), remove("smtp:New@example.com"), add("SMTP:new@example.com"), mail},
+		},
+		{
+			name:           "already primary",
+			proxyAddresses: []string{"SMTP:new@example.com", "smtp:old@example.com"},
+			want:           []AttrChange{mail},
+		},
+		{
+			name:           "primary differs only in case",
+			proxyAddresses: []string{"SMTP:NEW@example.com"},
+			want:           []AttrChange{remove("SMTP:NEW@example.com"), add("SMTP:new@example.com"), mail},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := primarySMTPChanges(tt.proxyAddresses, "new@example.com"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("primarySMTPChanges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package ldap
+
+import (
+	"fmt"
+	"github.com/go-ldap/ldap"
+	"github.com/mozillazg/go-pinyin"
+	"golang.org/x/text/unicode/norm"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// sAMAccountName的最大长度
+const maxAccountNameLen = 20
+
+// 单次搜索校验的候选用户名数量，避免过滤条件过长
+const nameCheckBatch = 50
+
+// 用户名模板中的占位符：名、姓的全拼，名、姓各音节（单词）的首字母，以及数字后缀
+const (
+	placeholderFirst  = "{first}"
+	placeholderLast   = "{last}"
+	placeholderF      = "{f}"
+	placeholderL      = "{l}"
+	placeholderNumber = "{n}"
+)
+
+var pinyinArgs = pinyin.NewArgs()
+
+// nameOwner 占用用户名的对象，用户名可能被sAMAccountName或SMTP地址占用
+type nameOwner struct {
+	BaseObject
+	ProxyAddresses []string `ldap:"proxyAddresses" json:"proxyAddresses"`
+}
+
+func (o *nameOwner) ReturnBaseObj() *BaseObject {
+	return &o.BaseObject
+}
+
+func (o *nameOwner) GetSpecType() reflect.Type {
+	return reflect.TypeOf(*o)
+}
+
+// 将姓名拆分为小写的音节或单词，汉字转换为不带声调的拼音，其他字符去除变音符号后只保留字母和数字
+func nameSyllables(name string) []string {
+	var (
+		syllables []string
+		word      strings.Builder
+	)
+	flush := func() {
+		if word.Len() > 0 {
+			syllables = append(syllables, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 {
+				syllables = append(syllables, py[0])
+			}
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word.WriteRune(unicode.ToLower(r))
+		case unicode.Is(unicode.Mn, r):
+			// 变音符号，例如é分解后的重音符
+		default:
+			flush()
+		}
+	}
+	flush()
+	return syllables
+}
+
+func initials(syllables []string) string {
+	var b strings.Builder
+	for _, s := range syllables {
+		b.WriteByte(s[0])
+	}
+	return b.String()
+}
+
+// 按模板生成候选用户名，不含数字后缀的模板优先，之后按数字后缀从小到大生成；
+// 超长的用户名优先截断后缀之前的部分以保留数字后缀，后缀本身超长时截断整个用户名
+func nameCandidates(given, surname string, patterns []string, maxSuffix int) []string {
+	first, last := nameSyllables(given), nameSyllables(surname)
+	if len(first) == 0 || len(last) == 0 {
+		return nil
+	}
+	replacer := strings.NewReplacer(
+		placeholderFirst, strings.Join(first, ""),
+		placeholderLast, strings.Join(last, ""),
+		placeholderF, initials(first),
+		placeholderL, initials(last),
+	)
+
+	var candidates []string
+	seen := make(map[string]bool)
+	add := func(base, suffix string) {
+		if keep := maxAccountNameLen - len(suffix); len(base) > keep {
+			if keep < 0 {
+				keep = 0
+			}
+			base = strings.TrimRight(base[:keep], ".-_")
+		}
+		candidate := base + suffix
+		if len(candidate) > maxAccountNameLen {
+			candidate = candidate[:maxAccountNameLen]
+		}
+		// sAMAccountName不能以点结尾
+		candidate = strings.TrimRight(candidate, ".")
+		if candidate != "" && !seen[candidate] {
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	var numbered []string
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, placeholderNumber) {
+			numbered = append(numbered, pattern)
+			continue
+		}
+		add(replacer.Replace(pattern), "")
+	}
+	for n := 1; n <= maxSuffix; n++ {
+		for _, pattern := range numbered {
+			base, rest, _ := strings.Cut(pattern, placeholderNumber)
+			add(replacer.Replace(base), strconv.Itoa(n)+replacer.Replace(strings.ReplaceAll(rest, placeholderNumber, "")))
+		}
+	}
+	return candidates
+}
+
+// 通过一次OR过滤条件的搜索，返回names中已被sAMAccountName或服务支持的域下SMTP地址占用的名称
+func (l *ldapConnPool) takenNames(names []string) (map[string]bool, error) {
+	var filter strings.Builder
+	for _, name := range names {
+		escaped := ldap.EscapeFilter(name)
+		filter.WriteString(fmt.Sprintf("(sAMAccountName=%s)", escaped))
+		for _, domain := range l.Zones {
+			filter.WriteString(fmt.Sprintf("(proxyAddresses=smtp:%s@%s)", escaped, ldap.EscapeFilter(domain)))
+		}
+	}
+
+	taken := make(map[string]bool)
+	err := l.searchLdapObjects(func() SpecObject { return &nameOwner{} }, fmt.Sprintf("(|%s)", filter.String()), "",
+		[]string{"sAMAccountName", "proxyAddresses"}, func(obj SpecObject, _ *pooledLdapConn) error {
+			owner := obj.(*nameOwner)
+			taken[strings.ToLower(owner.SAMAccountName)] = true
+			for _, proxy := range owner.ProxyAddresses {
+				if !strings.HasPrefix(strings.ToLower(proxy), aliasSMTPPrefix) {
+					continue
+				}
+				local, domain, _ := strings.Cut(proxy[len(aliasSMTPPrefix):], "@")
+				for _, zone := range l.Zones {
+					if strings.EqualFold(domain, zone) {
+						taken[strings.ToLower(local)] = true
+					}
+				}
+			}
+			return nil
+		})
+	return taken, err
+}
+
+// 按顺序返回前count个未被占用的候选用户名，候选用户名分批校验
+func (l *ldapConnPool) suggestNames(candidates []string, count int) ([]string, error) {
+	var free []string
+	for start := 0; start < len(candidates) && len(free) < count; start += nameCheckBatch {
+		batch := candidates[start:]
+		if len(batch) > nameCheckBatch {
+			batch = batch[:nameCheckBatch]
+		}
+
+		taken, err := l.takenNames(batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range batch {
+			if !taken[name] && len(free) < count {
+				free = append(free, name)
+			}
+		}
+	}
+	return free, nil
+}
@@ -0,0 +1,80 @@
+package ldap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNameCandidates(t *testing.T) {
+	tests := []struct {
+		name      string
+		given     string
+		surname   string
+		patterns  []string
+		maxSuffix int
+		want      []string
+	}{
+		{
+			name:      "chinese name",
+			given:     "小明",
+			surname:   "王",
+			patterns:  []string{"{first}.{last}", "{f}{last}", "{first}.{last}{n}"},
+			maxSuffix: 2,
+			want:      []string{"xiaoming.wang", "xmwang", "xiaoming.wang1", "xiaoming.wang2"},
+		},
+		{
+			name:     "diacritics and duplicates",
+			given:    "José",
+			surname:  "Núñez",
+			patterns: []string{"{first}.{last}", "{first}.{last}"},
+			want:     []string{"jose.nunez"},
+		},
+		{
+			name:      "long name keeps the suffix",
+			given:     "Maximilian",
+			surname:   "Oppenheimerstein",
+			patterns:  []string{"{first}.{last}", "{first}.{last}{n}"},
+			maxSuffix: 10,
+			want: []string{
+				"maximilian.oppenheim",
+				"maximilian.oppenhei1", "maximilian.oppenhei2", "maximilian.oppenhei3", "maximilian.oppenhei4", "maximilian.oppenhei5",
+				"maximilian.oppenhei6", "maximilian.oppenhei7", "maximilian.oppenhei8", "maximilian.oppenhei9", "maximilian.oppenhe10",
+			},
+		},
+		{
+			name:     "trailing dot is trimmed",
+			given:    "Abcdefghijklmnopqrs",
+			surname:  "Smith",
+			patterns: []string{"{first}.{last}"},
+			want:     []string{"abcdefghijklmnopqrs"},
+		},
+		{
+			name:      "suffix longer than the limit",
+			given:     "Li",
+			surname:   "Lei",
+			patterns:  []string{"{first}{n}.employee.account.{last}"},
+			maxSuffix: 1,
+			want:      []string{"1.employee.account.l"},
+		},
+		{
+			name:     "missing surname",
+			given:    "Alice",
+			surname:  "!!",
+			patterns: []string{"{first}.{last}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nameCandidates(tt.given, tt.surname, tt.patterns, tt.maxSuffix)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nameCandidates() = %q, want %q", got, tt.want)
+			}
+			for _, candidate := range got {
+				if len(candidate) > maxAccountNameLen {
+					t.Errorf("candidate %q is longer than %d", candidate, maxAccountNameLen)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package ldap
+
+import (
+	"errors"
+	"ldap-http-service/lib/ers"
+	"sort"
+	"strings"
+)
+
+// 组织架构图需要获取的用户属性
+var orgAttrs = []string{"objectGUID", "sAMAccountName", "displayName", "title", "department", "mail", "employeeID", "manager", "directReports"}
+
+// OrgPerson 组织架构图中的人员
+type OrgPerson struct {
+	DistinguishedName string `json:"distinguishedName"`
+	ObjectGUID        string `json:"objectGUID"`
+	SAMAccountName    string `json:"sAMAccountName"`
+	DisplayName       string `json:"displayName"`
+	Title             string `json:"title,omitempty"`
+	Department        string `json:"department,omitempty"`
+	Mail              string `json:"mail,omitempty"`
+	EmployeeID        string `json:"employeeID,omitempty"`
+}
+
+// OrgNode 组织架构图中的节点，HasMoreReports为true时表示超出遍历层数、未展开的直接下属
+type OrgNode struct {
+	OrgPerson
+	DirectReports  []*OrgNode `json:"directReports"`
+	HasMoreReports bool       `json:"hasMoreReports,omitempty"`
+}
+
+// OrgChart 用户的汇报关系，Managers为经理链，按由近到远排列；Cycles为遍历时发现的形成循环的汇报关系中的人员
+type OrgChart struct {
+	User     *OrgNode    `json:"user"`
+	Managers []OrgPerson `json:"managers"`
+	Cycles   []string    `json:"cycles,omitempty"`
+}
+
+func orgPerson(user *User) OrgPerson {
+	return OrgPerson{
+		DistinguishedName: user.DistinguishedName,
+		ObjectGUID:        user.ObjectGUID,
+		SAMAccountName:    user.SAMAccountName,
+		DisplayName:       user.DisplayName,
+		Title:             user.Title,
+		Department:        user.Department,
+		Mail:              user.Mail,
+		EmployeeID:        user.EmployeeID,
+	}
+}
+
+// 校验将managerDN设置为userDN的经理后不会形成循环：沿经理的经理链向上查找，链中出现该用户即为循环；
+// 链中已存在与该用户无关的循环或经理不是用户（例如联系人）时停止查找
+func (l *ldapConnPool) checkManagerCycle(userDN, managerDN string) error {
+	visited := make(map[string]bool)
+	for current := managerDN; current != ""; {
+		if strings.EqualFold(current, userDN) {
+			return &ers.ManagerCycleErr{User: userDN, Manager: managerDN}
+		}
+		if visited[strings.ToLower(current)] {
+			return nil
+		}
+		visited[strings.ToLower(current)] = true
+
+		manager, err := l.getUser(current, "distinguishedName", "", "manager")
+		var notFound *ers.NotFoundError
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		current = manager.Manager
+	}
+	return nil
+}
+
+// 从用户开始向上沿经理链、向下逐层沿直接下属各遍历depth层，同一人员只访问一次以避免循环的汇报关系；
+// 每层的直接下属通过一次批量搜索获取，不是用户的经理及下属（例如联系人）被忽略
+func (l *ldapConnPool) orgChart(user *User, depth int) (*OrgChart, error) {
+	chart := &OrgChart{User: &OrgNode{OrgPerson: orgPerson(user)}, Managers: []OrgPerson{}}
+	visited := map[string]bool{strings.ToLower(user.DistinguishedName): true}
+
+	current := user.Manager
+	for i := 0; i < depth && current != ""; i++ {
+		if visited[strings.ToLower(current)] {
+			chart.Cycles = append(chart.Cycles, current)
+			break
+		}
+		visited[strings.ToLower(current)] = true
+
+		manager, err := l.getUser(current, "distinguishedName", "", orgAttrs...)
+		var notFound *ers.NotFoundError
+		if errors.As(err, &notFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		chart.Managers = append(chart.Managers, orgPerson(&manager))
+		current = manager.Manager
+	}
+
+	// 当前层的节点及其直接下属的DN
+	level := map[*OrgNode][]string{chart.User: user.DirectReports}
+	for d := 0; d < depth && len(level) > 0; d++ {
+		var dns []string
+		for _, reports := range level {
+			for _, dn := range reports {
+				if visited[strings.ToLower(dn)] {
+					chart.Cycles = append(chart.Cycles, dn)
+					continue
+				}
+				visited[strings.ToLower(dn)] = true
+				dns = append(dns, dn)
+			}
+		}
+
+		found, _, err := l.lookupObjects(func() SpecObject { return &User{} }, memberFilters[MemberUser], dns, "distinguishedName", "", orgAttrs, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		next := make(map[*OrgNode][]string)
+		for node, reports := range level {
+			node.DirectReports = []*OrgNode{}
+			for _, dn := range reports {
+				obj, ok := found[dn]
+				if !ok {
+					continue
+				}
+				report := obj.(*User)
+				child := &OrgNode{OrgPerson: orgPerson(report)}
+				node.DirectReports = append(node.DirectReports, child)
+				next[child] = report.DirectReports
+			}
+			sort.SliceStable(node.DirectReports, func(i, j int) bool {
+				return strings.ToLower(node.DirectReports[i].DisplayName) < strings.ToLower(node.DirectReports[j].DisplayName)
+			})
+		}
+		level = next
+	}
+
+	for node, reports := range level {
+		node.DirectReports = []*OrgNode{}
+		node.HasMoreReports = len(reports) > 0
+	}
+	return chart, nil
+}
@@ -0,0 +1,139 @@
+package ldap
+
+import (
+	"fmt"
+	"github.com/go-ldap/ldap"
+	"ldap-http-service/lib/utils"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// 得分相同的人员搜索结果按类型排列的顺序
+var personTypeRank = map[string]int{MemberUser: 0, MemberGroup: 1, MemberContact: 2}
+
+// Person 人员选择器使用的轻量搜索结果，Score为匹配程度，越大越接近
+type Person struct {
+	Type              string `json:"type"`
+	DistinguishedName string `json:"distinguishedName"`
+	ObjectGUID        string `json:"objectGUID"`
+	SAMAccountName    string `json:"sAMAccountName,omitempty"`
+	DisplayName       string `json:"displayName"`
+	Mail              string `json:"mail,omitempty"`
+	Score             int    `json:"score"`
+}
+
+// personEntry 人员搜索需要获取的属性
+type personEntry struct {
+	BaseObject
+	Mail      string `ldap:"mail" json:"mail"`
+	GivenName string `ldap:"givenName" json:"givenName"`
+	Sn        string `ldap:"sn" json:"sn"`
+}
+
+func (p *personEntry) ReturnBaseObj() *BaseObject {
+	return &p.BaseObject
+}
+
+func (p *personEntry) GetSpecType() reflect.Type {
+	return reflect.TypeOf(*p)
+}
+
+var personAttrs = []string{"objectClass", "objectGUID", "sAMAccountName", "displayName", "mail", "givenName", "sn"}
+
+// 计算搜索词与对象的匹配程度：完全匹配 > 名或姓、邮件前缀完全匹配 > 前缀匹配 > 包含；
+// 以上都不满足时说明对象通过ANR的其他属性匹配，得分最低
+func matchScore(q string, p *personEntry) int {
+	q = strings.ToLower(q)
+	local, _, _ := strings.Cut(p.Mail, "@")
+	fields := []struct {
+		value          string
+		exact, partial int
+	}{
+		{p.SAMAccountName, 100, 80},
+		{p.Mail, 100, 80},
+		{p.DisplayName, 100, 80},
+		{local, 90, 80},
+		{p.GivenName, 90, 60},
+		{p.Sn, 90, 60},
+	}
+
+	score := 10
+	raise := func(s int) {
+		if s > score {
+			score = s
+		}
+	}
+	for _, field := range fields {
+		value := strings.ToLower(field.value)
+		switch {
+		case value == "":
+		case value == q:
+			raise(field.exact)
+		case strings.HasPrefix(value, q):
+			raise(field.partial)
+		case strings.Contains(value, q):
+			raise(40)
+		}
+	}
+	for _, word := range strings.Fields(strings.ToLower(p.DisplayName)) {
+		if strings.HasPrefix(word, q) {
+			raise(60)
+		}
+	}
+	return score
+}
+
+// 人员搜索最多从目录中获取sizeLimit的倍数个匹配对象参与排序
+const peopleScanFactor = 10
+
+// 通过ANR搜索用户、群组及联系人，按匹配程度排序后返回前sizeLimit个结果；
+// 最多获取sizeLimit*peopleScanFactor个匹配的对象后再排序，过于宽泛的搜索词可能丢失目录返回顺序靠后但更接近的结果
+func (l *ldapConnPool) searchPeople(q string, sizeLimit int) ([]Person, error) {
+	escaped := ldap.EscapeFilter(q)
+	filter := fmt.Sprintf("(&(|%s%s%s)(|(anr=%s)(mail=%s*)))",
+		memberFilters[MemberUser], memberFilters[MemberGroup], memberFilters[MemberContact], escaped, escaped)
+
+	people := make([]Person, 0)
+	err := l.searchLdapObjects(func() SpecObject { return &personEntry{} }, filter, "", personAttrs,
+		func(obj SpecObject, _ *pooledLdapConn) error {
+			entry := obj.(*personEntry)
+			person := Person{
+				Type:              MemberUser,
+				DistinguishedName: entry.DistinguishedName,
+				ObjectGUID:        entry.ObjectGUID,
+				SAMAccountName:    entry.SAMAccountName,
+				DisplayName:       entry.DisplayName,
+				Mail:              entry.Mail,
+				Score:             matchScore(q, entry),
+			}
+			for _, t := range []string{MemberGroup, MemberContact} {
+				if utils.InSliceIC(entry.ObjectClass, t) {
+					person.Type = t
+				}
+			}
+
+			people = append(people, person)
+			if len(people) >= sizeLimit*peopleScanFactor {
+				return errStopSearch
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(people, func(i, j int) bool {
+		if people[i].Score != people[j].Score {
+			return people[i].Score > people[j].Score
+		}
+		if people[i].Type != people[j].Type {
+			return personTypeRank[people[i].Type] < personTypeRank[people[j].Type]
+		}
+		return strings.ToLower(people[i].DisplayName) < strings.ToLower(people[j].DisplayName)
+	})
+	if len(people) > sizeLimit {
+		people = people[:sizeLimit]
+	}
+	return people, nil
+}
@@ -0,0 +1,23 @@
+package ldap
+
+import "reflect"
+
+// userPhoto 用户的照片，thumbnailPhoto较大，不包含在User中，仅在照片接口中单独获取
+type userPhoto struct {
+	BaseObject
+	ThumbnailPhoto []byte `ldap:"thumbnailPhoto" json:"thumbnailPhoto"`
+}
+
+func (p *userPhoto) ReturnBaseObj() *BaseObject {
+	return &p.BaseObject
+}
+
+func (p *userPhoto) GetSpecType() reflect.Type {
+	return reflect.TypeOf(*p)
+}
+
+// 获取用户的thumbnailPhoto，用户没有照片时ThumbnailPhoto为空
+func (l *ldapConnPool) getUserPhoto(userId, userIdType, searchBase string) (photo userPhoto, err error) {
+	err = l.searchUser(&photo, userId, userIdType, searchBase, []string{"thumbnailPhoto"})
+	return
+}
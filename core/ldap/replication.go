@@ -0,0 +1,211 @@
+package ldap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/go-ldap/ldap"
+	"ldap-http-service/lib/ers"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Show Deleted控件，搜索结果中包含已删除的对象（墓碑）
+const controlTypeShowDeleted = "1.2.840.113556.1.4.417"
+
+// Deleted Objects容器的知名GUID
+const deletedObjectsWKGUID = "18e2ea80684f11d2b9aa00c04f79f805"
+
+// DeletedObject 已删除的对象（墓碑），DistinguishedName为墓碑的DN
+type DeletedObject struct {
+	BaseObject
+	LastKnownParent string `ldap:"lastKnownParent" json:"lastKnownParent"`
+}
+
+func (d *DeletedObject) ReturnBaseObj() *BaseObject {
+	return &d.BaseObject
+}
+
+func (d *DeletedObject) GetSpecType() reflect.Type {
+	return reflect.TypeOf(*d)
+}
+
+// OriginalDN 按墓碑的名称及lastKnownParent还原对象删除前的DN，无法还原时返回墓碑的DN
+func (d *DeletedObject) OriginalDN() string {
+	rdn, _, found := strings.Cut(d.DistinguishedName, "\\0ADEL:")
+	if !found || d.LastKnownParent == "" {
+		return d.DistinguishedName
+	}
+	return rdn + "," + d.LastKnownParent
+}
+
+// MemberChange 群组成员的单个变更，Removed为true代表成员被移除
+type MemberChange struct {
+	Member  string
+	Removed bool
+	USN     int64
+}
+
+// replValueMetaData msDS-ReplValueMetaData中单个链接值的复制元数据
+type replValueMetaData struct {
+	AttributeName  string `xml:"pszAttributeName"`
+	ObjectDN       string `xml:"pszObjectDn"`
+	TimeDeleted    string `xml:"ftimeDeleted"`
+	USNLocalChange int64  `xml:"usnLocalChange"`
+}
+
+// 链接值未被删除时ftimeDeleted的取值
+const replTimeZero = "1601-01-01T00:00:00Z"
+
+// 解析msDS-ReplValueMetaData的取值，返回attribute在本地USN大于since之后的变更
+func parseValueMetaData(values []string, attribute string, since int64) ([]MemberChange, error) {
+	var changes []MemberChange
+	for _, value := range values {
+		var meta replValueMetaData
+		if err := xml.Unmarshal([]byte(strings.TrimRight(value, "\x00")), &meta); err != nil {
+			return nil, &ers.InvalidFormatErr{Name: "msDS-ReplValueMetaData", Object: value}
+		}
+		if !strings.EqualFold(meta.AttributeName, attribute) || meta.USNLocalChange <= since {
+			continue
+		}
+		changes = append(changes, MemberChange{
+			Member:  meta.ObjectDN,
+			Removed: meta.TimeDeleted != "" && meta.TimeDeleted != replTimeZero,
+			USN:     meta.USNLocalChange,
+		})
+	}
+	return changes, nil
+}
+
+// 读取rootDSE的单个属性
+func (l *ldapConnPool) rootDSE(conn *pooledLdapConn, attribute string) (string, error) {
+	sr, err := conn.Search(ldap.NewSearchRequest(
+		"",
+		ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)",
+		[]string{attribute},
+		nil,
+	))
+	if err != nil {
+		return "", err
+	}
+	if len(sr.Entries) == 0 {
+		return "", &ers.NotFoundError{Object: "rootDSE"}
+	}
+	return sr.Entries[0].GetAttributeValue(attribute), nil
+}
+
+// 获取当前DC已提交的最大USN，uSNChanged仅在单个DC内有效
+func (l *ldapConnPool) highestCommittedUSN() (int64, error) {
+	conn, err := l.getConn()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	value, err := l.rootDSE(conn, "highestCommittedUSN")
+	if err != nil {
+		return 0, err
+	}
+	usn, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &ers.InvalidFormatErr{Name: "highestCommittedUSN", Object: value}
+	}
+	return usn, nil
+}
+
+// 获取群组本地USN大于since的成员变更，按范围分页读取msDS-ReplValueMetaData；
+// 只包含林功能级别启用链接值复制（LVR）之后写入的成员，更早写入且之后未变更的成员没有值级元数据
+func (l *ldapConnPool) memberChangesSince(groupDN string, since int64) ([]MemberChange, error) {
+	conn, err := l.getConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var changes []MemberChange
+	for pageTop := 0; ; pageTop += 1500 {
+		sr, err := conn.Search(ldap.NewSearchRequest(
+			groupDN,
+			ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+			"(objectClass=*)",
+			[]string{fmt.Sprintf("msDS-ReplValueMetaData;range=%d-*", pageTop)},
+			nil,
+		))
+		if err != nil {
+			if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+				return nil, &ers.NotFoundError{Object: groupDN}
+			}
+			return nil, err
+		}
+		if len(sr.Entries) == 0 {
+			return changes, nil
+		}
+
+		last := true
+		for _, attr := range sr.Entries[0].Attributes {
+			name := strings.ToLower(attr.Name)
+			if !strings.HasPrefix(name, "msds-replvaluemetadata") {
+				continue
+			}
+			page, err := parseValueMetaData(attr.Values, "member", since)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, page...)
+			// 返回的范围不以 "*" 结束时代表还有更多的值
+			last = !strings.Contains(name, ";range=") || strings.HasSuffix(name, "-*")
+		}
+		if last {
+			return changes, nil
+		}
+	}
+}
+
+// 分页搜索Deleted Objects容器中匹配过滤条件的墓碑，每解析出一个对象调用一次fn；
+// 墓碑不保留objectCategory，过滤条件需要使用objectClass
+func (l *ldapConnPool) searchDeleted(filter string, fn func(*DeletedObject) error) error {
+	conn, err := l.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	namingContext, err := l.rootDSE(conn, "defaultNamingContext")
+	if err != nil {
+		return err
+	}
+
+	attributes := []string{"objectGUID", "distinguishedName", "objectClass", "uSNChanged", "lastKnownParent"}
+	paging := ldap.NewControlPaging(searchPageSize)
+	searchRequest := ldap.NewSearchRequest(
+		fmt.Sprintf("<WKGUID=%s,%s>", deletedObjectsWKGUID, namingContext),
+		ldap.ScopeSingleLevel, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf("(&(isDeleted=TRUE)%s)", filter),
+		attributes,
+		[]ldap.Control{paging, ldap.NewControlString(controlTypeShowDeleted, true, "")},
+	)
+
+	for {
+		sr, err := conn.Search(searchRequest)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range sr.Entries {
+			obj := &DeletedObject{}
+			if err = mapEntry(obj, entry, attributes); err != nil {
+				return err
+			}
+			if err = fn(obj); err != nil {
+				return err
+			}
+		}
+
+		control, ok := ldap.FindControl(sr.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+		if !ok || len(control.Cookie) == 0 {
+			return nil
+		}
+		paging.SetCookie(control.Cookie)
+	}
+}
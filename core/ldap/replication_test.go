@@ -0,0 +1,60 @@
+package ldap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseValueMetaData(t *testing.T) {
+	value := func(attr, dn, deleted, usn string) string {
+		return "<DS_REPL_VALUE_META_DATA>\n\t<pszAttributeName>" + attr + "</pszAttributeName>\n\t<pszObjectDn>" + dn +
+			"</pszObjectDn>\n\t<cbData>0</cbData>\n\t<pbData></pbData>\n\t<ftimeDeleted>" + deleted +
+			"</ftimeDeleted>\n\t<ftimeCreated>2024-01-01T00:00:00Z</ftimeCreated>\n\t<dwVersion>1</dwVersion>\n\t<usnOriginatingChange>" + usn +
+			"</usnOriginatingChange>\n\t<usnLocalChange>" + usn + "</usnLocalChange>\n</DS_REPL_VALUE_META_DATA>\n\x00"
+	}
+
+	tests := []struct {
+		name    string
+		values  []string
+		want    []MemberChange
+		wantErr bool
+	}{
+		{
+			name: "added and removed",
+			values: []string{
+				value("member", "CN=Alice,OU=People,DC=example,DC=com", replTimeZero, "120"),
+				value("member", "CN=Bob,OU=People,DC=example,DC=com", "2024-02-01T00:00:00Z", "130"),
+			},
+			want: []MemberChange{
+				{Member: "CN=Alice,OU=People,DC=example,DC=com", USN: 120},
+				{Member: "CN=Bob,OU=People,DC=example,DC=com", Removed: true, USN: 130},
+			},
+		},
+		{
+			name:   "changes before the cursor are skipped",
+			values: []string{value("member", "CN=Alice,DC=example,DC=com", replTimeZero, "100")},
+		},
+		{
+			name:   "other linked attributes are skipped",
+			values: []string{value("msExchCoManagedByLink", "CN=Alice,DC=example,DC=com", replTimeZero, "120")},
+		},
+		{
+			name:   "escaped dn",
+			values: []string{value("member", "CN=R&amp;D,DC=example,DC=com", replTimeZero, "101")},
+			want:   []MemberChange{{Member: "CN=R&D,DC=example,DC=com", USN: 101}},
+		},
+		{name: "malformed", values: []string{"<DS_REPL_VALUE_META_DATA>"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseValueMetaData(tt.values, "member", 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseValueMetaData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseValueMetaData() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -14,6 +14,12 @@ type User struct {
 	BaseObject
 	Company                    string   `ldap:"company" json:"company"`
 	Department                 string   `ldap:"department" json:"department"`
+	GivenName                  string   `ldap:"givenName" json:"givenName"`
+	Sn                         string   `ldap:"sn" json:"sn"`
+	Manager                    string   `ldap:"manager" json:"manager"`
+	DirectReports              []string `ldap:"directReports" json:"directReports"`
+	Title                      string   `ldap:"title" json:"title"`
+	EmployeeID                 string   `ldap:"employeeID" json:"employeeID"`
 	PhysicalDeliveryOfficeName string   `ldap:"physicalDeliveryOfficeName" json:"physicalDeliveryOfficeName"`
 	MemberOf                   []string `ldap:"memberOf" json:"memberOf"`
 	PwdLastSet                 FileTime `ldap:"pwdLastSet" json:"pwdLastSet"`
@@ -42,7 +48,7 @@ func (u *User) GetSpecType() reflect.Type {
 }
 
 // 创建用户
-func (l *ldapConnPool) createUser(userDN, displayName, primaryDomain string) error {
+func (l *ldapConnPool) createUser(userDN, displayName, primaryDomain string, attrs map[string]string) error {
 	conn, err := l.getConn()
 	if err != nil {
 		return err
@@ -63,6 +69,11 @@ func (l *ldapConnPool) createUser(userDN, displayName, primaryDomain string) err
 	addRequest.Attribute("userAccountControl", []string{fmt.Sprintf("%d", 0x0202)})
 	addRequest.Attribute("userPrincipalName", []string{fmt.Sprintf("%s@%s", username, primaryDomain)})
 	addRequest.Attribute("accountExpires", []string{fmt.Sprintf("%d", 0x00000000)})
+	for attr, value := range attrs {
+		if value != "" {
+			addRequest.Attribute(attr, []string{value})
+		}
+	}
 	err = conn.Add(addRequest)
 	if err != nil {
 		return &ers.OptErr{Option: "create user", Message: err.Error()}
@@ -70,8 +81,8 @@ func (l *ldapConnPool) createUser(userDN, displayName, primaryDomain string) err
 	return nil
 }
 
-// 设置用户密码
-func (l *ldapConnPool) setPassword(userDN, password string) error {
+// 设置用户密码，version不为空时要求用户的uSNChanged与其一致
+func (l *ldapConnPool) setPassword(userDN, password, version string) error {
 	username := strings.Replace(strings.ToLower(strings.Split(userDN, ",")[0]), "cn=", "", 1)
 	if !utils.IsStrongPassword(username, password) {
 		return &ers.OptErr{Option: fmt.Sprintf("set password for '%s'", username), Message: "password is not strong enough"}
@@ -89,10 +100,18 @@ func (l *ldapConnPool) setPassword(userDN, password string) error {
 		return err
 	}
 
-	modReq := ldap.NewModifyRequest(userDN, []ldap.Control{})
+	controls, err := l.versionControls(conn, userDN, version)
+	if err != nil {
+		return err
+	}
+
+	modReq := ldap.NewModifyRequest(userDN, controls)
 	modReq.Replace("unicodePwd", []string{pwdEncoded})
 
 	if err = conn.Modify(modReq); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultAssertionFailed) {
+			return &ers.PreconditionFailedErr{Object: userDN, Version: version}
+		}
 		return &ers.OptErr{Option: fmt.Sprintf("set password for '%s'", userDN), Message: err.Error()}
 	}
 	return nil
@@ -132,18 +151,33 @@ func (l *ldapConnPool) unlockAccount(userDN string) error {
 	return nil
 }
 
-// 根据结构体获取用户信息
-func (l *ldapConnPool) getUser(userId, userIdType, searchBase string) (user User, err error) {
+// 根据结构体获取用户信息，attributes为需要获取的属性，为空时获取全部属性
+func (l *ldapConnPool) getUser(userId, userIdType, searchBase string, attributes ...string) (user User, err error) {
+	err = l.searchUser(&user, userId, userIdType, searchBase, attributes)
+	return
+}
+
+// 按标识查找用户并读取到obj中，供需要读取User以外属性（例如照片）的查询复用
+func (l *ldapConnPool) searchUser(obj SpecObject, userId, userIdType, searchBase string, attributes []string) error {
 	if userIdType == "objectGUID" {
-		userId, err = unFormatGUID(userId)
-		if err != nil {
-			return
+		var err error
+		if userId, err = unFormatGUID(userId); err != nil {
+			return err
 		}
 	}
 
 	// 设置ldap过滤查询条件
 	filter := fmt.Sprintf("(&(objectClass=user)(objectCategory=person)(%s=%s))", userIdType, ldap.EscapeFilter(userId))
 
-	err = l.searchLdapObject(&user, filter, searchBase)
-	return
+	return l.searchLdapObject(obj, filter, searchBase, attributes)
+}
+
+// 搜索所有匹配过滤条件的用户，filter为附加的LDAP过滤条件，为空时返回全部用户
+func (l *ldapConnPool) searchUsers(filter, searchBase string, attributes []string, fn func(*User) error) error {
+	filter = fmt.Sprintf("(&(objectClass=user)(objectCategory=person)%s)", filter)
+	return l.searchLdapObjects(func() SpecObject { return &User{} }, filter, searchBase, attributes,
+		func(obj SpecObject, _ *pooledLdapConn) error {
+			return fn(obj.(*User))
+		},
+	)
 }
@@ -47,6 +47,16 @@ func formatGUID(guidBytes []byte) (string, error) {
 	return guid, nil
 }
 
+// 将GUID字符串规范化为formatGUID输出的小写带破折号形式，支持大写及不带破折号的GUID
+func canonicalGUID(guidStr string) (string, error) {
+	raw, err := hex.DecodeString(strings.ReplaceAll(guidStr, "-", ""))
+	if err != nil || len(raw) != 16 {
+		return "", fmt.Errorf("invalid GUID '%s'", guidStr)
+	}
+	h := hex.EncodeToString(raw)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
+
 func unFormatGUID(guidStr string) (string, error) {
 	// 去掉破折号
 	noDash := strings.ReplaceAll(guidStr, "-", "")
@@ -56,6 +66,9 @@ func unFormatGUID(guidStr string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("hex decode string error: %v", err)
 	}
+	if len(bytes) != 16 {
+		return "", fmt.Errorf("invalid GUID length: expected 16, got %d", len(bytes))
+	}
 
 	// 格式转换
 	b := []byte{bytes[3], bytes[2], bytes[1], bytes[0], bytes[5], bytes[4], bytes[7], bytes[6], bytes[8], bytes[9], bytes[10], bytes[11], bytes[12], bytes[13], bytes[14], bytes[15]}
@@ -63,6 +76,17 @@ func unFormatGUID(guidStr string) (string, error) {
 	return string(b), nil
 }
 
+// 校验附加的LDAP过滤条件语法，空字符串代表不附加过滤条件
+func checkFilter(filter string) error {
+	if filter == "" {
+		return nil
+	}
+	if _, err := ldap.CompileFilter(filter); err != nil {
+		return &ers.InvalidFormatErr{Name: "filter", Object: filter}
+	}
+	return nil
+}
+
 func parseGeneralizedTime(generalizedTime string) (GeneralizedTime, error) {
 	layout := "20060102150405.0Z"
 	parsedTime, err := time.Parse(layout, generalizedTime)
@@ -143,10 +167,14 @@ func setLdapAttr(name string, field reflect.Value, attr *ldap.EntryAttribute) er
 				}
 			}
 		case reflect.Slice:
-			if field.Type().Elem().Kind() == reflect.String {
+			switch field.Type().Elem().Kind() {
+			case reflect.String:
 				strSlice := make([]string, len(attr.Values))
 				copy(strSlice, attr.Values)
 				field.Set(reflect.ValueOf(strSlice))
+			case reflect.Uint8:
+				// 二进制属性，例如thumbnailPhoto，取第一个值的原始字节
+				field.SetBytes(append([]byte{}, attr.ByteValues[0]...))
 			}
 		}
 	}
@@ -0,0 +1,169 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	coreldap "ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/logger"
+	"net/http"
+	"strconv"
+)
+
+// Error SCIM协议错误，ScimType为RFC 7644 3.12中定义的错误类型
+type Error struct {
+	ers.BaseErr
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func (e *Error) HttpCode() int {
+	return e.Status
+}
+
+// NewErrorResponse 将错误转换为SCIM错误响应，返回http状态码与响应体
+func NewErrorResponse(err error) (int, *ErrorResponse) {
+	response := &ErrorResponse{Schemas: []string{SchemaError}, Detail: "Internal Server Error"}
+	status := http.StatusInternalServerError
+
+	var scimErr *Error
+	var existErr *ers.ObjExistError
+	var customErr ers.CustomErr
+	switch {
+	case errors.As(err, &scimErr):
+		status, response.ScimType, response.Detail = scimErr.Status, scimErr.ScimType, scimErr.Detail
+	case errors.As(err, &existErr):
+		status, response.ScimType, response.Detail = existErr.HttpCode(), "uniqueness", existErr.Error()
+	case errors.As(err, &customErr):
+		status, response.Detail = customErr.HttpCode(), customErr.Error()
+	}
+	response.Status = strconv.Itoa(status)
+	return status, response
+}
+
+// ListUsers 按SCIM过滤条件分页查询用户，count小于0代表使用默认单页数量，excluded为不需要返回的属性
+func ListUsers(tractx context.Context, filter string, startIndex, count int, excluded []string) (*ListResponse, error) {
+	return listUsers(tractx, filter, startIndex, count, excluded)
+}
+
+// GetUser 按id获取用户
+func GetUser(tractx context.Context, id string, excluded []string) (*User, error) {
+	return readUser(tractx, id, excluded)
+}
+
+// CreateUser 创建用户，未指定密码时生成随机密码
+func CreateUser(tractx context.Context, u *User) (*User, error) {
+	logger.LdapLogger.WithContext(tractx).Infof("正在通过SCIM创建用户 `%s` ...", u.UserName)
+	return createUser(tractx, u)
+}
+
+// ReplaceUser 以请求中的用户资源替换用户的全部可写属性，version不为空时要求用户的uSNChanged与其一致
+func ReplaceUser(tractx context.Context, id, version string, u *User) (*User, error) {
+	user, err := getUser(tractx, id)
+	if err != nil {
+		return nil, err
+	}
+	update, err := userUpdateFromResource(tractx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在通过SCIM替换用户 `%s` ...", user.DistinguishedName)
+	if err = applyUserUpdate(tractx, &user, version, update); err != nil {
+		return nil, err
+	}
+	return readUser(tractx, id, nil)
+}
+
+// PatchUser 对用户执行PATCH操作，version不为空时要求用户的uSNChanged与其一致
+func PatchUser(tractx context.Context, id, version string, patch *PatchOp) (*User, error) {
+	user, err := getUser(tractx, id)
+	if err != nil {
+		return nil, err
+	}
+	update, err := userUpdateFromPatch(tractx, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在通过SCIM修改用户 `%s` ...", user.DistinguishedName)
+	if err = applyUserUpdate(tractx, &user, version, update); err != nil {
+		return nil, err
+	}
+	return readUser(tractx, id, nil)
+}
+
+// DeleteUser 删除用户，version不为空时要求用户的uSNChanged与其一致
+func DeleteUser(tractx context.Context, id, version string) error {
+	user, err := getUser(tractx, id)
+	if err != nil {
+		return err
+	}
+	return coreldap.DeleteObj(tractx, user.DistinguishedName, version)
+}
+
+// ListGroups 按SCIM过滤条件分页查询群组，count小于0代表使用默认单页数量，excluded为不需要返回的属性
+func ListGroups(tractx context.Context, filter string, startIndex, count int, excluded []string) (*ListResponse, error) {
+	return listGroups(tractx, filter, startIndex, count, excluded)
+}
+
+// GetGroup 按id获取群组
+func GetGroup(tractx context.Context, id string, excluded []string) (*Group, error) {
+	return readGroup(tractx, id, excluded)
+}
+
+// CreateGroup 创建群组并添加成员
+func CreateGroup(tractx context.Context, g *Group) (*Group, error) {
+	logger.LdapLogger.WithContext(tractx).Infof("正在通过SCIM创建群组 `%s` ...", g.DisplayName)
+	return createGroup(tractx, g)
+}
+
+// ReplaceGroup 以请求中的群组资源替换群组的displayName及全部成员，version不为空时要求群组的uSNChanged与其一致
+func ReplaceGroup(tractx context.Context, id, version string, g *Group) (*Group, error) {
+	group, err := getGroup(tractx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	update, err := groupUpdateFromResource(tractx, g)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在通过SCIM替换群组 `%s` ...", group.DistinguishedName)
+	if err = applyGroupUpdate(tractx, &group, version, update); err != nil {
+		return nil, err
+	}
+	return readGroup(tractx, id, nil)
+}
+
+// PatchGroup 对群组执行PATCH操作，version不为空时要求群组的uSNChanged与其一致
+func PatchGroup(tractx context.Context, id, version string, patch *PatchOp) (*Group, error) {
+	group, err := getGroup(tractx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	update, err := groupUpdateFromPatch(tractx, &group, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("正在通过SCIM修改群组 `%s` ...", group.DistinguishedName)
+	if err = applyGroupUpdate(tractx, &group, version, update); err != nil {
+		return nil, err
+	}
+	return readGroup(tractx, id, nil)
+}
+
+// DeleteGroup 删除群组，version不为空时要求群组的uSNChanged与其一致
+func DeleteGroup(tractx context.Context, id, version string) error {
+	group, err := getGroup(tractx, id, false)
+	if err != nil {
+		return err
+	}
+	return coreldap.DeleteObj(tractx, group.DistinguishedName, version)
+}
@@ -0,0 +1,333 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-ldap/ldap"
+	coreldap "ldap-http-service/core/ldap"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 过滤条件中属性值的类型，决定比较运算如何转换为LDAP过滤条件
+const (
+	kindString = iota
+	kindGUID
+	kindActive
+	kindTime
+)
+
+// filterAttr SCIM属性在LDAP中对应的属性及值类型
+type filterAttr struct {
+	ldap string
+	kind int
+}
+
+// 用户资源支持过滤的属性，键为小写且去掉了核心schema前缀的属性路径
+var userFilterAttrs = map[string]filterAttr{
+	"id":                {"objectGUID", kindGUID},
+	"displayname":       {"displayName", kindString},
+	"name.givenname":    {"givenName", kindString},
+	"name.familyname":   {"sn", kindString},
+	"emails":            {"mail", kindString},
+	"emails.value":      {"mail", kindString},
+	"active":            {"userAccountControl", kindActive},
+	"meta.created":      {"whenCreated", kindTime},
+	"meta.lastmodified": {"whenChanged", kindTime},
+	"organization":      {"company", kindString},
+	"department":        {"department", kindString},
+}
+
+// 群组资源支持过滤的属性
+var groupFilterAttrs = map[string]filterAttr{
+	"id":                {"objectGUID", kindGUID},
+	"displayname":       {"displayName", kindString},
+	"meta.created":      {"whenCreated", kindTime},
+	"meta.lastmodified": {"whenChanged", kindTime},
+}
+
+// 用户的userName在LDAP中对应的属性随配置变化，因此在过滤时单独处理
+func userFilterAttr(path string) (filterAttr, bool) {
+	if path == "username" {
+		return filterAttr{userNameAttr(), kindString}, true
+	}
+	attr, ok := userFilterAttrs[path]
+	return attr, ok
+}
+
+func groupFilterAttr(path string) (filterAttr, bool) {
+	attr, ok := groupFilterAttrs[path]
+	return attr, ok
+}
+
+// 去掉属性路径中的schema前缀并转为小写，企业扩展属性只保留属性名
+func normalizePath(path string) string {
+	path = strings.ToLower(strings.TrimSpace(path))
+	for _, schema := range []string{SchemaUser, SchemaGroup, SchemaEnterpriseUser} {
+		if prefix := strings.ToLower(schema) + ":"; strings.HasPrefix(path, prefix) {
+			return strings.TrimPrefix(path, prefix)
+		}
+	}
+	return path
+}
+
+func filterErr(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusBadRequest, ScimType: "invalidFilter", Detail: fmt.Sprintf(format, args...)}
+}
+
+// filterParser 按RFC 7644 3.4.2.2的语法将SCIM过滤条件解析为LDAP过滤条件，不支持值路径 (如emails[type eq "work"])
+type filterParser struct {
+	tokens []string
+	pos    int
+	attr   func(path string) (filterAttr, bool)
+}
+
+// 将SCIM过滤条件转换为LDAP过滤条件，filter为空时返回空字符串
+func compileFilter(filter string, attr func(path string) (filterAttr, bool)) (string, error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", nil
+	}
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return "", err
+	}
+
+	p := &filterParser{tokens: tokens, attr: attr}
+	result, err := p.parseOr()
+	if err != nil {
+		return "", err
+	}
+	if p.pos < len(p.tokens) {
+		return "", filterErr("unexpected token '%s'", p.tokens[p.pos])
+	}
+	return result, nil
+}
+
+// 拆分过滤条件，引号内的字符串作为一个完整的词，保留引号用于区分字符串与关键字
+func tokenize(filter string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(filter); {
+		switch c := filter[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '[' || c == ']':
+			return nil, filterErr("value path filters are not supported")
+		case c == '"':
+			j := i + 1
+			for ; j < len(filter) && filter[j] != '"'; j++ {
+				if filter[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(filter) {
+				return nil, filterErr("unterminated string in filter")
+			}
+			tokens = append(tokens, filter[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for ; j < len(filter) && !strings.ContainsRune(" \t()[]\"", rune(filter[j])); j++ {
+			}
+			tokens = append(tokens, filter[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", filterErr("unexpected end of filter")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *filterParser) parseOr() (string, error) {
+	return p.parseLogical("or", "|", p.parseAnd)
+}
+
+func (p *filterParser) parseAnd() (string, error) {
+	return p.parseLogical("and", "&", p.parseNot)
+}
+
+// 解析由同一逻辑运算符连接的多个条件
+func (p *filterParser) parseLogical(keyword, operator string, operand func() (string, error)) (string, error) {
+	first, err := operand()
+	if err != nil {
+		return "", err
+	}
+	filters := []string{first}
+	for strings.EqualFold(p.peek(), keyword) {
+		p.pos++
+		f, err := operand()
+		if err != nil {
+			return "", err
+		}
+		filters = append(filters, f)
+	}
+	if len(filters) == 1 {
+		return first, nil
+	}
+	return fmt.Sprintf("(%s%s)", operator, strings.Join(filters, "")), nil
+}
+
+func (p *filterParser) parseNot() (string, error) {
+	if strings.EqualFold(p.peek(), "not") {
+		p.pos++
+		if p.peek() != "(" {
+			return "", filterErr("'not' must be followed by '('")
+		}
+		f, err := p.parseNot()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(!%s)", f), nil
+	}
+
+	if p.peek() == "(" {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if token, err := p.next(); err != nil || token != ")" {
+			return "", filterErr("missing ')' in filter")
+		}
+		return f, nil
+	}
+	return p.parseComparison()
+}
+
+// 解析单个比较条件：attrPath op value 或 attrPath pr
+func (p *filterParser) parseComparison() (string, error) {
+	path, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	attr, ok := p.attr(normalizePath(path))
+	if !ok {
+		return "", filterErr("attribute '%s' is not filterable", path)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	op = strings.ToLower(op)
+	if op == "pr" {
+		return fmt.Sprintf("(%s=*)", attr.ldap), nil
+	}
+
+	token, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	var value interface{}
+	if err = json.Unmarshal([]byte(token), &value); err != nil {
+		return "", filterErr("invalid value '%s' in filter", token)
+	}
+
+	switch attr.kind {
+	case kindActive:
+		return activeFilter(path, op, value)
+	case kindGUID:
+		return guidFilter(attr.ldap, path, op, value)
+	case kindTime:
+		return timeFilter(attr.ldap, path, op, value)
+	default:
+		s, ok := value.(string)
+		if !ok {
+			return "", filterErr("attribute '%s' must be compared with a string", path)
+		}
+		return stringFilter(attr.ldap, op, ldap.EscapeFilter(s))
+	}
+}
+
+// 字符串比较，value须已转义
+func stringFilter(attr, op, value string) (string, error) {
+	switch op {
+	case "eq":
+		return fmt.Sprintf("(%s=%s)", attr, value), nil
+	case "ne":
+		return fmt.Sprintf("(!(%s=%s))", attr, value), nil
+	case "co":
+		return fmt.Sprintf("(%s=*%s*)", attr, value), nil
+	case "sw":
+		return fmt.Sprintf("(%s=%s*)", attr, value), nil
+	case "ew":
+		return fmt.Sprintf("(%s=*%s)", attr, value), nil
+	case "ge":
+		return fmt.Sprintf("(%s>=%s)", attr, value), nil
+	case "le":
+		return fmt.Sprintf("(%s<=%s)", attr, value), nil
+	case "gt":
+		return fmt.Sprintf("(&(%s>=%s)(!(%s=%s)))", attr, value, attr, value), nil
+	case "lt":
+		return fmt.Sprintf("(&(%s<=%s)(!(%s=%s)))", attr, value, attr, value), nil
+	}
+	return "", filterErr("unsupported operator '%s'", op)
+}
+
+// 用户是否启用由userAccountControl中的ACCOUNTDISABLE标志位决定
+func activeFilter(path, op string, value interface{}) (string, error) {
+	active, ok := value.(bool)
+	if !ok || (op != "eq" && op != "ne") {
+		return "", filterErr("attribute '%s' only supports eq/ne with a boolean", path)
+	}
+	if op == "ne" {
+		active = !active
+	}
+	disabled := "(userAccountControl:1.2.840.113556.1.4.803:=2)"
+	if active {
+		return fmt.Sprintf("(!%s)", disabled), nil
+	}
+	return disabled, nil
+}
+
+func guidFilter(attr, path, op string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok || (op != "eq" && op != "ne") {
+		return "", filterErr("attribute '%s' only supports eq/ne with a string", path)
+	}
+	escaped, err := coreldap.GUIDFilterValue(s)
+	if err != nil {
+		// 不合法的id不会匹配任何对象
+		escaped = ""
+	}
+	if escaped == "" {
+		if op == "eq" {
+			return "(!(objectClass=*))", nil
+		}
+		return "(objectClass=*)", nil
+	}
+	return stringFilter(attr, op, escaped)
+}
+
+// 时间比较，值为RFC3339格式，转换为GeneralizedTime后比较
+func timeFilter(attr, path, op string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", filterErr("attribute '%s' must be compared with a dateTime string", path)
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return "", filterErr("invalid dateTime '%s'", s)
+	}
+	switch op {
+	case "eq", "ne", "gt", "ge", "lt", "le":
+		return stringFilter(attr, op, t.UTC().Format("20060102150405.0Z"))
+	}
+	return "", filterErr("unsupported operator '%s' for attribute '%s'", op, path)
+}
@@ -0,0 +1,86 @@
+package scim
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompileFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{name: "empty", filter: "  ", want: ""},
+		{name: "eq", filter: `userName eq "alice"`, want: "(sAMAccountName=alice)"},
+		{name: "schema prefix and case", filter: `urn:ietf:params:scim:schemas:core:2.0:User:DisplayName SW "Al"`, want: "(displayName=Al*)"},
+		{name: "escaped value", filter: `displayName co "a*(b)"`, want: `(displayName=*a\2a\28b\29*)`},
+		{name: "quoted string with escape", filter: `displayName eq "say \"hi\""`, want: `(displayName=say "hi")`},
+		{name: "present", filter: "emails pr", want: "(mail=*)"},
+		{name: "ne", filter: `department ne "IT"`, want: "(!(department=IT))"},
+		{name: "gt", filter: `name.familyName gt "m"`, want: "(&(sn>=m)(!(sn=m)))"},
+		{name: "active", filter: "active eq true", want: "(!(userAccountControl:1.2.840.113556.1.4.803:=2))"},
+		{name: "inactive", filter: "active ne true", want: "(userAccountControl:1.2.840.113556.1.4.803:=2)"},
+		{name: "invalid id matches nothing", filter: `id eq "not-a-guid"`, want: "(!(objectClass=*))"},
+		{name: "time", filter: `meta.lastModified ge "2024-01-02T03:04:05+08:00"`, want: "(whenChanged>=20240101190405.0Z)"},
+		{
+			name:   "and binds tighter than or",
+			filter: `userName eq "a" or userName eq "b" and active eq false`,
+			want:   "(|(sAMAccountName=a)(&(sAMAccountName=b)(userAccountControl:1.2.840.113556.1.4.803:=2)))",
+		},
+		{
+			name:   "parentheses and not",
+			filter: `not (userName eq "a" or userName eq "b") and emails pr`,
+			want:   "(&(!(|(sAMAccountName=a)(sAMAccountName=b)))(mail=*))",
+		},
+		{name: "chained and", filter: `department eq "a" AND department eq "b" and department eq "c"`, want: "(&(department=a)(department=b)(department=c))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compileFilter(tt.filter, userFilterAttr)
+			if err != nil {
+				t.Fatalf("compileFilter(%q) error: %v", tt.filter, err)
+			}
+			if got != tt.want {
+				t.Errorf("compileFilter(%q) = %q, want %q", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileFilterErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		attr   func(path string) (filterAttr, bool)
+	}{
+		{name: "value path", filter: `emails[type eq "work"]`, attr: userFilterAttr},
+		{name: "unterminated string", filter: `userName eq "alice`, attr: userFilterAttr},
+		{name: "unknown attribute", filter: `title eq "x"`, attr: userFilterAttr},
+		{name: "user attribute on group", filter: `userName eq "x"`, attr: groupFilterAttr},
+		{name: "missing value", filter: "userName eq", attr: userFilterAttr},
+		{name: "unsupported operator", filter: `userName like "x"`, attr: userFilterAttr},
+		{name: "number for string", filter: "userName eq 1", attr: userFilterAttr},
+		{name: "invalid value", filter: "userName eq alice", attr: userFilterAttr},
+		{name: "missing parenthesis", filter: `(userName eq "a"`, attr: userFilterAttr},
+		{name: "not without parenthesis", filter: `not userName eq "a"`, attr: userFilterAttr},
+		{name: "trailing token", filter: `userName eq "a" "b"`, attr: userFilterAttr},
+		{name: "active with string", filter: `active eq "true"`, attr: userFilterAttr},
+		{name: "active with co", filter: "active co true", attr: userFilterAttr},
+		{name: "id with sw", filter: `id sw "a"`, attr: groupFilterAttr},
+		{name: "invalid time", filter: `meta.created gt "yesterday"`, attr: groupFilterAttr},
+		{name: "time with co", filter: `meta.created co "2024-01-02T03:04:05Z"`, attr: groupFilterAttr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileFilter(tt.filter, tt.attr)
+			var scimErr *Error
+			if !errors.As(err, &scimErr) {
+				t.Fatalf("compileFilter(%q) error = %v, want *Error", tt.filter, err)
+			}
+			if scimErr.Status != 400 || scimErr.ScimType != "invalidFilter" {
+				t.Errorf("compileFilter(%q) = %d %s, want 400 invalidFilter", tt.filter, scimErr.Status, scimErr.ScimType)
+			}
+		})
+	}
+}
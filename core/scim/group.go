@@ -0,0 +1,334 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"ldap-http-service/config"
+	coreldap "ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/utils"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 群组资源需要从LDAP获取的属性，成员单独获取
+var groupAttributes = []string{"objectGUID", "sAMAccountName", "displayName", "whenCreated", "whenChanged", "uSNChanged"}
+
+// 变更成员时需要额外获取的属性，所有者及共同管理者用于owner模式的权限校验
+var groupMemberAttributes = []string{"member", "managedBy", "msExchCoManagedByLink"}
+
+// 通过SCIM创建的群组类型：全局安全组
+const defaultGroupType = -2147483646
+
+// sAMAccountName中不允许出现的字符
+const invalidNameChars = "\"/\\[]:;|=,+*?<>@"
+
+// 按objectGUID获取群组，withMembers为false时不获取成员
+func getGroup(tractx context.Context, id string, withMembers bool) (coreldap.Group, error) {
+	if _, err := coreldap.GUIDFilterValue(id); err != nil {
+		return coreldap.Group{}, &ers.NotFoundError{Object: fmt.Sprintf("id='%s'", id)}
+	}
+	attributes := groupAttributes
+	if withMembers {
+		attributes = append(append([]string{}, groupAttributes...), groupMemberAttributes...)
+	}
+	return coreldap.GetGroup(tractx, id, "objectGUID", "", attributes...)
+}
+
+// 将LDAP群组转换为SCIM群组资源，群组未设置displayName时使用sAMAccountName
+func toGroup(group *coreldap.Group, refs map[string]reference) *Group {
+	g := &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          group.ObjectGUID,
+		DisplayName: group.DisplayName,
+		Meta: &Meta{
+			ResourceType: ResourceGroup,
+			Created:      formatTime(time.Time(group.WhenCreated)),
+			LastModified: formatTime(time.Time(group.WhenChanged)),
+			Location:     location("Groups", group.ObjectGUID),
+			Version:      "W/" + group.ETag(),
+		},
+	}
+	if g.DisplayName == "" {
+		g.DisplayName = group.SAMAccountName
+	}
+	for _, dn := range group.Member {
+		if ref, ok := refs[strings.ToLower(dn)]; ok {
+			g.Members = append(g.Members, ref.multiValue())
+		}
+	}
+	return g
+}
+
+// 批量转换群组，excluded中不包含members时重新获取每个群组的成员并一次性解析
+func toGroups(tractx context.Context, groups []*coreldap.Group, excluded []string) ([]*Group, error) {
+	refs := map[string]reference{}
+	if !utils.InSliceIC(excluded, "members") {
+		var dns []string
+		for _, group := range groups {
+			if group.Member == nil {
+				withMembers, err := coreldap.GetGroup(tractx, group.DistinguishedName, "distinguishedName", "", "member")
+				if err != nil {
+					return nil, err
+				}
+				group.Member = withMembers.Member
+			}
+			dns = append(dns, group.Member...)
+		}
+
+		var err error
+		if refs, err = resolveDNs(tractx, dns); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]*Group, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, toGroup(group, refs))
+	}
+	return result, nil
+}
+
+// 按id读取群组并转换为SCIM群组资源
+func readGroup(tractx context.Context, id string, excluded []string) (*Group, error) {
+	group, err := getGroup(tractx, id, !utils.InSliceIC(excluded, "members"))
+	if err != nil {
+		return nil, err
+	}
+	groups, err := toGroups(tractx, []*coreldap.Group{&group}, excluded)
+	if err != nil {
+		return nil, err
+	}
+	return groups[0], nil
+}
+
+// 按过滤条件查询群组，成员只对当前页的群组获取
+func listGroups(tractx context.Context, filter string, startIndex, count int, excluded []string) (*ListResponse, error) {
+	ldapFilter, err := compileFilter(filter, groupFilterAttr)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*coreldap.Group
+	err = coreldap.SearchGroups(tractx, ldapFilter, "", groupAttributes, func(group *coreldap.Group) error {
+		groups = append(groups, group)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ObjectGUID < groups[j].ObjectGUID })
+
+	start, end := pageRange(len(groups), startIndex, count)
+	page, err := toGroups(tractx, groups[start:end], excluded)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]interface{}, 0, len(page))
+	for _, group := range page {
+		resources = append(resources, group)
+	}
+	return newListResponse(len(groups), start+1, resources), nil
+}
+
+// 将成员id解析为DN，strict为true时不存在的id返回错误，否则忽略
+func memberDNs(tractx context.Context, ids []string, strict bool) ([]string, error) {
+	refs, err := resolveIDs(tractx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var dns []string
+	for _, id := range ids {
+		ref, ok := refs[strings.ToLower(id)]
+		if !ok {
+			if strict {
+				return nil, invalidValue("member '%s' not found", id)
+			}
+			continue
+		}
+		dns = append(dns, ref.dn)
+	}
+	return dns, nil
+}
+
+// groupUpdate 待写入群组的变更，members为nil代表不修改成员，否则为变更后的完整成员DN列表
+type groupUpdate struct {
+	displayName *string
+	members     []string
+}
+
+// 根据完整的SCIM群组资源生成变更
+func groupUpdateFromResource(tractx context.Context, g *Group) (*groupUpdate, error) {
+	if g.DisplayName == "" {
+		return nil, invalidValue("displayName is required")
+	}
+	ids := make([]string, 0, len(g.Members))
+	for _, member := range g.Members {
+		ids = append(ids, member.Value)
+	}
+	members, err := memberDNs(tractx, ids, true)
+	if err != nil {
+		return nil, err
+	}
+	if members == nil {
+		members = []string{}
+	}
+	return &groupUpdate{displayName: &g.DisplayName, members: members}, nil
+}
+
+// 根据PATCH操作生成变更，成员操作按顺序作用于当前成员列表
+func groupUpdateFromPatch(tractx context.Context, group *coreldap.Group, patch *PatchOp) (*groupUpdate, error) {
+	changes, err := expandOperations(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &groupUpdate{}
+	members := append([]string{}, group.Member...)
+	for _, change := range changes {
+		switch path := change.path; {
+		case path == "displayname":
+			name, err := stringValue(path, change.value)
+			if err != nil {
+				return nil, err
+			}
+			if change.op == "remove" || name == "" {
+				return nil, &Error{Status: http.StatusBadRequest, ScimType: "mutability", Detail: "displayName cannot be removed"}
+			}
+			update.displayName = &name
+		case path == "externalid":
+			// externalId不在目录中保存，忽略对它的修改
+		case path == "members":
+			ids, err := referenceValues(path, change.value)
+			if err != nil {
+				return nil, err
+			}
+			dns, err := memberDNs(tractx, ids, change.op != "remove")
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case change.op == "replace":
+				members = append([]string{}, dns...)
+			case change.op == "add":
+				members = mergeDNs(members, dns)
+			case change.value == nil:
+				members = []string{}
+			default:
+				members = excludeDNs(members, dns)
+			}
+		case memberPathRegexp.MatchString(change.path) && change.op == "remove":
+			dns, err := memberDNs(tractx, []string{memberPathRegexp.FindStringSubmatch(change.path)[1]}, false)
+			if err != nil {
+				return nil, err
+			}
+			members = excludeDNs(members, dns)
+		default:
+			return nil, invalidPath(change.path)
+		}
+	}
+
+	if len(excludeDNs(members, group.Member)) > 0 || len(excludeDNs(group.Member, members)) > 0 {
+		update.members = members
+	}
+	return update, nil
+}
+
+// 合并DN列表，忽略大小写去重
+func mergeDNs(dns, added []string) []string {
+	result := make([]string, 0, len(dns)+len(added))
+	for _, dn := range append(append([]string{}, dns...), added...) {
+		if !utils.InSliceIC(result, dn) {
+			result = append(result, dn)
+		}
+	}
+	return result
+}
+
+// 从DN列表中去除指定的DN，忽略大小写
+func excludeDNs(dns, removed []string) []string {
+	result := make([]string, 0, len(dns))
+	for _, dn := range dns {
+		if !utils.InSliceIC(removed, dn) {
+			result = append(result, dn)
+		}
+	}
+	return result
+}
+
+// 将变更写入群组；version仅用于第一次写入，之后的写入不再校验版本
+func applyGroupUpdate(tractx context.Context, group *coreldap.Group, version string, update *groupUpdate) error {
+	if version != "" && version != group.USNChanged {
+		return &ers.PreconditionFailedErr{Object: group.DistinguishedName, Version: version}
+	}
+
+	// 成员变更受owner模式限制，在写入任何变更前校验
+	var added, removed []string
+	if update.members != nil {
+		added, removed = excludeDNs(update.members, group.Member), excludeDNs(group.Member, update.members)
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		if err := coreldap.AuthorizeMemberChange(tractx, group); err != nil {
+			return err
+		}
+	}
+
+	if update.displayName != nil && *update.displayName != group.DisplayName {
+		changes := []coreldap.AttrChange{{Op: coreldap.OpReplace, Attribute: "displayName", Values: []string{*update.displayName}}}
+		if err := coreldap.ApplyChanges(tractx, group.DistinguishedName, version, changes); err != nil {
+			return err
+		}
+		version = ""
+	}
+	if len(added) > 0 {
+		if err := coreldap.AddGroupMembers(tractx, group.DistinguishedName, "distinguishedName", version, added...); err != nil {
+			return err
+		}
+		version = ""
+	}
+	if len(removed) > 0 {
+		if err := coreldap.RemoveGroupMembers(tractx, group.DistinguishedName, "distinguishedName", version, removed...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 创建群组，displayName同时作为群组的sAMAccountName
+func createGroup(tractx context.Context, g *Group) (*Group, error) {
+	if config.ScimConfig.GroupOU == "" {
+		return nil, &ers.SystemErr{Message: "SCIM_GROUPOU is not configured"}
+	}
+	update, err := groupUpdateFromResource(tractx, g)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(g.DisplayName, invalidNameChars) {
+		return nil, invalidValue("displayName '%s' contains characters not allowed in sAMAccountName", g.DisplayName)
+	}
+
+	// 新建的群组没有所有者，owner模式下不能在创建时添加成员，需要在创建前校验，避免群组已创建而成员添加失败
+	dn := fmt.Sprintf("CN=%s,%s", g.DisplayName, config.ScimConfig.GroupOU)
+	if len(update.members) > 0 {
+		if err = coreldap.AuthorizeMemberChange(tractx, &coreldap.Group{BaseObject: coreldap.BaseObject{DistinguishedName: dn}}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = coreldap.CreateGroup(tractx, g.DisplayName, config.ScimConfig.GroupOU, g.DisplayName, "", defaultGroupType); err != nil {
+		return nil, err
+	}
+
+	group, err := coreldap.GetGroup(tractx, dn, "distinguishedName", "", append(append([]string{}, groupAttributes...), groupMemberAttributes...)...)
+	if err != nil {
+		return nil, err
+	}
+	if err = applyGroupUpdate(tractx, &group, "", update); err != nil {
+		return nil, err
+	}
+	return readGroup(tractx, group.ObjectGUID, nil)
+}
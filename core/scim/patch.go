@@ -0,0 +1,185 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// PatchOp PATCH请求体
+type PatchOp struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation 单个PATCH操作，Op不区分大小写，Path为空时Value为包含多个属性的对象
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// 以单个属性为单位的变更，Value为nil时代表移除该属性
+type pathChange struct {
+	op    string
+	path  string
+	value interface{}
+}
+
+// 成员移除时使用的值路径，如 members[value eq "2819c223-7f76-453a-919d-413861904646"]
+var memberPathRegexp = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+"([^"]*)"\s*]$`)
+
+func invalidValue(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: fmt.Sprintf(format, args...)}
+}
+
+func invalidPath(path string) *Error {
+	return &Error{Status: http.StatusBadRequest, ScimType: "invalidPath", Detail: fmt.Sprintf("unsupported path '%s'", path)}
+}
+
+// 将PATCH操作展开为以单个属性为单位的变更，未指定path时按值中的属性展开，复合属性 (name、企业扩展) 展开为子属性
+func expandOperations(patch *PatchOp) ([]pathChange, error) {
+	if len(patch.Operations) == 0 {
+		return nil, invalidValue("no operations in patch request")
+	}
+
+	var changes []pathChange
+	for _, operation := range patch.Operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "remove" && op != "replace" {
+			return nil, invalidValue("unsupported patch op '%s'", operation.Op)
+		}
+
+		var value interface{}
+		if len(operation.Value) > 0 {
+			if err := json.Unmarshal(operation.Value, &value); err != nil {
+				return nil, invalidValue("invalid value for path '%s'", operation.Path)
+			}
+		}
+
+		if operation.Path == "" {
+			if op == "remove" {
+				return nil, &Error{Status: http.StatusBadRequest, ScimType: "noTarget", Detail: "path is required for remove operations"}
+			}
+			attrs, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, invalidValue("value must be an object when path is not specified")
+			}
+			changes = append(changes, expandValue(op, "", attrs)...)
+			continue
+		}
+
+		path := normalizePath(operation.Path)
+		if attrs, ok := value.(map[string]interface{}); ok && path == "name" {
+			changes = append(changes, expandValue(op, path+".", attrs)...)
+			continue
+		}
+		changes = append(changes, pathChange{op: op, path: path, value: value})
+	}
+	return changes, nil
+}
+
+// 展开对象中的属性，企业扩展的URN键下的属性同样展开
+func expandValue(op, prefix string, attrs map[string]interface{}) []pathChange {
+	var changes []pathChange
+	for key, value := range attrs {
+		path := prefix + normalizePath(key)
+		if nested, ok := value.(map[string]interface{}); ok && (path == "name" || strings.EqualFold(key, SchemaEnterpriseUser)) {
+			nestedPrefix := path + "."
+			if strings.EqualFold(key, SchemaEnterpriseUser) {
+				nestedPrefix = ""
+			}
+			changes = append(changes, expandValue(op, nestedPrefix, nested)...)
+			continue
+		}
+		changes = append(changes, pathChange{op: op, path: path, value: value})
+	}
+	return changes
+}
+
+// 将变更值转换为单个字符串，nil或空字符串代表清空
+func stringValue(path string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case map[string]interface{}:
+		// 允许以 {"value": "..."} 的形式传入，如manager
+		return stringValue(path, v["value"])
+	}
+	return "", invalidValue("attribute '%s' requires a string value", path)
+}
+
+// 从PATCH操作的emails值中取得主邮箱地址
+func primaryEmail(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []interface{}:
+		var emails []MultiValue
+		raw, _ := json.Marshal(v)
+		if err := json.Unmarshal(raw, &emails); err != nil {
+			return "", invalidValue("invalid value for emails")
+		}
+		return primaryMail(emails), nil
+	}
+	return "", invalidValue("invalid value for emails")
+}
+
+// 取得主邮箱地址，未标记primary时取第一个
+func primaryMail(emails []MultiValue) string {
+	for _, email := range emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(emails) > 0 {
+		return emails[0].Value
+	}
+	return ""
+}
+
+// 解析多值引用属性的值 (如members)，返回被引用资源的id
+func referenceValues(path string, value interface{}) ([]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if single, ok := value.(map[string]interface{}); ok {
+		value = []interface{}{single}
+	}
+	var refs []MultiValue
+	raw, _ := json.Marshal(value)
+	if err := json.Unmarshal(raw, &refs); err != nil {
+		return nil, invalidValue("invalid value for %s", path)
+	}
+
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Value == "" {
+			return nil, invalidValue("value is required for every %s", path)
+		}
+		ids = append(ids, ref.Value)
+	}
+	return ids, nil
+}
+
+// 将布尔值或字符串格式的布尔值转换为bool，部分身份提供方会以 "True"/"False" 传递active
+func boolValue(path string, value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, invalidValue("attribute '%s' requires a boolean value", path)
+}
@@ -0,0 +1,104 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ldap/ldap"
+	coreldap "ldap-http-service/core/ldap"
+	"strings"
+)
+
+// 单次批量解析时合并到同一个LDAP过滤条件中的对象数量
+const resolveChunkSize = 100
+
+// reference 被引用的用户或群组，用于members、groups、manager等属性在DN与SCIM id之间的转换
+type reference struct {
+	dn           string
+	id           string
+	display      string
+	resourceType string
+}
+
+// 转换为多值属性中的值
+func (r reference) multiValue() MultiValue {
+	endpoint := "Users"
+	if r.resourceType == ResourceGroup {
+		endpoint = "Groups"
+	}
+	return MultiValue{Value: r.id, Display: r.display, Type: r.resourceType, Ref: location(endpoint, r.id)}
+}
+
+// 按过滤条件批量搜索用户和群组，其他类型的对象 (如联系人) 不会被返回
+func lookupReferences(tractx context.Context, filters []string) ([]reference, error) {
+	var refs []reference
+	attributes := []string{"objectGUID", "sAMAccountName", "displayName"}
+	for start := 0; start < len(filters); start += resolveChunkSize {
+		end := start + resolveChunkSize
+		if end > len(filters) {
+			end = len(filters)
+		}
+		filter := fmt.Sprintf("(|%s)", strings.Join(filters[start:end], ""))
+
+		err := coreldap.SearchUsers(tractx, filter, "", attributes, func(user *coreldap.User) error {
+			refs = append(refs, newReference(&user.BaseObject, ResourceUser))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = coreldap.SearchGroups(tractx, filter, "", attributes, func(group *coreldap.Group) error {
+			refs = append(refs, newReference(&group.BaseObject, ResourceGroup))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
+
+func newReference(obj *coreldap.BaseObject, resourceType string) reference {
+	display := obj.DisplayName
+	if display == "" {
+		display = obj.SAMAccountName
+	}
+	return reference{dn: obj.DistinguishedName, id: obj.ObjectGUID, display: display, resourceType: resourceType}
+}
+
+// 批量将DN解析为引用，返回以小写DN为键的map，无法解析的DN不包含在结果中
+func resolveDNs(tractx context.Context, dns []string) (map[string]reference, error) {
+	filters := make([]string, 0, len(dns))
+	for _, dn := range dns {
+		filters = append(filters, fmt.Sprintf("(distinguishedName=%s)", ldap.EscapeFilter(dn)))
+	}
+
+	refs, err := lookupReferences(tractx, filters)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]reference, len(refs))
+	for _, ref := range refs {
+		result[strings.ToLower(ref.dn)] = ref
+	}
+	return result, nil
+}
+
+// 批量将SCIM id解析为引用，返回以小写id为键的map，不合法或不存在的id不包含在结果中
+func resolveIDs(tractx context.Context, ids []string) (map[string]reference, error) {
+	filters := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if value, err := coreldap.GUIDFilterValue(id); err == nil {
+			filters = append(filters, fmt.Sprintf("(objectGUID=%s)", value))
+		}
+	}
+
+	refs, err := lookupReferences(tractx, filters)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]reference, len(refs))
+	for _, ref := range refs {
+		result[strings.ToLower(ref.id)] = ref
+	}
+	return result, nil
+}
@@ -0,0 +1,371 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"ldap-http-service/config"
+	"net/http"
+	"strings"
+)
+
+// SCIM 2.0 中使用的schema URN
+const (
+	SchemaUser            = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup           = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaEnterpriseUser  = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SchemaListResponse    = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp         = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError           = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProvider = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType    = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaSchema          = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+)
+
+// 资源类型名称
+const (
+	ResourceUser  = "User"
+	ResourceGroup = "Group"
+)
+
+// Meta 资源的元数据
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+	Version      string `json:"version,omitempty"`
+}
+
+// MultiValue 多值属性中的单个值，如emails、groups、members
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// Name 用户姓名
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+}
+
+// Manager 企业扩展中的直属上级，Value为上级用户的id
+type Manager struct {
+	Value       string `json:"value,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// EnterpriseUser 企业用户扩展，Organization对应AD中的company属性
+type EnterpriseUser struct {
+	Organization string   `json:"organization,omitempty"`
+	Department   string   `json:"department,omitempty"`
+	Manager      *Manager `json:"manager,omitempty"`
+}
+
+// User SCIM用户资源，id为AD用户的objectGUID
+type User struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        *Name           `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Emails      []MultiValue    `json:"emails,omitempty"`
+	Active      *bool           `json:"active,omitempty"`
+	Password    string          `json:"password,omitempty"`
+	Groups      []MultiValue    `json:"groups,omitempty"`
+	Enterprise  *EnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta        *Meta           `json:"meta,omitempty"`
+}
+
+// Group SCIM群组资源，id为AD群组的objectGUID
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// ListResponse 查询结果，StartIndex从1开始
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+func newListResponse(total, startIndex int, resources []interface{}) *ListResponse {
+	if resources == nil {
+		resources = []interface{}{}
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+// NewListResponse 返回不分页的查询结果，用于资源类型、schema等发现接口
+func NewListResponse(resources []interface{}) *ListResponse {
+	return newListResponse(len(resources), 1, resources)
+}
+
+// ErrorResponse SCIM错误响应体，Status为字符串格式的http状态码
+type ErrorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+type supported struct {
+	Supported bool `json:"supported"`
+}
+
+type filterSupported struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type bulkSupported struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+// ServiceProviderConfig 服务能力声明
+type ServiceProviderConfig struct {
+	Schemas               []string          `json:"schemas"`
+	DocumentationURI      string            `json:"documentationUri,omitempty"`
+	Patch                 supported         `json:"patch"`
+	Bulk                  bulkSupported     `json:"bulk"`
+	Filter                filterSupported   `json:"filter"`
+	ChangePassword        supported         `json:"changePassword"`
+	Sort                  supported         `json:"sort"`
+	ETag                  supported         `json:"etag"`
+	AuthenticationSchemes []json.RawMessage `json:"authenticationSchemes"`
+	Meta                  Meta              `json:"meta"`
+}
+
+// GetServiceProviderConfig 返回服务能力声明，认证由部署环境在网关层完成，因此不声明认证方式
+func GetServiceProviderConfig() *ServiceProviderConfig {
+	return &ServiceProviderConfig{
+		Schemas:               []string{SchemaServiceProvider},
+		Patch:                 supported{Supported: true},
+		Bulk:                  bulkSupported{Supported: false},
+		Filter:                filterSupported{Supported: true, MaxResults: config.ScimConfig.MaxResults},
+		ChangePassword:        supported{Supported: true},
+		Sort:                  supported{Supported: false},
+		ETag:                  supported{Supported: true},
+		AuthenticationSchemes: []json.RawMessage{},
+		Meta:                  Meta{ResourceType: "ServiceProviderConfig", Location: location("ServiceProviderConfig", "")},
+	}
+}
+
+// ResourceType 资源类型声明
+type ResourceType struct {
+	Schemas          []string          `json:"schemas"`
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	Endpoint         string            `json:"endpoint"`
+	Schema           string            `json:"schema"`
+	SchemaExtensions []schemaExtension `json:"schemaExtensions,omitempty"`
+	Meta             Meta              `json:"meta"`
+}
+
+type schemaExtension struct {
+	Schema   string `json:"schema"`
+	Required bool   `json:"required"`
+}
+
+// GetResourceTypes 返回支持的资源类型
+func GetResourceTypes() []interface{} {
+	return []interface{}{
+		&ResourceType{
+			Schemas:          []string{SchemaResourceType},
+			ID:               ResourceUser,
+			Name:             ResourceUser,
+			Endpoint:         "/Users",
+			Schema:           SchemaUser,
+			SchemaExtensions: []schemaExtension{{Schema: SchemaEnterpriseUser, Required: false}},
+			Meta:             Meta{ResourceType: "ResourceType", Location: location("ResourceTypes", ResourceUser)},
+		},
+		&ResourceType{
+			Schemas:  []string{SchemaResourceType},
+			ID:       ResourceGroup,
+			Name:     ResourceGroup,
+			Endpoint: "/Groups",
+			Schema:   SchemaGroup,
+			Meta:     Meta{ResourceType: "ResourceType", Location: location("ResourceTypes", ResourceGroup)},
+		},
+	}
+}
+
+// Attribute schema中的属性定义
+type Attribute struct {
+	Name          string      `json:"name"`
+	Type          string      `json:"type"`
+	MultiValued   bool        `json:"multiValued"`
+	Description   string      `json:"description,omitempty"`
+	Required      bool        `json:"required"`
+	CaseExact     bool        `json:"caseExact"`
+	Mutability    string      `json:"mutability"`
+	Returned      string      `json:"returned"`
+	Uniqueness    string      `json:"uniqueness"`
+	SubAttributes []Attribute `json:"subAttributes,omitempty"`
+}
+
+// Schema 资源的schema定义，只包含本服务实际支持的属性
+type Schema struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Attributes  []Attribute `json:"attributes"`
+	Meta        Meta        `json:"meta"`
+}
+
+// 生成字符串类型的属性定义
+func stringAttr(name, description, mutability string, required bool) Attribute {
+	return Attribute{
+		Name:        name,
+		Type:        "string",
+		Description: description,
+		Required:    required,
+		Mutability:  mutability,
+		Returned:    "default",
+		Uniqueness:  "none",
+	}
+}
+
+// 生成引用其他资源的多值属性定义
+func referenceAttr(name, description, mutability string) Attribute {
+	return Attribute{
+		Name:        name,
+		Type:        "complex",
+		MultiValued: true,
+		Description: description,
+		Mutability:  mutability,
+		Returned:    "default",
+		Uniqueness:  "none",
+		SubAttributes: []Attribute{
+			stringAttr("value", "id of the referenced resource", mutability, false),
+			stringAttr("display", "display name of the referenced resource", "readOnly", false),
+			{Name: "$ref", Type: "reference", Mutability: mutability, Returned: "default", Uniqueness: "none"},
+			stringAttr("type", "type of the referenced resource", mutability, false),
+		},
+	}
+}
+
+// GetSchemas 返回支持的schema定义
+func GetSchemas() []interface{} {
+	userName := stringAttr("userName", "maps to the AD attribute configured by SCIM_USERNAMEATTR", "readWrite", true)
+	userName.Uniqueness = "server"
+	password := stringAttr("password", "write only, maps to unicodePwd", "writeOnly", false)
+	password.Returned = "never"
+
+	return []interface{}{
+		&Schema{
+			Schemas:     []string{SchemaSchema},
+			ID:          SchemaUser,
+			Name:        ResourceUser,
+			Description: "User Account",
+			Attributes: []Attribute{
+				userName,
+				{
+					Name:       "name",
+					Type:       "complex",
+					Mutability: "readWrite",
+					Returned:   "default",
+					Uniqueness: "none",
+					SubAttributes: []Attribute{
+						stringAttr("formatted", "read only, built from givenName and sn", "readOnly", false),
+						stringAttr("familyName", "maps to sn", "readWrite", false),
+						stringAttr("givenName", "maps to givenName", "readWrite", false),
+					},
+				},
+				stringAttr("displayName", "maps to displayName", "readWrite", false),
+				{
+					Name:        "emails",
+					Type:        "complex",
+					MultiValued: true,
+					Description: "only the primary address is stored, maps to mail",
+					Mutability:  "readWrite",
+					Returned:    "default",
+					Uniqueness:  "none",
+					SubAttributes: []Attribute{
+						stringAttr("value", "email address", "readWrite", false),
+						stringAttr("type", "always work", "readWrite", false),
+						{Name: "primary", Type: "boolean", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+					},
+				},
+				{Name: "active", Type: "boolean", Description: "maps to the ACCOUNTDISABLE flag of userAccountControl", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+				password,
+				referenceAttr("groups", "groups the user is a direct member of, maps to memberOf", "readOnly"),
+			},
+			Meta: Meta{ResourceType: "Schema", Location: location("Schemas", SchemaUser)},
+		},
+		&Schema{
+			Schemas:     []string{SchemaSchema},
+			ID:          SchemaEnterpriseUser,
+			Name:        "EnterpriseUser",
+			Description: "Enterprise User",
+			Attributes: []Attribute{
+				stringAttr("organization", "maps to company", "readWrite", false),
+				stringAttr("department", "maps to department", "readWrite", false),
+				{
+					Name:       "manager",
+					Type:       "complex",
+					Mutability: "readWrite",
+					Returned:   "default",
+					Uniqueness: "none",
+					SubAttributes: []Attribute{
+						stringAttr("value", "id of the manager, maps to manager", "readWrite", false),
+						{Name: "$ref", Type: "reference", Mutability: "readOnly", Returned: "default", Uniqueness: "none"},
+						stringAttr("displayName", "display name of the manager", "readOnly", false),
+					},
+				},
+			},
+			Meta: Meta{ResourceType: "Schema", Location: location("Schemas", SchemaEnterpriseUser)},
+		},
+		&Schema{
+			Schemas:     []string{SchemaSchema},
+			ID:          SchemaGroup,
+			Name:        ResourceGroup,
+			Description: "Group",
+			Attributes: []Attribute{
+				stringAttr("displayName", "maps to displayName, also used as sAMAccountName on creation", "readWrite", true),
+				referenceAttr("members", "direct members of the group, maps to member", "readWrite"),
+			},
+			Meta: Meta{ResourceType: "Schema", Location: location("Schemas", SchemaGroup)},
+		},
+	}
+}
+
+// GetSchema 按schema URN获取schema定义
+func GetSchema(id string) (*Schema, error) {
+	for _, schema := range GetSchemas() {
+		if s := schema.(*Schema); s.ID == id {
+			return s, nil
+		}
+	}
+	return nil, &Error{Status: http.StatusNotFound, Detail: fmt.Sprintf("schema '%s' not found", id)}
+}
+
+// 生成资源的访问地址
+func location(endpoint, id string) string {
+	url := strings.TrimSuffix(config.ScimConfig.BaseURL, "/") + "/" + endpoint
+	if id != "" {
+		url += "/" + id
+	}
+	return url
+}
@@ -0,0 +1,444 @@
+package scim
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"ldap-http-service/config"
+	coreldap "ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/utils"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 用户资源需要从LDAP获取的属性
+var userAttributes = []string{
+	"objectGUID", "sAMAccountName", "userPrincipalName", "displayName", "givenName", "sn", "mail",
+	"userAccountControl", "company", "department", "manager", "memberOf", "whenCreated", "whenChanged", "uSNChanged",
+}
+
+// userAccountControl中的ACCOUNTDISABLE标志位
+const accountDisable = 0x0002
+
+// 未指定密码时自动生成的密码所使用的字符集及长度
+const (
+	passwordChars  = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789!@#$%^&*-_=+"
+	passwordLength = 24
+)
+
+// userName对应的LDAP属性，可通过SCIM_USERNAMEATTR配置为userPrincipalName
+func userNameAttr() string {
+	if strings.EqualFold(config.ScimConfig.UserNameAttr, "userPrincipalName") {
+		return "userPrincipalName"
+	}
+	return "sAMAccountName"
+}
+
+// userUpdate 待写入用户的变更，attrs中值为空字符串代表清空该属性
+type userUpdate struct {
+	attrs    map[string]string
+	active   *bool
+	password string
+}
+
+func newUserUpdate() *userUpdate {
+	return &userUpdate{attrs: make(map[string]string)}
+}
+
+// 按objectGUID获取用户，不合法的id视为不存在
+func getUser(tractx context.Context, id string) (coreldap.User, error) {
+	if _, err := coreldap.GUIDFilterValue(id); err != nil {
+		return coreldap.User{}, &ers.NotFoundError{Object: fmt.Sprintf("id='%s'", id)}
+	}
+	return coreldap.GetUser(tractx, id, "objectGUID", "", userAttributes...)
+}
+
+// 用户未设置ACCOUNTDISABLE标志位时视为启用
+func isActive(userAccountControl string) bool {
+	uac, _ := strconv.Atoi(userAccountControl)
+	return uac&accountDisable == 0
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// 将LDAP用户转换为SCIM用户资源，refs为groups、manager中DN对应的引用
+func toUser(user *coreldap.User, refs map[string]reference) *User {
+	u := &User{
+		Schemas:     []string{SchemaUser},
+		ID:          user.ObjectGUID,
+		UserName:    user.SAMAccountName,
+		DisplayName: user.DisplayName,
+		Meta: &Meta{
+			ResourceType: ResourceUser,
+			Created:      formatTime(time.Time(user.WhenCreated)),
+			LastModified: formatTime(time.Time(user.WhenChanged)),
+			Location:     location("Users", user.ObjectGUID),
+			Version:      "W/" + user.ETag(),
+		},
+	}
+	if userNameAttr() == "userPrincipalName" {
+		u.UserName = user.UserPrincipalName
+	}
+	if user.GivenName != "" || user.Sn != "" {
+		u.Name = &Name{
+			Formatted:  strings.TrimSpace(user.GivenName + " " + user.Sn),
+			FamilyName: user.Sn,
+			GivenName:  user.GivenName,
+		}
+	}
+	if user.Mail != "" {
+		u.Emails = []MultiValue{{Value: user.Mail, Type: "work", Primary: true}}
+	}
+	active := isActive(user.UserAccountControl)
+	u.Active = &active
+
+	for _, dn := range user.MemberOf {
+		if ref, ok := refs[strings.ToLower(dn)]; ok {
+			group := ref.multiValue()
+			group.Type = "direct"
+			u.Groups = append(u.Groups, group)
+		}
+	}
+
+	if user.Company != "" || user.Department != "" || user.Manager != "" {
+		u.Schemas = append(u.Schemas, SchemaEnterpriseUser)
+		u.Enterprise = &EnterpriseUser{Organization: user.Company, Department: user.Department}
+		if ref, ok := refs[strings.ToLower(user.Manager)]; ok {
+			u.Enterprise.Manager = &Manager{Value: ref.id, Ref: location("Users", ref.id), DisplayName: ref.display}
+		}
+	}
+	return u
+}
+
+// 批量转换用户，一次性解析所有用户的groups与manager引用，excluded中包含groups时不解析群组
+func toUsers(tractx context.Context, users []*coreldap.User, excluded []string) ([]*User, error) {
+	var dns []string
+	for _, user := range users {
+		if !utils.InSliceIC(excluded, "groups") {
+			dns = append(dns, user.MemberOf...)
+		}
+		if user.Manager != "" {
+			dns = append(dns, user.Manager)
+		}
+	}
+	refs, err := resolveDNs(tractx, dns)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*User, 0, len(users))
+	for _, user := range users {
+		result = append(result, toUser(user, refs))
+	}
+	return result, nil
+}
+
+// 按id读取用户并转换为SCIM用户资源
+func readUser(tractx context.Context, id string, excluded []string) (*User, error) {
+	user, err := getUser(tractx, id)
+	if err != nil {
+		return nil, err
+	}
+	users, err := toUsers(tractx, []*coreldap.User{&user}, excluded)
+	if err != nil {
+		return nil, err
+	}
+	return users[0], nil
+}
+
+// 按过滤条件查询用户，结果按objectGUID排序以保证分页稳定
+func listUsers(tractx context.Context, filter string, startIndex, count int, excluded []string) (*ListResponse, error) {
+	ldapFilter, err := compileFilter(filter, userFilterAttr)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*coreldap.User
+	err = coreldap.SearchUsers(tractx, ldapFilter, "", userAttributes, func(user *coreldap.User) error {
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ObjectGUID < users[j].ObjectGUID })
+
+	start, end := pageRange(len(users), startIndex, count)
+	page, err := toUsers(tractx, users[start:end], excluded)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]interface{}, 0, len(page))
+	for _, user := range page {
+		resources = append(resources, user)
+	}
+	return newListResponse(len(users), start+1, resources), nil
+}
+
+// 计算分页在结果中的范围，startIndex从1开始，count小于0代表未指定，最大不超过配置的单页数量
+func pageRange(total, startIndex, count int) (int, int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 || count > config.ScimConfig.MaxResults {
+		count = config.ScimConfig.MaxResults
+	}
+
+	start := startIndex - 1
+	if start > total {
+		start = total
+	}
+	end := start + count
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// 生成满足密码复杂度要求的随机密码
+func generatePassword(username string) (string, error) {
+	password := make([]byte, passwordLength)
+	for {
+		for i := range password {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordChars))))
+			if err != nil {
+				return "", err
+			}
+			password[i] = passwordChars[n.Int64()]
+		}
+		if utils.IsStrongPassword(username, string(password)) {
+			return string(password), nil
+		}
+	}
+}
+
+// 将manager的id解析为DN，空字符串代表清空
+func managerDN(tractx context.Context, id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+	manager, err := getUser(tractx, id)
+	if err != nil {
+		return "", invalidValue("manager '%s' not found", id)
+	}
+	return manager.DistinguishedName, nil
+}
+
+// 根据完整的SCIM用户资源生成变更，未提供的可写属性将被清空
+func userUpdateFromResource(tractx context.Context, u *User) (*userUpdate, error) {
+	if u.UserName == "" {
+		return nil, invalidValue("userName is required")
+	}
+
+	update := newUserUpdate()
+	update.attrs[userNameAttr()] = u.UserName
+	update.attrs["displayName"] = u.DisplayName
+	update.attrs["givenName"] = ""
+	update.attrs["sn"] = ""
+	if u.Name != nil {
+		update.attrs["givenName"] = u.Name.GivenName
+		update.attrs["sn"] = u.Name.FamilyName
+	}
+
+	update.attrs["mail"] = primaryMail(u.Emails)
+
+	var err error
+	update.attrs["company"] = ""
+	update.attrs["department"] = ""
+	update.attrs["manager"] = ""
+	if u.Enterprise != nil {
+		update.attrs["company"] = u.Enterprise.Organization
+		update.attrs["department"] = u.Enterprise.Department
+		if u.Enterprise.Manager != nil {
+			if update.attrs["manager"], err = managerDN(tractx, u.Enterprise.Manager.Value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	update.active = u.Active
+	update.password = u.Password
+	return update, nil
+}
+
+// 根据PATCH操作生成变更，只包含被操作的属性
+func userUpdateFromPatch(tractx context.Context, patch *PatchOp) (*userUpdate, error) {
+	changes, err := expandOperations(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	update := newUserUpdate()
+	for _, change := range changes {
+		value := change.value
+		if change.op == "remove" {
+			value = nil
+		}
+
+		switch path := change.path; {
+		case path == "username":
+			if value == nil {
+				return nil, &Error{Status: http.StatusBadRequest, ScimType: "mutability", Detail: "userName cannot be removed"}
+			}
+			if update.attrs[userNameAttr()], err = stringValue(path, value); err != nil {
+				return nil, err
+			}
+		case path == "displayname", path == "name.givenname", path == "name.familyname", path == "department", path == "organization":
+			attr := map[string]string{
+				"displayname":     "displayName",
+				"name.givenname":  "givenName",
+				"name.familyname": "sn",
+				"department":      "department",
+				"organization":    "company",
+			}[path]
+			if update.attrs[attr], err = stringValue(path, value); err != nil {
+				return nil, err
+			}
+		case path == "name":
+			if value != nil {
+				return nil, invalidValue("name requires an object value")
+			}
+			update.attrs["givenName"] = ""
+			update.attrs["sn"] = ""
+		case path == "name.formatted", path == "externalid":
+			// formatted由givenName与sn生成，externalId不在目录中保存，忽略对它们的修改
+		case strings.HasPrefix(path, "emails"):
+			if update.attrs["mail"], err = primaryEmail(value); err != nil {
+				return nil, err
+			}
+		case path == "manager", path == "manager.value":
+			id, err := stringValue(path, value)
+			if err != nil {
+				return nil, err
+			}
+			if update.attrs["manager"], err = managerDN(tractx, id); err != nil {
+				return nil, err
+			}
+		case path == "active":
+			if value == nil {
+				return nil, &Error{Status: http.StatusBadRequest, ScimType: "mutability", Detail: "active cannot be removed"}
+			}
+			active, err := boolValue(path, value)
+			if err != nil {
+				return nil, err
+			}
+			update.active = &active
+		case path == "password":
+			if update.password, err = stringValue(path, value); err != nil {
+				return nil, err
+			}
+		case path == "groups":
+			return nil, &Error{Status: http.StatusBadRequest, ScimType: "mutability", Detail: "groups is read only, update the group members instead"}
+		default:
+			return nil, invalidPath(change.path)
+		}
+	}
+	return update, nil
+}
+
+// 将变更写入用户，只写入与当前值不同的属性；version仅用于第一次写入，之后的写入不再校验版本
+func applyUserUpdate(tractx context.Context, user *coreldap.User, version string, update *userUpdate) error {
+	if version != "" && version != user.USNChanged {
+		return &ers.PreconditionFailedErr{Object: user.DistinguishedName, Version: version}
+	}
+
+	current := coreldap.ObjectValues(user, userAttributes)
+	attrs := make([]string, 0, len(update.attrs))
+	for attr := range update.attrs {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+
+	var changes []coreldap.AttrChange
+	for _, attr := range attrs {
+		value, old := update.attrs[attr], ""
+		if len(current[attr]) > 0 {
+			old = current[attr][0]
+		}
+		if value == old {
+			continue
+		}
+		change := coreldap.AttrChange{Op: coreldap.OpReplace, Attribute: attr}
+		if value != "" {
+			change.Values = []string{value}
+		}
+		changes = append(changes, change)
+	}
+
+	if update.active != nil && *update.active != isActive(user.UserAccountControl) {
+		uac, _ := strconv.Atoi(user.UserAccountControl)
+		if *update.active {
+			uac &^= accountDisable
+		} else {
+			uac |= accountDisable
+		}
+		changes = append(changes, coreldap.AttrChange{Op: coreldap.OpReplace, Attribute: "userAccountControl", Values: []string{strconv.Itoa(uac)}})
+	}
+
+	if len(changes) > 0 {
+		if err := coreldap.ApplyChanges(tractx, user.DistinguishedName, version, changes); err != nil {
+			return err
+		}
+		version = ""
+	}
+	if update.password != "" {
+		return coreldap.SetUserPwd(tractx, user.DistinguishedName, "distinguishedName", update.password, "", version)
+	}
+	return nil
+}
+
+// 创建用户，userName包含@时@之后的部分作为用户的主域
+func createUser(tractx context.Context, u *User) (*User, error) {
+	if config.ScimConfig.UserOU == "" {
+		return nil, &ers.SystemErr{Message: "SCIM_USEROU is not configured"}
+	}
+	update, err := userUpdateFromResource(tractx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	sAMAccountName, domain := u.UserName, config.LdapConfig.Domain
+	if i := strings.LastIndex(u.UserName, "@"); i >= 0 {
+		sAMAccountName, domain = u.UserName[:i], u.UserName[i+1:]
+	}
+	displayName := u.DisplayName
+	if displayName == "" {
+		displayName = sAMAccountName
+	}
+	password := update.password
+	if password == "" {
+		if password, err = generatePassword(sAMAccountName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = coreldap.CreateEnabledUser(tractx, sAMAccountName, displayName, config.ScimConfig.UserOU, password, domain); err != nil {
+		return nil, err
+	}
+
+	dn := fmt.Sprintf("CN=%s,%s", sAMAccountName, config.ScimConfig.UserOU)
+	user, err := coreldap.GetUser(tractx, dn, "distinguishedName", "", userAttributes...)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建时已设置的属性不再重复写入
+	delete(update.attrs, userNameAttr())
+	update.attrs["displayName"] = displayName
+	update.password = ""
+	if err = applyUserUpdate(tractx, &user, "", update); err != nil {
+		return nil, err
+	}
+	return readUser(tractx, user.ObjectGUID, nil)
+}
@@ -0,0 +1,217 @@
+package transfer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/utils"
+	"strings"
+)
+
+// 支持的导入导出格式
+const (
+	FormatCSV   = "csv"
+	FormatLDIF  = "ldif"
+	FormatJSONL = "jsonl"
+)
+
+// 支持的对象类型
+const (
+	TypeUser     = "user"
+	TypeGroup    = "group"
+	TypeComputer = "computer"
+)
+
+// CSV中多值属性的分隔符
+const csvValueSep = ";"
+
+// ContentType 返回导出格式对应的Content-Type
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatLDIF:
+		return "text/x-ldif; charset=utf-8"
+	default:
+		return "application/x-ndjson; charset=utf-8"
+	}
+}
+
+// CheckExport 在开始输出前校验导出参数，避免输出过程中才发现参数错误
+func CheckExport(format, objType string) error {
+	if !utils.InSlice(format, []string{FormatCSV, FormatLDIF, FormatJSONL}) {
+		return &ers.UnSupportedErr{Object: format, ObjectType: "export format"}
+	}
+	if !utils.InSlice(objType, []string{TypeUser, TypeGroup, TypeComputer}) {
+		return &ers.UnSupportedErr{Object: objType, ObjectType: "object type"}
+	}
+	return nil
+}
+
+// objectWriter 将LDAP对象按指定格式写入输出流
+type objectWriter interface {
+	write(obj ldap.SpecObject) error
+	flush() error
+}
+
+// Export 将匹配过滤条件的用户、群组或计算机以指定格式流式写入w
+func Export(tractx context.Context, w io.Writer, format, objType, filter, searchBase string, attributes []string) error {
+	if err := CheckExport(format, objType); err != nil {
+		return err
+	}
+
+	var template ldap.SpecObject = &ldap.User{}
+	switch objType {
+	case TypeGroup:
+		template = &ldap.Group{}
+	case TypeComputer:
+		template = &ldap.Computer{}
+	}
+
+	var writer objectWriter
+	switch format {
+	case FormatCSV:
+		writer = newCSVWriter(w, ldap.AttrNames(template, attributes), attributes)
+	case FormatLDIF:
+		writer = &ldifWriter{w: w, names: ldap.AttrNames(template, attributes), attributes: attributes}
+	default:
+		writer = &jsonlWriter{encoder: json.NewEncoder(w), attributes: attributes}
+	}
+
+	var err error
+	switch objType {
+	case TypeGroup:
+		err = ldap.SearchGroups(tractx, filter, searchBase, attributes, func(group *ldap.Group) error {
+			return writer.write(group)
+		})
+	case TypeComputer:
+		err = ldap.SearchComputers(tractx, filter, searchBase, attributes, func(computer *ldap.Computer) error {
+			return writer.write(computer)
+		})
+	default:
+		err = ldap.SearchUsers(tractx, filter, searchBase, attributes, func(user *ldap.User) error {
+			return writer.write(user)
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return writer.flush()
+}
+
+type jsonlWriter struct {
+	encoder    *json.Encoder
+	attributes []string
+}
+
+func (w *jsonlWriter) write(obj ldap.SpecObject) error {
+	return w.encoder.Encode(ldap.ProjectObject(obj, w.attributes))
+}
+
+func (w *jsonlWriter) flush() error {
+	return nil
+}
+
+// csvWriter 首行为属性名，多值属性以分号连接
+type csvWriter struct {
+	writer      *csv.Writer
+	names       []string
+	attributes  []string
+	wroteHeader bool
+}
+
+func newCSVWriter(w io.Writer, names, attributes []string) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w), names: names, attributes: attributes}
+}
+
+func (w *csvWriter) write(obj ldap.SpecObject) error {
+	if !w.wroteHeader {
+		if err := w.writer.Write(w.names); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+
+	values := ldap.ObjectValues(obj, w.attributes)
+	row := make([]string, len(w.names))
+	for i, name := range w.names {
+		row[i] = strings.Join(values[name], csvValueSep)
+	}
+	return w.writer.Write(row)
+}
+
+func (w *csvWriter) flush() error {
+	if !w.wroteHeader {
+		if err := w.writer.Write(w.names); err != nil {
+			return err
+		}
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// ldifWriter 按RFC 2849输出内容记录
+type ldifWriter struct {
+	w           io.Writer
+	names       []string
+	attributes  []string
+	wroteHeader bool
+}
+
+func (w *ldifWriter) write(obj ldap.SpecObject) error {
+	if !w.wroteHeader {
+		if _, err := io.WriteString(w.w, "version: 1\n\n"); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+
+	var b strings.Builder
+	b.WriteString(ldifLine("dn", obj.ReturnBaseObj().DistinguishedName))
+	values := ldap.ObjectValues(obj, w.attributes)
+	for _, name := range w.names {
+		if name == "distinguishedName" {
+			continue
+		}
+		for _, value := range values[name] {
+			b.WriteString(ldifLine(name, value))
+		}
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w.w, b.String())
+	return err
+}
+
+func (w *ldifWriter) flush() error {
+	return nil
+}
+
+// ldifLine 输出单个属性值，非安全字符串使用base64编码
+func ldifLine(name, value string) string {
+	if ldifSafe(value) {
+		return fmt.Sprintf("%s: %s\n", name, value)
+	}
+	return fmt.Sprintf("%s:: %s\n", name, base64.StdEncoding.EncodeToString([]byte(value)))
+}
+
+// ldifSafe 判断值是否符合RFC 2849中SAFE-STRING的定义
+func ldifSafe(value string) bool {
+	if value == "" {
+		return true
+	}
+	if strings.ContainsAny(value[:1], " :<") || strings.HasSuffix(value, " ") {
+		return false
+	}
+	for _, r := range value {
+		if r == 0 || r == '\n' || r == '\r' || r > 127 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,351 @@
+package transfer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"ldap-http-service/config"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/logger"
+	"ldap-http-service/lib/utils"
+	"strconv"
+	"strings"
+)
+
+// 导入结果的状态
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusPlanned   = "planned"
+)
+
+// CSV导入模式
+const (
+	ModeCreate = "create"
+	ModeUpdate = "update"
+)
+
+// 默认创建的群组类型：全局安全组
+const defaultGroupType = -2147483646
+
+// 由目录服务维护，不允许通过导入写入的属性
+var readOnlyAttrs = []string{
+	"distinguishedName", "name", "whenCreated", "whenChanged", "objectClass", "objectCategory", "objectGUID", "objectSid",
+	"uSNChanged", "memberOf", "pwdLastSet", "lockoutTime", "lastLogon", "msDS-UserPasswordExpiryTimeComputed",
+}
+
+// 创建对象时由创建流程处理的列，而非直接写入的属性
+var createFields = map[string][]string{
+	TypeUser:  {"sAMAccountName", "displayName", "OU", "password", "primaryDomain"},
+	TypeGroup: {"sAMAccountName", "displayName", "OU", "description", "groupType"},
+}
+
+// LineResult 单行（CSV）或单条记录（LDIF）的导入结果
+type LineResult struct {
+	Line   int    `json:"line"`
+	Action string `json:"action,omitempty"`
+	Target string `json:"target,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report 导入报告
+type Report struct {
+	DryRun    bool         `json:"dry_run"`
+	Total     int          `json:"total"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Results   []LineResult `json:"results"`
+}
+
+func (r *Report) add(result LineResult) {
+	r.Total++
+	if result.Status == StatusFailed {
+		r.Failed++
+	} else {
+		r.Succeeded++
+	}
+	r.Results = append(r.Results, result)
+}
+
+// CSVOptions CSV导入参数
+type CSVOptions struct {
+	ObjType string
+	Mode    string
+	// update模式下用于查找对象的属性
+	Key string
+	// 列名到属性名的映射，为空时直接使用列名作为属性名
+	Mapping map[string]string
+	DryRun  bool
+}
+
+// ParseMapping 解析 "列名:属性名,列名:属性名" 格式的列映射
+func ParseMapping(mapping string) (map[string]string, error) {
+	if mapping == "" {
+		return nil, nil
+	}
+	result := make(map[string]string)
+	for _, pair := range strings.Split(mapping, ",") {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, &ers.InvalidFormatErr{Name: "mapping", Object: pair}
+		}
+		result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return result, nil
+}
+
+// ImportCSV 从CSV中批量创建或更新用户、群组，每一行单独报告结果
+func ImportCSV(tractx context.Context, r io.Reader, opts CSVOptions) (*Report, error) {
+	if !utils.InSlice(opts.ObjType, []string{TypeUser, TypeGroup}) {
+		return nil, &ers.UnSupportedErr{Object: opts.ObjType, ObjectType: "object type"}
+	}
+	if !utils.InSlice(opts.Mode, []string{ModeCreate, ModeUpdate}) {
+		return nil, &ers.UnSupportedErr{Object: opts.Mode, ObjectType: "import mode"}
+	}
+	if opts.Key == "" {
+		opts.Key = "sAMAccountName"
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, &ers.InvalidFormatErr{Name: "csv header", Object: err.Error()}
+	}
+
+	// 将每一列对应到属性，未映射的列忽略
+	columns := make([]string, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if opts.Mapping == nil {
+			columns[i] = name
+		} else {
+			columns[i] = opts.Mapping[name]
+		}
+	}
+	if err = checkColumns(columns, opts); err != nil {
+		return nil, err
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("开始导入CSV，对象类型: %s，模式: %s，试运行: %v", opts.ObjType, opts.Mode, opts.DryRun)
+	report := &Report{DryRun: opts.DryRun}
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		// 单行格式错误时记录失败并继续处理后续行
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.add(LineResult{Line: parseErr.Line, Status: StatusFailed, Error: parseErr.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		values := make(map[string]string)
+		for i, attr := range columns {
+			if attr != "" && strings.TrimSpace(row[i]) != "" {
+				values[attr] = strings.TrimSpace(row[i])
+			}
+		}
+		report.add(importRow(tractx, line, values, opts))
+	}
+	return report, nil
+}
+
+// checkColumns 校验列映射的属性是否可写，以及当前模式所需的列是否存在
+func checkColumns(columns []string, opts CSVOptions) error {
+	var template ldap.SpecObject = &ldap.User{}
+	if opts.ObjType == TypeGroup {
+		template = &ldap.Group{}
+	}
+	known := ldap.AttrNames(template, nil)
+
+	for _, attr := range columns {
+		if attr == "" || utils.InSlice(attr, createFields[opts.ObjType]) || (opts.Mode == ModeUpdate && attr == opts.Key) {
+			continue
+		}
+		if !utils.InSliceIC(known, attr) || utils.InSliceIC(readOnlyAttrs, attr) {
+			return &ers.UnSupportedErr{Object: attr, ObjectType: "import attribute"}
+		}
+	}
+
+	required := []string{opts.Key}
+	if opts.Mode == ModeCreate {
+		required = []string{"sAMAccountName", "OU"}
+		if opts.ObjType == TypeUser {
+			required = append(required, "password")
+		}
+	}
+	for _, attr := range required {
+		if !utils.InSlice(attr, columns) {
+			return &ers.InvalidFormatErr{Name: "csv columns", Object: fmt.Sprintf("missing column for '%s'", attr)}
+		}
+	}
+	return nil
+}
+
+// importRow 处理单行数据：create模式先创建对象再写入其他属性，update模式查找对象后替换属性，空值的列不做修改
+func importRow(tractx context.Context, line int, values map[string]string, opts CSVOptions) LineResult {
+	result := LineResult{Line: line, Action: opts.Mode, Status: StatusSucceeded}
+	fail := func(err error) LineResult {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	var changes []ldap.AttrChange
+	for attr, value := range values {
+		if utils.InSlice(attr, createFields[opts.ObjType]) || (opts.Mode == ModeUpdate && attr == opts.Key) {
+			continue
+		}
+		changes = append(changes, ldap.AttrChange{Op: ldap.OpReplace, Attribute: attr, Values: strings.Split(value, csvValueSep)})
+	}
+
+	if opts.Mode == ModeUpdate {
+		result.Target = values[opts.Key]
+		if result.Target == "" {
+			return fail(fmt.Errorf("empty value of key '%s'", opts.Key))
+		}
+
+		var (
+			dn  string
+			err error
+		)
+		if opts.ObjType == TypeGroup {
+			var group ldap.Group
+			group, err = ldap.GetGroup(tractx, result.Target, opts.Key, "", "distinguishedName")
+			dn = group.DistinguishedName
+		} else {
+			var user ldap.User
+			user, err = ldap.GetUser(tractx, result.Target, opts.Key, "", "distinguishedName")
+			dn = user.DistinguishedName
+		}
+		if err != nil {
+			return fail(err)
+		}
+		if len(changes) == 0 {
+			return fail(errors.New("no attribute to update"))
+		}
+
+		result.Target = dn
+		if opts.DryRun {
+			result.Status = StatusPlanned
+			return result
+		}
+		if err = ldap.ApplyChanges(tractx, dn, "", changes); err != nil {
+			return fail(err)
+		}
+		return result
+	}
+
+	// create模式
+	name, ou := values["sAMAccountName"], values["OU"]
+	if name == "" || ou == "" {
+		return fail(errors.New("sAMAccountName and OU are required"))
+	}
+	dn := fmt.Sprintf("CN=%s,%s", name, ou)
+	result.Target = dn
+
+	groupType := defaultGroupType
+	if value, ok := values["groupType"]; ok {
+		var err error
+		if groupType, err = strconv.Atoi(value); err != nil {
+			return fail(&ers.InvalidFormatErr{Name: "groupType", Object: value})
+		}
+	}
+
+	if opts.DryRun {
+		ok, _, err := ldap.CheckAvailability(tractx, name)
+		if err != nil {
+			return fail(err)
+		}
+		if !ok {
+			return fail(&ers.ObjExistError{Object: fmt.Sprintf("sAMAccountName='%s'", name)})
+		}
+		result.Status = StatusPlanned
+		return result
+	}
+
+	var err error
+	if opts.ObjType == TypeGroup {
+		err = ldap.CreateGroup(tractx, name, ou, values["displayName"], values["description"], groupType)
+	} else {
+		primaryDomain := values["primaryDomain"]
+		if primaryDomain == "" {
+			primaryDomain = config.LdapConfig.Domain
+		}
+		err = ldap.CreateEnabledUser(tractx, name, values["displayName"], ou, values["password"], primaryDomain)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if len(changes) > 0 {
+		if err = ldap.ApplyChanges(tractx, dn, "", changes); err != nil {
+			return fail(err)
+		}
+	}
+	return result
+}
+
+// ImportLDIF 按LDIF变更记录执行add/modify/moddn/delete，每条记录单独报告结果
+func ImportLDIF(tractx context.Context, r io.Reader, dryRun bool) (*Report, error) {
+	records, failures, err := parseLDIF(r)
+	if err != nil {
+		return nil, &ers.InvalidFormatErr{Name: "ldif", Object: err.Error()}
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("开始导入LDIF，共计 %d 条有效记录，试运行: %v", len(records), dryRun)
+	report := &Report{DryRun: dryRun}
+	for _, failure := range failures {
+		report.add(failure)
+	}
+	for _, record := range records {
+		report.add(importRecord(tractx, record, dryRun))
+	}
+	return report, nil
+}
+
+// importRecord 执行单条变更记录，试运行时仅校验目标对象是否存在
+func importRecord(tractx context.Context, record *changeRecord, dryRun bool) LineResult {
+	result := LineResult{Line: record.Line, Action: record.ChangeType, Target: record.DN, Status: StatusSucceeded}
+
+	var err error
+	if dryRun {
+		var exists bool
+		exists, err = ldap.ObjectExists(tractx, record.DN)
+		switch {
+		case err != nil:
+		case record.ChangeType == ChangeAdd && exists:
+			err = &ers.ObjExistError{Object: record.DN}
+		case record.ChangeType != ChangeAdd && !exists:
+			err = &ers.NotFoundError{Object: record.DN}
+		default:
+			result.Status = StatusPlanned
+		}
+	} else {
+		switch record.ChangeType {
+		case ChangeAdd:
+			err = ldap.AddObj(tractx, record.DN, record.Attrs)
+		case ChangeModify:
+			err = ldap.ApplyChanges(tractx, record.DN, "", record.Changes)
+		case ChangeModDN:
+			err = ldap.RenameObj(tractx, record.DN, record.NewRDN, record.DeleteOldRDN, record.NewSuperior)
+		case ChangeDelete:
+			err = ldap.DeleteObj(tractx, record.DN, "")
+		}
+	}
+
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,247 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"ldap-http-service/core/ldap"
+	"strings"
+)
+
+// LDIF变更记录的类型
+const (
+	ChangeAdd    = "add"
+	ChangeModify = "modify"
+	ChangeModDN  = "moddn"
+	ChangeDelete = "delete"
+)
+
+// changeRecord LDIF中的单条变更记录
+type changeRecord struct {
+	Line       int
+	DN         string
+	ChangeType string
+
+	// add
+	Attrs map[string][]string
+	// modify
+	Changes []ldap.AttrChange
+	// moddn
+	NewRDN       string
+	DeleteOldRDN bool
+	NewSuperior  string
+}
+
+// ldifLineValue LDIF中的一行 "属性: 值"
+type ldifLineValue struct {
+	line  int
+	name  string
+	value string
+	sep   bool  // 修改记录中的 "-" 分隔行
+	err   error // 该行无法解析时的错误，由所属记录报告
+}
+
+// parseLDIF 解析LDIF变更记录，无法解析的记录以失败结果返回，不影响其他记录
+func parseLDIF(r io.Reader) ([]*changeRecord, []LineResult, error) {
+	blocks, err := readLDIFBlocks(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		records  []*changeRecord
+		failures []LineResult
+	)
+	for _, block := range blocks {
+		record, err := parseChangeRecord(block)
+		if err != nil {
+			failures = append(failures, LineResult{Line: block[0].line, Status: StatusFailed, Error: err.Error()})
+			continue
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+	return records, failures, nil
+}
+
+// readLDIFBlocks 按空行拆分记录，处理注释以及以单个空格开头的续行
+func readLDIFBlocks(r io.Reader) ([][]ldifLineValue, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var (
+		blocks  [][]ldifLineValue
+		block   []ldifLineValue
+		lines   []string
+		starts  []int
+		lineNum int
+	)
+
+	// 续行已合并至所属的逻辑行，按逻辑行的起始行号解析
+	flushBlock := func() {
+		for i, raw := range lines {
+			value, err := parseLDIFLine(starts[i], raw)
+			if err != nil {
+				value = ldifLineValue{line: starts[i], err: err}
+			}
+			block = append(block, value)
+		}
+		if len(block) > 0 {
+			blocks = append(blocks, block)
+		}
+		block, lines, starts = nil, nil, nil
+	}
+
+	for scanner.Scan() {
+		lineNum++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case text == "":
+			flushBlock()
+		case strings.HasPrefix(text, "#"):
+		case strings.HasPrefix(text, " ") && len(lines) > 0:
+			lines[len(lines)-1] += text[1:]
+		case strings.HasPrefix(text, " "):
+			block = append(block, ldifLineValue{line: lineNum, err: fmt.Errorf("line %d: unexpected continuation line", lineNum)})
+		default:
+			lines = append(lines, text)
+			starts = append(starts, lineNum)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flushBlock()
+	return blocks, nil
+}
+
+// parseLDIFLine 解析单个逻辑行，支持 "name: value" 和base64编码的 "name:: value"
+func parseLDIFLine(line int, text string) (ldifLineValue, error) {
+	if text == "-" {
+		return ldifLineValue{line: line, sep: true}, nil
+	}
+
+	idx := strings.Index(text, ":")
+	if idx <= 0 {
+		return ldifLineValue{}, fmt.Errorf("line %d: missing ':' separator", line)
+	}
+	name, value := text[:idx], text[idx+1:]
+
+	switch {
+	case strings.HasPrefix(value, ":"):
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+		if err != nil {
+			return ldifLineValue{}, fmt.Errorf("line %d: invalid base64 value of '%s'", line, name)
+		}
+		value = string(decoded)
+	case strings.HasPrefix(value, "<"):
+		return ldifLineValue{}, fmt.Errorf("line %d: URL values are not supported", line)
+	default:
+		value = strings.TrimLeft(value, " ")
+	}
+	return ldifLineValue{line: line, name: name, value: value}, nil
+}
+
+// parseChangeRecord 解析单条记录，版本声明返回nil；未指定changetype的内容记录按add处理
+func parseChangeRecord(block []ldifLineValue) (*changeRecord, error) {
+	for _, v := range block {
+		if v.err != nil {
+			return nil, v.err
+		}
+	}
+	if len(block) == 1 && strings.EqualFold(block[0].name, "version") {
+		return nil, nil
+	}
+	if !strings.EqualFold(block[0].name, "dn") {
+		return nil, fmt.Errorf("record must start with dn")
+	}
+
+	record := &changeRecord{Line: block[0].line, DN: block[0].value, ChangeType: ChangeAdd}
+	body := block[1:]
+	if len(body) > 0 && strings.EqualFold(body[0].name, "changetype") {
+		record.ChangeType = strings.ToLower(body[0].value)
+		body = body[1:]
+	}
+	if !ldap.ValidDN(record.DN) {
+		return nil, fmt.Errorf("invalid dn '%s'", record.DN)
+	}
+
+	switch record.ChangeType {
+	case ChangeAdd:
+		record.Attrs = make(map[string][]string)
+		for _, v := range body {
+			if v.sep {
+				return nil, fmt.Errorf("line %d: unexpected '-' in add record", v.line)
+			}
+			record.Attrs[v.name] = append(record.Attrs[v.name], v.value)
+		}
+		if len(record.Attrs) == 0 {
+			return nil, fmt.Errorf("add record has no attributes")
+		}
+	case ChangeModify:
+		return record, parseModify(record, body)
+	case ChangeModDN, "modrdn":
+		record.ChangeType = ChangeModDN
+		for _, v := range body {
+			switch strings.ToLower(v.name) {
+			case "newrdn":
+				record.NewRDN = v.value
+			case "deleteoldrdn":
+				record.DeleteOldRDN = v.value == "1"
+			case "newsuperior":
+				record.NewSuperior = v.value
+			default:
+				return nil, fmt.Errorf("line %d: unexpected '%s' in moddn record", v.line, v.name)
+			}
+		}
+		if record.NewRDN == "" {
+			return nil, fmt.Errorf("moddn record requires newrdn")
+		}
+	case ChangeDelete:
+		if len(body) > 0 {
+			return nil, fmt.Errorf("line %d: delete record must not have attributes", body[0].line)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported changetype '%s'", record.ChangeType)
+	}
+	return record, nil
+}
+
+// parseModify 解析修改记录中以 "-" 分隔的add/delete/replace操作
+func parseModify(record *changeRecord, body []ldifLineValue) error {
+	var current *ldap.AttrChange
+	for _, v := range body {
+		if v.sep {
+			if current == nil {
+				return fmt.Errorf("line %d: unexpected '-'", v.line)
+			}
+			record.Changes = append(record.Changes, *current)
+			current = nil
+			continue
+		}
+
+		if current == nil {
+			op := map[string]string{"add": ldap.OpAdd, "delete": ldap.OpRemove, "replace": ldap.OpReplace}[strings.ToLower(v.name)]
+			if op == "" {
+				return fmt.Errorf("line %d: expected add/delete/replace, got '%s'", v.line, v.name)
+			}
+			current = &ldap.AttrChange{Op: op, Attribute: v.value}
+			continue
+		}
+
+		if !strings.EqualFold(v.name, current.Attribute) {
+			return fmt.Errorf("line %d: attribute '%s' does not match '%s'", v.line, v.name, current.Attribute)
+		}
+		current.Values = append(current.Values, v.value)
+	}
+
+	if current != nil {
+		record.Changes = append(record.Changes, *current)
+	}
+	if len(record.Changes) == 0 {
+		return fmt.Errorf("modify record has no changes")
+	}
+	return nil
+}
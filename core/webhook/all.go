@@ -0,0 +1,226 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"ldap-http-service/config"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/ers"
+	"ldap-http-service/lib/logger"
+	"ldap-http-service/lib/utils"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	hub     *dispatcher
+	hubOnce sync.Once
+)
+
+// Init 加载webhook订阅及未完成的投递，注册写入事件的处理函数，并在后台开始分发
+func Init(tractx context.Context) (err error) {
+	hubOnce.Do(func() {
+		logger.LdapLogger.WithContext(tractx).Info("正在初始化webhook分发器...")
+		hub = newDispatcher(config.WebhookConfig.Path, config.WebhookConfig.MaxAttempts, config.WebhookConfig.Workers, config.WebhookConfig.QueueSize, config.WebhookConfig.Timeout)
+		hub.deadRetention = config.WebhookConfig.DeadRetention
+		hub.allowLocal = config.WebhookConfig.AllowLocal
+		if err = hub.load(); err != nil {
+			return
+		}
+
+		ldap.AddWriteHook(hub.enqueue)
+		go hub.persist(context.Background())
+		go hub.run(context.Background())
+	})
+	return
+}
+
+// 校验webhook地址：只允许http(s)，主机需要在允许列表中，且不能是回环、链路本地等本机或内部地址；
+// 域名解析后的地址在建立连接时再次校验
+func checkURL(rawURL string, allowedHosts []string, allowLocal bool) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" || target.User != nil {
+		return &ers.InvalidFormatErr{Name: "webhook url", Object: rawURL}
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+	if len(allowedHosts) > 0 && !hostAllowed(host, allowedHosts) {
+		return &ers.ForbiddenErr{Message: fmt.Sprintf("webhook host '%s' is not in the allowed hosts", host)}
+	}
+	if allowLocal {
+		return nil
+	}
+	if ip := net.ParseIP(host); (ip != nil && blockedIP(ip)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return &ers.ForbiddenErr{Message: fmt.Sprintf("webhook host '%s' is a local address", host)}
+	}
+	return nil
+}
+
+// 主机是否匹配允许列表，"*.example.com" 匹配example.com的所有子域名
+func hostAllowed(host string, allowedHosts []string) bool {
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(host, strings.TrimPrefix(allowed, "*")) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateSubscription 创建webhook订阅，未指定密钥时自动生成；返回的订阅包含密钥，仅在创建时返回
+func CreateSubscription(tractx context.Context, subscription Subscription) (*Subscription, error) {
+	err := checkURL(subscription.URL, config.WebhookConfig.AllowedHosts, config.WebhookConfig.AllowLocal)
+	if err != nil {
+		return nil, err
+	}
+	for _, eventType := range subscription.EventTypes {
+		if eventType != "*" && !utils.InSlice(eventType, ldap.EventTypes) {
+			return nil, &ers.UnSupportedErr{Object: eventType, ObjectType: "event type"}
+		}
+	}
+	for _, ou := range subscription.OUs {
+		if !ldap.ValidDN(ou) {
+			return nil, &ers.InvalidFormatErr{Name: "OU", Object: ou}
+		}
+	}
+
+	if subscription.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			return nil, err
+		}
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+	subscription.ID = utils.GenUuid("whk")
+	subscription.CreatedAt = time.Now()
+
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	hub.subscriptions[subscription.ID] = &subscription
+	if err = hub.saveSubscriptions(); err != nil {
+		delete(hub.subscriptions, subscription.ID)
+		return nil, err
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("已创建webhook订阅 `%s` -> %s", subscription.ID, subscription.URL)
+	return &subscription, nil
+}
+
+// ListSubscriptions 获取所有webhook订阅，不返回密钥
+func ListSubscriptions() []Subscription {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	subscriptions := make([]Subscription, 0, len(hub.subscriptions))
+	for _, subscription := range hub.subscriptions {
+		masked := *subscription
+		masked.Secret = ""
+		subscriptions = append(subscriptions, masked)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions
+}
+
+// DeleteSubscription 删除webhook订阅，其尚未完成的投递将被丢弃
+func DeleteSubscription(tractx context.Context, id string) error {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	subscription, ok := hub.subscriptions[id]
+	if !ok {
+		return &ers.NotFoundError{Object: "webhook=" + id}
+	}
+	delete(hub.subscriptions, id)
+	if err := hub.saveSubscriptions(); err != nil {
+		hub.subscriptions[id] = subscription
+		return err
+	}
+
+	// 投递中的事件在投递结束后发现订阅已删除时自行移除
+	for deliveryId, delivery := range hub.deliveries {
+		if delivery.SubscriptionID == id && !hub.inflight[deliveryId] {
+			hub.removeDelivery(deliveryId)
+		}
+	}
+
+	logger.LdapLogger.WithContext(tractx).Infof("已删除webhook订阅 `%s`", id)
+	return nil
+}
+
+// ListDeadDeliveries 获取订阅中已超过最大尝试次数而不再重试的投递，按失败时间排序
+func ListDeadDeliveries(id string) ([]Delivery, error) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if _, ok := hub.subscriptions[id]; !ok {
+		return nil, &ers.NotFoundError{Object: "webhook=" + id}
+	}
+	deliveries := make([]Delivery, 0)
+	for _, delivery := range hub.deliveries {
+		if delivery.SubscriptionID == id && delivery.Dead {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].DiedAt.Before(deliveries[j].DiedAt)
+	})
+	return deliveries, nil
+}
+
+// 获取订阅中失败的投递，调用方需持有锁
+func deadDelivery(id, deliveryId string) (*Delivery, error) {
+	delivery, ok := hub.deliveries[deliveryId]
+	if !ok || delivery.SubscriptionID != id || !delivery.Dead {
+		return nil, &ers.NotFoundError{Object: "delivery=" + deliveryId}
+	}
+	return delivery, nil
+}
+
+// ReplayDelivery 重新投递失败的投递，尝试次数清零并立即投递
+func ReplayDelivery(tractx context.Context, id, deliveryId string) error {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	delivery, err := deadDelivery(id, deliveryId)
+	if err != nil {
+		return err
+	}
+	replay := *delivery
+	replay.Dead, replay.DiedAt, replay.Attempts, replay.NextAttempt = false, time.Time{}, 0, time.Now()
+	if err = writeFile(hub.deliveryFile(deliveryId), &replay); err != nil {
+		return err
+	}
+	*delivery = replay
+
+	select {
+	case hub.wake <- struct{}{}:
+	default:
+	}
+	logger.LdapLogger.WithContext(tractx).Infof("重新投递webhook投递 `%s`", deliveryId)
+	return nil
+}
+
+// DeleteDelivery 删除失败的投递
+func DeleteDelivery(tractx context.Context, id, deliveryId string) error {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if _, err := deadDelivery(id, deliveryId); err != nil {
+		return err
+	}
+	hub.removeDelivery(deliveryId)
+	logger.LdapLogger.WithContext(tractx).Infof("已删除webhook投递 `%s`", deliveryId)
+	return nil
+}
@@ -0,0 +1,405 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"ldap-http-service/core/ldap"
+	"ldap-http-service/lib/logger"
+	"ldap-http-service/lib/utils"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// 重试的退避时间，从初始值开始按2的指数增长，不超过上限
+const (
+	initialBackoff = 5 * time.Second
+	maxBackoff     = time.Hour
+)
+
+// 投递失败时最多读取的响应体长度，读取后连接可以被复用
+const maxDrainBytes = 64 << 10
+
+// Subscription webhook订阅，EventTypes和OUs为空时代表不过滤
+type Subscription struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	OUs        []string  `json:"ous"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// matches 判断订阅是否关注该事件，OU按DN后缀匹配，包含子OU中的对象
+func (s *Subscription) matches(event ldap.WriteEvent) bool {
+	if len(s.EventTypes) > 0 && !utils.InSlice(event.Type, s.EventTypes) && !utils.InSlice("*", s.EventTypes) {
+		return false
+	}
+	if len(s.OUs) == 0 {
+		return true
+	}
+	dn := strings.ToLower(event.DN)
+	for _, ou := range s.OUs {
+		if strings.HasSuffix(dn, ","+strings.ToLower(ou)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery 待投递的事件，投递成功后删除；超过最大尝试次数后标记为Dead，不再重试，超过保留时间后被清理
+type Delivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	Event          ldap.WriteEvent `json:"event"`
+	Attempts       int             `json:"attempts"`
+	NextAttempt    time.Time       `json:"next_attempt"`
+	LastError      string          `json:"last_error,omitempty"`
+	Dead           bool            `json:"dead,omitempty"`
+	DiedAt         time.Time       `json:"died_at,omitempty"`
+}
+
+// dispatcher webhook分发器，投递前先将事件持久化到本地队列，保证至少投递一次；
+// 写入事件先进入内存缓冲区，由后台协程持久化，避免磁盘写入拖慢目录写入请求
+type dispatcher struct {
+	path          string
+	maxAttempts   int
+	workers       int
+	client        *http.Client
+	mutex         sync.Mutex
+	subscriptions map[string]*Subscription
+	deliveries    map[string]*Delivery
+	inflight      map[string]bool
+	wake          chan struct{}
+	events        chan ldap.WriteEvent
+	// 失败的投递的保留时间，为0时不清理
+	deadRetention time.Duration
+	// 是否允许投递到回环、链路本地等本机或内部地址，仅用于测试
+	allowLocal bool
+}
+
+func newDispatcher(path string, maxAttempts, workers, queueSize int, timeout time.Duration) *dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	d := &dispatcher{
+		path:          path,
+		maxAttempts:   maxAttempts,
+		workers:       workers,
+		subscriptions: make(map[string]*Subscription),
+		deliveries:    make(map[string]*Delivery),
+		inflight:      make(map[string]bool),
+		wake:          make(chan struct{}, 1),
+		events:        make(chan ldap.WriteEvent, queueSize),
+	}
+
+	// 在建立连接时校验实际连接的地址，DNS解析结果变化或重定向时同样生效
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip != nil && !d.allowLocal && blockedIP(ip) {
+				return fmt.Errorf("webhook target address %s is not allowed", ip)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	d.client = &http.Client{Timeout: timeout, Transport: transport}
+	return d
+}
+
+// blockedIP 判断地址是否为不允许投递的本机或内部地址，包括回环、链路本地（包含云平台元数据地址）、未指定及组播地址
+func blockedIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified()
+}
+
+func (d *dispatcher) subscriptionsFile() string {
+	return filepath.Join(d.path, "subscriptions.json")
+}
+
+func (d *dispatcher) deliveryFile(id string) string {
+	return filepath.Join(d.path, "queue", id+".json")
+}
+
+// 从本地目录加载订阅以及未完成的投递
+func (d *dispatcher) load() error {
+	data, err := os.ReadFile(d.subscriptionsFile())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, &d.subscriptions); err != nil {
+			return fmt.Errorf("failed to load webhook subscriptions: %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(d.deliveryFile("*"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err = os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var delivery Delivery
+		if err = json.Unmarshal(data, &delivery); err != nil {
+			return fmt.Errorf("failed to load webhook delivery '%s': %v", file, err)
+		}
+		d.deliveries[delivery.ID] = &delivery
+	}
+	return nil
+}
+
+// 先写临时文件再重命名，避免写入中断导致文件损坏
+func writeFile(file string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	if err = os.WriteFile(file+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// 持久化所有订阅，调用方需持有锁
+func (d *dispatcher) saveSubscriptions() error {
+	return writeFile(d.subscriptionsFile(), d.subscriptions)
+}
+
+// enqueue 作为写入事件的处理函数，只将事件放入缓冲区，由persist持久化；缓冲区已满时丢弃事件并记录错误
+func (d *dispatcher) enqueue(event ldap.WriteEvent) {
+	select {
+	case d.events <- event:
+	default:
+		logger.LdapLogger.Errorf("webhook事件缓冲区已满，丢弃事件 `%s` (%s)", event.Type, event.DN)
+	}
+}
+
+// persist 从缓冲区读取事件，为每个匹配的订阅创建投递并持久化，随后唤醒分发循环，直到ctx被取消
+func (d *dispatcher) persist(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.events:
+			d.store(event)
+		}
+	}
+}
+
+// 为匹配的订阅创建投递，写文件时不持有锁
+func (d *dispatcher) store(event ldap.WriteEvent) {
+	d.mutex.Lock()
+	var matched []string
+	for _, subscription := range d.subscriptions {
+		if subscription.matches(event) {
+			matched = append(matched, subscription.ID)
+		}
+	}
+	d.mutex.Unlock()
+
+	for _, subscriptionId := range matched {
+		delivery := &Delivery{
+			ID:             utils.GenUuid("dlv"),
+			SubscriptionID: subscriptionId,
+			Event:          event,
+			NextAttempt:    time.Now(),
+		}
+		if err := writeFile(d.deliveryFile(delivery.ID), delivery); err != nil {
+			logger.LdapLogger.Errorf("webhook事件 `%s` 持久化失败: %v", event.Type, err)
+			continue
+		}
+		d.mutex.Lock()
+		d.deliveries[delivery.ID] = delivery
+		d.mutex.Unlock()
+	}
+
+	if len(matched) > 0 {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run 分发循环，每秒或有新事件时投递所有到期的事件，并清理超过保留时间的失败投递，直到ctx被取消
+func (d *dispatcher) run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	sem := make(chan struct{}, d.workers)
+	for {
+		for _, delivery := range d.due() {
+			sem <- struct{}{}
+			go func(delivery *Delivery) {
+				defer func() { <-sem }()
+				d.attempt(ctx, delivery)
+			}(delivery)
+		}
+		d.purgeDead()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// 获取到期且未在投递中的事件，并标记为投递中
+func (d *dispatcher) due() []*Delivery {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+	var due []*Delivery
+	for id, delivery := range d.deliveries {
+		if delivery.Dead || d.inflight[id] || delivery.NextAttempt.After(now) {
+			continue
+		}
+		d.inflight[id] = true
+		due = append(due, delivery)
+	}
+	return due
+}
+
+// 删除失败超过保留时间的投递
+func (d *dispatcher) purgeDead() {
+	if d.deadRetention <= 0 {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for id, delivery := range d.deliveries {
+		if delivery.Dead && time.Since(delivery.DiedAt) > d.deadRetention {
+			d.removeDelivery(id)
+		}
+	}
+}
+
+// 删除投递及其本地记录，调用方需持有锁
+func (d *dispatcher) removeDelivery(id string) {
+	delete(d.deliveries, id)
+	if err := os.Remove(d.deliveryFile(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.LdapLogger.Errorf("删除webhook投递记录 `%s` 失败: %v", id, err)
+	}
+}
+
+// 第attempts次投递失败后到下一次重试的等待时间
+func backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return initialBackoff
+	}
+	if attempts > 30 {
+		return maxBackoff
+	}
+	wait := initialBackoff << (attempts - 1)
+	if wait > maxBackoff || wait <= 0 {
+		return maxBackoff
+	}
+	return wait
+}
+
+// 执行一次投递，成功后删除本地记录，失败则按指数退避安排下一次重试
+func (d *dispatcher) attempt(ctx context.Context, delivery *Delivery) {
+	d.mutex.Lock()
+	subscription, ok := d.subscriptions[delivery.SubscriptionID]
+	d.mutex.Unlock()
+
+	var err error
+	if ok {
+		err = d.post(ctx, subscription, delivery)
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.inflight, delivery.ID)
+
+	// 投递成功，或订阅已被删除时，移除该投递
+	if err == nil {
+		d.removeDelivery(delivery.ID)
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Dead = true
+		delivery.DiedAt = time.Now()
+		logger.LdapLogger.Errorf("webhook投递 `%s` 已重试 %d 次仍然失败，不再重试: %v", delivery.ID, delivery.Attempts, err)
+	} else {
+		wait := backoff(delivery.Attempts)
+		delivery.NextAttempt = time.Now().Add(wait)
+		logger.LdapLogger.Warningf("webhook投递 `%s` 失败，将在 %v 后重试: %v", delivery.ID, wait, err)
+	}
+	if err = writeFile(d.deliveryFile(delivery.ID), delivery); err != nil {
+		logger.LdapLogger.Errorf("保存webhook投递记录 `%s` 失败: %v", delivery.ID, err)
+	}
+}
+
+// 发送事件，请求体使用订阅的密钥进行HMAC-SHA256签名，签名内容为 "时间戳.请求体"
+func (d *dispatcher) post(ctx context.Context, subscription *Subscription, delivery *Delivery) error {
+	body, err := json.Marshal(map[string]interface{}{"id": delivery.ID, "event": delivery.Event})
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", delivery.ID)
+	req.Header.Set("X-Webhook-Event", delivery.Event.Type)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+sign(subscription.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// 读取剩余的响应体后再关闭，使连接可以被复用
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d from '%s'", resp.StatusCode, subscription.URL)
+	}
+	return nil
+}
+
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// Cache 并发安全的内存缓存，条目在ttl后过期，数量超过上限时淘汰最久未使用的条目
+type Cache[V any] struct {
+	ttl        time.Duration
+	maxEntries int
+	mutex      sync.Mutex
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+// New 创建缓存，ttl或maxEntries不大于0时缓存不保存任何条目
+func New[V any](maxEntries int, ttl time.Duration) *Cache[V] {
+	return &Cache[V]{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get 获取未过期的条目，并将其标记为最近使用
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var zero V
+	element, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	e := element.Value.(*entry[V])
+	if time.Now().After(e.expiresAt) {
+		c.remove(element)
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return e.value, true
+}
+
+// Set 写入条目，已存在的条目被覆盖并重新计算过期时间
+func (c *Cache[V]) Set(key string, value V) {
+	if c.ttl <= 0 || c.maxEntries <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&entry[V]{key: key, value: value, expiresAt: time.Now().Add(c.ttl)})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete 删除条目
+func (c *Cache[V]) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// DeleteFunc 删除所有满足条件的条目
+func (c *Cache[V]) DeleteFunc(match func(key string, value V) bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key, element := range c.entries {
+		if match(key, element.Value.(*entry[V]).value) {
+			c.remove(element)
+		}
+	}
+}
+
+// Purge 清空所有条目
+func (c *Cache[V]) Purge() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+}
+
+// 移除条目，调用方需持有锁
+func (c *Cache[V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry[V]).key)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"ldap-http-service/constants"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -95,6 +96,117 @@ func (e *ObjExistError) Code() int {
 	return constants.CodeObjAlreadyExists
 }
 
+// PreconditionFailedErr 对象已被修改，与请求中If-Match指定的版本不一致
+type PreconditionFailedErr struct {
+	BaseErr
+	Object  string
+	Version string
+}
+
+func (e *PreconditionFailedErr) Error() string {
+	return fmt.Sprintf("object '%s' has been modified since version '%s'", e.Object, e.Version)
+}
+
+func (e *PreconditionFailedErr) HttpCode() int {
+	return http.StatusPreconditionFailed
+}
+
+func (e *PreconditionFailedErr) Code() int {
+	return constants.CodePreconditionFailed
+}
+
+// IdempotencyKeyReusedErr 幂等键已被请求内容不同的请求使用
+type IdempotencyKeyReusedErr struct {
+	BaseErr
+	Key string
+}
+
+func (e *IdempotencyKeyReusedErr) Error() string {
+	return fmt.Sprintf("idempotency key '%s' has been used by a different request", e.Key)
+}
+
+func (e *IdempotencyKeyReusedErr) HttpCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (e *IdempotencyKeyReusedErr) Code() int {
+	return constants.CodeIdempotencyKeyReused
+}
+
+// RequestInProgressErr 使用相同幂等键的请求仍在处理中
+type RequestInProgressErr struct {
+	BaseErr
+	Key string
+}
+
+func (e *RequestInProgressErr) Error() string {
+	return fmt.Sprintf("request with idempotency key '%s' is still in progress", e.Key)
+}
+
+func (e *RequestInProgressErr) HttpCode() int {
+	return http.StatusConflict
+}
+
+func (e *RequestInProgressErr) Code() int {
+	return constants.CodeRequestInProgress
+}
+
+// CursorExpiredErr 变更游标早于保留的最早事件，无法继续增量获取
+type CursorExpiredErr struct {
+	BaseErr
+	Cursor int64
+}
+
+func (e *CursorExpiredErr) Error() string {
+	return fmt.Sprintf("cursor '%d' has expired, please resync", e.Cursor)
+}
+
+func (e *CursorExpiredErr) HttpCode() int {
+	return http.StatusGone
+}
+
+func (e *CursorExpiredErr) Code() int {
+	return constants.CodeCursorExpired
+}
+
+// MembershipCycleErr 将群组添加为成员后会形成循环嵌套
+type MembershipCycleErr struct {
+	BaseErr
+	Group  string
+	Member string
+}
+
+func (e *MembershipCycleErr) Error() string {
+	return fmt.Sprintf("adding '%s' to group '%s' would create a membership cycle", e.Member, e.Group)
+}
+
+func (e *MembershipCycleErr) HttpCode() int {
+	return http.StatusConflict
+}
+
+func (e *MembershipCycleErr) Code() int {
+	return constants.CodeMembershipCycle
+}
+
+// ManagerCycleErr 设置经理后汇报关系会形成循环
+type ManagerCycleErr struct {
+	BaseErr
+	User    string
+	Manager string
+}
+
+func (e *ManagerCycleErr) Error() string {
+	return fmt.Sprintf("setting '%s' as manager of '%s' would create a reporting cycle", e.Manager, e.User)
+}
+
+func (e *ManagerCycleErr) HttpCode() int {
+	return http.StatusConflict
+}
+
+func (e *ManagerCycleErr) Code() int {
+	return constants.CodeManagerCycle
+}
+
 // InvalidJsonErr Json请求体异常
 type InvalidJsonErr struct {
 	BaseErr
@@ -108,6 +220,67 @@ func (e *InvalidJsonErr) Error() string {
 	return "invalid json body"
 }
 
+// BadRequestErr 请求内容不合法
+type BadRequestErr struct {
+	BaseErr
+	Message string
+}
+
+func (e *BadRequestErr) HttpCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *BadRequestErr) Error() string {
+	return fmt.Sprintf("bad request: %s", e.Message)
+}
+
+// FieldError 请求体中单个字段的校验错误，Field为json中的字段路径
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErr 请求体字段校验失败，Fields为每个字段的错误详情
+type ValidationErr struct {
+	BaseErr
+	Fields []FieldError
+}
+
+func (e *ValidationErr) HttpCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *ValidationErr) Code() int {
+	return constants.CodeValidationFailed
+}
+
+func (e *ValidationErr) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, fmt.Sprintf("%s %s", field.Field, field.Message))
+	}
+	return fmt.Sprintf("validation failed: %s", strings.Join(messages, "; "))
+}
+
+// Details 返回字段错误详情，作为响应中的data返回
+func (e *ValidationErr) Details() interface{} {
+	return map[string]interface{}{"errors": e.Fields}
+}
+
+// InvalidPatchErr PATCH请求体中的变更无法应用
+type InvalidPatchErr struct {
+	BaseErr
+	Message string
+}
+
+func (e *InvalidPatchErr) HttpCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *InvalidPatchErr) Error() string {
+	return fmt.Sprintf("invalid patch: %s", e.Message)
+}
+
 // UnSupportedErr 不支持的对象或属性异常
 type UnSupportedErr struct {
 	BaseErr
@@ -130,6 +303,20 @@ func (e *InvalidFormatErr) Error() string {
 	return fmt.Sprintf("invalid %s format: '%s'", e.Name, e.Object)
 }
 
+// UnauthorizedErr 调用方身份认证失败
+type UnauthorizedErr struct {
+	BaseErr
+	Message string
+}
+
+func (e *UnauthorizedErr) HttpCode() int {
+	return http.StatusUnauthorized
+}
+
+func (e *UnauthorizedErr) Error() string {
+	return fmt.Sprintf("unauthorized: %s", e.Message)
+}
+
 // ForbiddenErr 禁止的操作异常
 type ForbiddenErr struct {
 	BaseErr
@@ -0,0 +1,160 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ldap-http-service/lib/ers"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Record 幂等键对应的请求处理结果
+type Record struct {
+	Fingerprint string            `json:"fingerprint"`
+	Status      int               `json:"status"`
+	ContentType string            `json:"content_type"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        []byte            `json:"body"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Done        bool              `json:"-"`
+}
+
+// Store 基于本地文件持久化的幂等键存储，记录数量超过上限时淘汰最早的记录
+type Store struct {
+	path       string
+	ttl        time.Duration
+	maxEntries int
+	mutex      sync.Mutex
+	records    map[string]*Record
+}
+
+// NewStore 创建幂等键存储，并从本地文件中加载未过期的记录
+func NewStore(path string, ttl time.Duration, maxEntries int) (*Store, error) {
+	s := &Store{
+		path:       path,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		records:    make(map[string]*Record),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &s.records); err != nil {
+		return nil, fmt.Errorf("failed to load idempotency store '%s': %v", path, err)
+	}
+	for key, record := range s.records {
+		if s.expired(record) {
+			delete(s.records, key)
+		} else {
+			record.Done = true
+		}
+	}
+	return s, nil
+}
+
+// Begin 开始处理携带幂等键的请求：已有完成的记录时返回该记录用于重放；
+// 同一个键正在处理中，或者请求指纹与原请求不一致时返回错误
+func (s *Store) Begin(key, fingerprint string) (*Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// 处理中的记录即使超过TTL也保留，直到请求完成或被放弃
+	if record, ok := s.records[key]; ok && (!s.expired(record) || !record.Done) {
+		if record.Fingerprint != fingerprint {
+			return nil, &ers.IdempotencyKeyReusedErr{Key: key}
+		}
+		if !record.Done {
+			return nil, &ers.RequestInProgressErr{Key: key}
+		}
+		return record, nil
+	}
+
+	s.evict()
+	s.records[key] = &Record{Fingerprint: fingerprint, CreatedAt: time.Now()}
+	return nil, nil
+}
+
+// Complete 记录请求的处理结果并持久化，headers为重放时需要一并返回的响应头，例如ETag、Location
+func (s *Store) Complete(key string, status int, contentType string, headers map[string]string, body []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return nil
+	}
+	record.Status = status
+	record.ContentType = contentType
+	record.Headers = headers
+	record.Body = body
+	record.Done = true
+	return s.save()
+}
+
+// Abort 放弃请求的处理记录，之后使用相同键的请求将被重新执行
+func (s *Store) Abort(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.records, key)
+}
+
+func (s *Store) expired(record *Record) bool {
+	return time.Since(record.CreatedAt) > s.ttl
+}
+
+// 清理过期记录，记录数仍达到上限时淘汰最早的已完成记录；处理中的记录不会被淘汰，
+// 否则并发的重复请求将无法检测到正在处理的请求而再次执行
+func (s *Store) evict() {
+	for key, record := range s.records {
+		if s.expired(record) && record.Done {
+			delete(s.records, key)
+		}
+	}
+
+	for len(s.records) >= s.maxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for key, record := range s.records {
+			if record.Done && (oldestKey == "" || record.CreatedAt.Before(oldest)) {
+				oldestKey, oldest = key, record.CreatedAt
+			}
+		}
+		if oldestKey == "" {
+			return
+		}
+		delete(s.records, oldestKey)
+	}
+}
+
+// 将已完成的记录写入本地文件，先写临时文件再重命名，避免写入中断导致文件损坏
+func (s *Store) save() error {
+	done := make(map[string]*Record)
+	for key, record := range s.records {
+		if record.Done {
+			done[key] = record
+		}
+	}
+
+	data, err := json.Marshal(done)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
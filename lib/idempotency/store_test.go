@@ -0,0 +1,149 @@
+package idempotency
+
+import (
+	"errors"
+	"ldap-http-service/lib/ers"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStoreBegin(t *testing.T) {
+	tests := []struct {
+		name        string
+		prepare     func(s *Store)
+		fingerprint string
+		wantReplay  bool
+		wantErr     error
+	}{
+		{name: "new key", fingerprint: "a"},
+		{
+			name:        "completed key is replayed",
+			prepare:     func(s *Store) { begin(t, s, "key", "a"); complete(t, s, "key") },
+			fingerprint: "a",
+			wantReplay:  true,
+		},
+		{
+			name:        "key reused for another request",
+			prepare:     func(s *Store) { begin(t, s, "key", "a"); complete(t, s, "key") },
+			fingerprint: "b",
+			wantErr:     &ers.IdempotencyKeyReusedErr{},
+		},
+		{
+			name:        "key in progress",
+			prepare:     func(s *Store) { begin(t, s, "key", "a") },
+			fingerprint: "a",
+			wantErr:     &ers.RequestInProgressErr{},
+		},
+		{
+			name:        "aborted key runs again",
+			prepare:     func(s *Store) { begin(t, s, "key", "a"); s.Abort("key") },
+			fingerprint: "a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t, 10)
+			if tt.prepare != nil {
+				tt.prepare(s)
+			}
+			record, err := s.Begin("key", tt.fingerprint)
+			if tt.wantErr != nil {
+				if reflect.TypeOf(err) != reflect.TypeOf(tt.wantErr) {
+					t.Fatalf("Begin() error = %T(%v), want %T", err, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Begin() error = %v", err)
+			}
+			if (record != nil) != tt.wantReplay {
+				t.Fatalf("Begin() record = %+v, want replay %v", record, tt.wantReplay)
+			}
+			if record != nil && record.Headers["ETag"] != `"42"` {
+				t.Errorf("replayed headers = %v", record.Headers)
+			}
+		})
+	}
+}
+
+func TestStoreEvict(t *testing.T) {
+	s := newStore(t, 3)
+	begin(t, s, "running", "a")
+	begin(t, s, "done-1", "a")
+	complete(t, s, "done-1")
+	begin(t, s, "done-2", "a")
+	complete(t, s, "done-2")
+
+	// 达到上限时淘汰最早的已完成记录，处理中的记录保留
+	begin(t, s, "new", "a")
+	if _, err := s.Begin("running", "a"); !errors.As(err, new(*ers.RequestInProgressErr)) {
+		t.Errorf("in-progress record was evicted, Begin() error = %v", err)
+	}
+	if _, ok := s.records["done-1"]; ok {
+		t.Errorf("oldest completed record was not evicted")
+	}
+	if _, ok := s.records["done-2"]; !ok {
+		t.Errorf("newer completed record was evicted")
+	}
+
+	// 只剩处理中的记录时允许暂时超过上限
+	s = newStore(t, 2)
+	begin(t, s, "a", "a")
+	begin(t, s, "b", "a")
+	begin(t, s, "c", "a")
+	if len(s.records) != 3 {
+		t.Errorf("records = %d, want 3", len(s.records))
+	}
+}
+
+func TestStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	s, err := NewStore(path, time.Hour, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	begin(t, s, "key", "a")
+	complete(t, s, "key")
+	begin(t, s, "running", "a")
+
+	reloaded, err := NewStore(path, time.Hour, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := reloaded.Begin("key", "a")
+	if err != nil || record == nil {
+		t.Fatalf("Begin() = %+v, %v, want the persisted record", record, err)
+	}
+	if record.Status != 201 || string(record.Body) != "{}" || record.Headers["Location"] != "/ldap/user/alice" {
+		t.Errorf("reloaded record = %+v", record)
+	}
+	if _, ok := reloaded.records["running"]; ok {
+		t.Errorf("in-progress record was persisted")
+	}
+}
+
+func newStore(t *testing.T, maxEntries int) *Store {
+	s, err := NewStore(filepath.Join(t.TempDir(), "idempotency.json"), time.Hour, maxEntries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func begin(t *testing.T, s *Store, key, fingerprint string) {
+	if _, err := s.Begin(key, fingerprint); err != nil {
+		t.Fatalf("Begin(%q): %v", key, err)
+	}
+	// 保证记录的创建时间有先后
+	s.records[key].CreatedAt = time.Now().Add(time.Duration(len(s.records)) * time.Millisecond)
+}
+
+func complete(t *testing.T, s *Store, key string) {
+	headers := map[string]string{"ETag": `"42"`, "Location": "/ldap/user/alice"}
+	if err := s.Complete(key, 201, "application/json", headers, []byte("{}")); err != nil {
+		t.Fatalf("Complete(%q): %v", key, err)
+	}
+}
@@ -104,6 +104,9 @@ func (hook LogTrace) Fire(entry *logrus.Entry) error {
 var (
 	GinLogger  *logrus.Entry
 	LdapLogger *logrus.Entry
+	GrpcLogger *logrus.Entry
+	// AuditLogger 记录由服务自动执行的目录变更，例如限时群组成员到期移除
+	AuditLogger *logrus.Entry
 )
 
 func init() {
@@ -118,4 +121,6 @@ func init() {
 
 	GinLogger = baseLogger.WithField("logger", "gin")
 	LdapLogger = baseLogger.WithField("logger", "ldap")
+	GrpcLogger = baseLogger.WithField("logger", "grpc")
+	AuditLogger = baseLogger.WithField("logger", "audit")
 }
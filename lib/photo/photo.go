@@ -0,0 +1,105 @@
+package photo
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"ldap-http-service/lib/ers"
+	"net/http"
+)
+
+// 允许解码的最大像素数（约1200万像素，足以容纳常见手机照片），解码前按图片头声明的尺寸校验，避免解码超大图片耗尽内存
+const maxPixels = 12 << 20
+
+// 重新编码时依次尝试的JPEG质量
+var jpegQualities = []int{90, 80, 70, 60, 50, 40}
+
+// 原样保存时支持的格式，其他格式（例如gif）统一转换为JPEG
+var keepFormats = map[string]bool{"jpeg": true, "png": true}
+
+// Fit 校验图片并使其符合大小限制：图片不超过maxBytes且宽高不超过maxDimension时原样返回，
+// 否则按比例缩小至maxDimension以内并编码为JPEG，依次降低质量及尺寸直到不超过maxBytes
+func Fit(data []byte, maxBytes, maxDimension int) ([]byte, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, &ers.UnSupportedErr{Object: http.DetectContentType(data), ObjectType: "image format"}
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, &ers.BadRequestErr{Message: "image dimensions are out of range"}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &ers.InvalidFormatErr{Name: format, Object: "image"}
+	}
+	if len(data) <= maxBytes && keepFormats[format] && cfg.Width <= maxDimension && cfg.Height <= maxDimension {
+		return data, nil
+	}
+
+	for dimension := maxDimension; dimension >= 16; dimension /= 2 {
+		scaled := scale(img, dimension)
+		for _, quality := range jpegQualities {
+			var buf bytes.Buffer
+			if err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, &ers.SystemErr{Message: err.Error()}
+			}
+			if buf.Len() <= maxBytes {
+				return buf.Bytes(), nil
+			}
+		}
+	}
+	return nil, &ers.BadRequestErr{Message: "image cannot be compressed to the size limit"}
+}
+
+// 按比例缩小图片使宽高不超过dimension，透明区域以白色填充；缩小时每个像素取其覆盖的原图区域的平均值
+func scale(img image.Image, dimension int) *image.RGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > dimension || height > dimension {
+		if width >= height {
+			width, height = dimension, height*dimension/bounds.Dx()
+		} else {
+			width, height = width*dimension/bounds.Dy(), dimension
+		}
+		if width == 0 {
+			width = 1
+		}
+		if height == 0 {
+			height = 1
+		}
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Over)
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return src
+	}
+
+	// 只会缩小图片，目标像素覆盖的原图区域至少包含一个像素
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*bounds.Dy()/height, (y+1)*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			x0, x1 := x*bounds.Dx()/width, (x+1)*bounds.Dx()/width
+
+			var r, g, b, n int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := src.PixOffset(sx, sy)
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = uint8(r/n), uint8(g/n), uint8(b/n), 0xff
+		}
+	}
+	return dst
+}
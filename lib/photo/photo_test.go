@@ -0,0 +1,117 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"ldap-http-service/lib/ers"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newImage(width, height int, noise bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	r := rand.New(rand.NewSource(1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 0xff}
+			if noise {
+				c.R, c.G, c.B = uint8(r.Intn(256)), uint8(r.Intn(256)), uint8(r.Intn(256))
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// 仅包含图片头的PNG，声明的尺寸超过maxPixels，用于校验解码前的尺寸检查
+func oversizedPNGHeader(t *testing.T) []byte {
+	data := encodePNG(t, image.NewGray(image.Rect(0, 0, 1, 1)))
+	// IHDR块类型从第12字节开始，其后依次为宽、高（大端序），修改后需要重新计算块的CRC
+	binary.BigEndian.PutUint32(data[16:20], 1<<14)
+	binary.BigEndian.PutUint32(data[20:24], 1<<14)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestFit(t *testing.T) {
+	small := encodePNG(t, newImage(64, 48, false))
+
+	var gifBuf bytes.Buffer
+	if err := gif.Encode(&gifBuf, newImage(32, 32, false), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		data         []byte
+		maxBytes     int
+		maxDimension int
+		wantErr      error
+		wantSame     bool
+		wantFormat   string
+		wantMaxSide  int
+	}{
+		{name: "small png is kept", data: small, maxBytes: 102400, maxDimension: 256, wantSame: true, wantFormat: "png", wantMaxSide: 64},
+		{name: "large image is scaled down", data: encodePNG(t, newImage(600, 300, false)), maxBytes: 102400, maxDimension: 256, wantFormat: "jpeg", wantMaxSide: 256},
+		{name: "too many bytes is recompressed", data: encodePNG(t, newImage(200, 200, true)), maxBytes: 20000, maxDimension: 256, wantFormat: "jpeg", wantMaxSide: 200},
+		{name: "gif is converted", data: gifBuf.Bytes(), maxBytes: 102400, maxDimension: 256, wantFormat: "jpeg", wantMaxSide: 32},
+		{name: "not an image", data: []byte("hello, world"), maxBytes: 102400, maxDimension: 256, wantErr: &ers.UnSupportedErr{}},
+		{name: "too many pixels", data: oversizedPNGHeader(t), maxBytes: 102400, maxDimension: 256, wantErr: &ers.BadRequestErr{}},
+		{name: "size limit cannot be met", data: small, maxBytes: 10, maxDimension: 256, wantErr: &ers.BadRequestErr{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Fit(tt.data, tt.maxBytes, tt.maxDimension)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("Fit() error = nil, want %T", tt.wantErr)
+				}
+				if reflect.TypeOf(err) != reflect.TypeOf(tt.wantErr) {
+					t.Fatalf("Fit() error = %T(%v), want %T", err, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+			if tt.wantSame && !bytes.Equal(got, tt.data) {
+				t.Errorf("Fit() changed an image that is within the limits")
+			}
+			if len(got) > tt.maxBytes {
+				t.Errorf("Fit() returned %d bytes, limit %d", len(got), tt.maxBytes)
+			}
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(got))
+			if err != nil {
+				t.Fatalf("decode result: %v", err)
+			}
+			if format != tt.wantFormat {
+				t.Errorf("Fit() format = %s, want %s", format, tt.wantFormat)
+			}
+			if side := max(cfg.Width, cfg.Height); side != tt.wantMaxSide {
+				t.Errorf("Fit() longest side = %d, want %d", side, tt.wantMaxSide)
+			}
+		})
+	}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}